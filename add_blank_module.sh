@@ -1,6 +1,14 @@
 #!/usr/bin/env bash
+set -e
 
 moduleName=$1
+# company_person -> CompanyPerson, the entity; companyPerson, the identifiers; COMPANY_PERSON, the table.
+# awk and tr behave the same with GNU and BSD (macOS) tools
+typeName=$(echo "$moduleName" | awk -F_ '{ for (i = 1; i <= NF; i++) printf "%s%s", toupper(substr($i, 1, 1)), substr($i, 2) }')
+varName=$(echo "${typeName:0:1}" | tr '[:upper:]' '[:lower:]')${typeName:1}
+tableName=$(echo "$moduleName" | tr '[:lower:]' '[:upper:]')
+modulePath=github.com/internet-banking-ul/internal/modules/$moduleName
+
 echo "Create directory for module \"$moduleName\""
 mkdir ./internal/modules/$moduleName
 
@@ -10,4 +18,167 @@ for i in dto entities repositories services; do
 
     echo "Create empty file for module $moduleName/$i"
     echo "package $i" > internal/modules/$moduleName/$i/$moduleName.go
-done
\ No newline at end of file
+done
+
+echo "Create entity $typeName and its repository on table $tableName"
+cat > internal/modules/$moduleName/entities/$moduleName.go <<EOF
+package entities
+
+type $typeName struct {
+	ID int64 \`db:"ID" json:"id"\`
+}
+
+type ${typeName}List []*$typeName
+EOF
+
+cat > internal/modules/$moduleName/repositories/$moduleName.go <<EOF
+package repositories
+
+import (
+	"context"
+
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/modules/repository"
+	${varName}Model "$modulePath/entities"
+	"github.com/internet-banking-ul/internal/storage"
+)
+
+type Repository${typeName}Query interface {
+	List(context.Context, entities.BasePaginationFilters) (${varName}Model.${typeName}List, int64, string, error)
+	ByID(ctx context.Context, id int64) (result ${varName}Model.$typeName, err error)
+}
+
+// ${varName}ListColumns - the sort, search and filter whitelist of List
+var ${varName}ListColumns = entities.ListColumns{
+	Sortable: map[string]string{
+		"id": "ID",
+	},
+	DefaultSort: "id",
+	Key:         "ID",
+}
+
+// Repository${typeName}QueryImpl - List, Count, GetByID, GetBy and FindBy come from repository.Repository
+type Repository${typeName}QueryImpl struct {
+	*repository.Repository[${varName}Model.$typeName]
+}
+
+func new${typeName}Query(db *storage.DB) *Repository${typeName}QueryImpl {
+	return &Repository${typeName}QueryImpl{
+		Repository: repository.New[${varName}Model.$typeName](db, "$tableName", ${varName}ListColumns, nil),
+	}
+}
+
+// List returns the page selected by baseFilter, the total of the filtered rows
+// and the cursor of the next page ("" on the last page)
+func (repo *Repository${typeName}QueryImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (${varName}Model.${typeName}List, int64, string, error) {
+	return repo.Repository.List(ctx, baseFilter)
+}
+
+// ByID returns sql.ErrNoRows when the row doesn't exist
+func (repo *Repository${typeName}QueryImpl) ByID(ctx context.Context, id int64) (result ${varName}Model.$typeName, err error) {
+	return repo.GetByID(ctx, id)
+}
+EOF
+
+cat > internal/modules/$moduleName/repositories/${moduleName}_command.go <<EOF
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	${varName}Model "$modulePath/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+const ${varName}Sequence = "${tableName}_SEQ"
+
+// Repository${typeName}Command - writes, every method runs in the transaction of ctx started by the service (storage.DB.Runner)
+type Repository${typeName}Command interface {
+	Create(ctx context.Context, $varName *${varName}Model.$typeName) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type Repository${typeName}CommandImpl struct {
+	DB *storage.DB
+}
+
+// Create takes the next ID from ${tableName}_SEQ, inserts the row and sets $varName.ID
+func (repo *Repository${typeName}CommandImpl) Create(ctx context.Context, $varName *${varName}Model.$typeName) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Create")
+
+	id, err := repo.DB.NextID(ctx, ${varName}Sequence, "$tableName")
+	if err != nil {
+		l.Error("NextID", zap.Error(err))
+		return err
+	}
+
+	q := repo.DB.Builder().
+		Insert("$tableName").
+		Columns("ID").
+		Values(id)
+
+	if err = storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return err
+	}
+
+	$varName.ID = id
+	return nil
+}
+
+// Delete returns sql.ErrNoRows when there is nothing to delete
+func (repo *Repository${typeName}CommandImpl) Delete(ctx context.Context, id int64) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Delete")
+
+	q := repo.DB.Builder().
+		Delete("$tableName").
+		Where(sq.Eq{"ID": id})
+
+	return storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx)))
+}
+EOF
+
+cat > internal/modules/$moduleName/repositories/repositories.go <<EOF
+package repositories
+
+import (
+	"github.com/internet-banking-ul/internal/storage"
+)
+
+type Repositories interface {
+	Repository${typeName}Query
+	Repository${typeName}Command
+}
+
+type RepositoriesImpl struct {
+	// inject db impl to RepositoriesImpl event the db is being used by the child struct impl
+	DB *storage.DB
+	*Repository${typeName}QueryImpl
+	*Repository${typeName}CommandImpl
+}
+
+func New${typeName}Repository(
+	db *storage.DB,
+) *RepositoriesImpl {
+	return &RepositoriesImpl{
+		DB:                          db,
+		Repository${typeName}QueryImpl: new${typeName}Query(db),
+		Repository${typeName}CommandImpl: &Repository${typeName}CommandImpl{
+			DB: db,
+		},
+	}
+}
+EOF
+
+gofmt -w internal/modules/$moduleName
@@ -0,0 +1,7 @@
+//go:build oracle
+// +build oracle
+
+package main
+
+// the Oracle driver needs Instant Client (see deps.sh), so it is only linked with `-tags oracle`
+import _ "github.com/mattn/go-oci8"
@@ -0,0 +1,40 @@
+package apiErrors
+
+const (
+	AccountNotFound    = "ACCOUNT_NOT_FOUND"
+	AccountIdInvalid   = "ACCOUNT_ID_INVALID"
+	AccountIbanInvalid = "ACCOUNT_IBAN_INVALID"
+
+	AccountStatementFormatInvalid = "ACCOUNT_STATEMENT_FORMAT_INVALID"
+	AccountStatementPeriodInvalid = "ACCOUNT_STATEMENT_PERIOD_INVALID"
+)
+
+var (
+	accountErrors = []apiError{
+		{
+			Id:      AccountNotFound,
+			Message: "Account not found",
+			Status:  404,
+		},
+		{
+			Id:      AccountIdInvalid,
+			Message: "Account id must be a positive integer",
+			Status:  400,
+		},
+		{
+			Id:      AccountIbanInvalid,
+			Message: "IBAN must be a valid Kazakhstan IBAN (KZ and 18 characters)",
+			Status:  400,
+		},
+		{
+			Id:      AccountStatementFormatInvalid,
+			Message: "Statement format must be one of csv, xlsx, pdf",
+			Status:  400,
+		},
+		{
+			Id:      AccountStatementPeriodInvalid,
+			Message: "Statement period must be dateFrom and dateTo as YYYY-MM-DD, dateFrom not after dateTo",
+			Status:  400,
+		},
+	}
+)
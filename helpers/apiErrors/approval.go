@@ -0,0 +1,63 @@
+package apiErrors
+
+const (
+	ApprovalNotFound           = "APPROVAL_NOT_FOUND"
+	ApprovalIdInvalid          = "APPROVAL_ID_INVALID"
+	ApprovalAlreadyExists      = "APPROVAL_ALREADY_EXISTS"
+	ApprovalNotPending         = "APPROVAL_NOT_PENDING"
+	ApprovalExpired            = "APPROVAL_EXPIRED"
+	ApprovalAlreadySigned      = "APPROVAL_ALREADY_SIGNED"
+	ApprovalSignLevelNotNeeded = "APPROVAL_SIGN_LEVEL_NOT_NEEDED"
+	ApprovalConflict           = "APPROVAL_CONFLICT"
+	ApprovalOperationOwned     = "APPROVAL_OPERATION_OWNED"
+)
+
+var (
+	approvalErrors = []apiError{
+		{
+			Id:      ApprovalNotFound,
+			Message: "Approval not found",
+			Status:  404,
+		},
+		{
+			Id:      ApprovalIdInvalid,
+			Message: "Approval id must be a positive integer",
+			Status:  400,
+		},
+		{
+			Id:      ApprovalAlreadyExists,
+			Message: "The operation is already waiting for approval",
+			Status:  409,
+		},
+		{
+			Id:      ApprovalNotPending,
+			Message: "Approval is already resolved",
+			Status:  409,
+		},
+		{
+			Id:      ApprovalExpired,
+			Message: "Approval has expired",
+			Status:  409,
+		},
+		{
+			Id:      ApprovalAlreadySigned,
+			Message: "You have already signed the approval",
+			Status:  409,
+		},
+		{
+			Id:      ApprovalSignLevelNotNeeded,
+			Message: "The approval needs no more signatures of your sign level",
+			Status:  409,
+		},
+		{
+			Id:      ApprovalConflict,
+			Message: "Approval was changed by another request, try again",
+			Status:  409,
+		},
+		{
+			Id:      ApprovalOperationOwned,
+			Message: "The operation is put on approval and signed through its own route",
+			Status:  409,
+		},
+	}
+)
@@ -0,0 +1,27 @@
+package apiErrors
+
+const (
+	CompanyPersonNotFound  = "COMPANY_PERSON_NOT_FOUND"
+	CompanyPersonIdInvalid = "COMPANY_PERSON_ID_INVALID"
+	CompanyPersonManages   = "COMPANY_PERSON_MANAGES"
+)
+
+var (
+	companyPersonErrors = []apiError{
+		{
+			Id:      CompanyPersonNotFound,
+			Message: "Company person not found",
+			Status:  404,
+		},
+		{
+			Id:      CompanyPersonIdInvalid,
+			Message: "Company person id must be a positive integer",
+			Status:  400,
+		},
+		{
+			Id:      CompanyPersonManages,
+			Message: "Company person is the manager of other persons, change their manager first",
+			Status:  409,
+		},
+	}
+)
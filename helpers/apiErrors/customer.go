@@ -0,0 +1,27 @@
+package apiErrors
+
+const (
+	CustomerNotFound      = "CUSTOMER_NOT_FOUND"
+	CustomerIdInvalid     = "CUSTOMER_ID_INVALID"
+	CustomerAlreadyExists = "CUSTOMER_ALREADY_EXISTS"
+)
+
+var (
+	customerErrors = []apiError{
+		{
+			Id:      CustomerNotFound,
+			Message: "Customer not found",
+			Status:  404,
+		},
+		{
+			Id:      CustomerIdInvalid,
+			Message: "Customer id must be a positive integer",
+			Status:  400,
+		},
+		{
+			Id:      CustomerAlreadyExists,
+			Message: "Customer with this externalID already exists",
+			Status:  409,
+		},
+	}
+)
@@ -0,0 +1,52 @@
+package apiErrors
+
+const (
+	SortFieldUnknown = "SORT_FIELD_UNKNOWN"
+	SortOrderInvalid = "SORT_ORDER_INVALID"
+	CursorInvalid    = "CURSOR_INVALID"
+
+	FilterInvalid         = "FILTER_INVALID"
+	FilterFieldUnknown    = "FILTER_FIELD_UNKNOWN"
+	FilterOperatorInvalid = "FILTER_OPERATOR_INVALID"
+	FilterValueInvalid    = "FILTER_VALUE_INVALID"
+)
+
+var (
+	filterErrors = []apiError{
+		{
+			Id:      SortFieldUnknown,
+			Message: "Unknown sort field",
+			Status:  400,
+		},
+		{
+			Id:      SortOrderInvalid,
+			Message: "Order must be asc or desc",
+			Status:  400,
+		},
+		{
+			Id:      CursorInvalid,
+			Message: "Cursor is invalid",
+			Status:  400,
+		},
+		{
+			Id:      FilterInvalid,
+			Message: "Filter must be filter[field][op]=value",
+			Status:  400,
+		},
+		{
+			Id:      FilterFieldUnknown,
+			Message: "Unknown filter field",
+			Status:  400,
+		},
+		{
+			Id:      FilterOperatorInvalid,
+			Message: "Filter operator is not supported by the field",
+			Status:  400,
+		},
+		{
+			Id:      FilterValueInvalid,
+			Message: "Filter value is invalid",
+			Status:  400,
+		},
+	}
+)
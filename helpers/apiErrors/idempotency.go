@@ -0,0 +1,27 @@
+package apiErrors
+
+const (
+	IdempotencyKeyInvalid    = "IDEMPOTENCY_KEY_INVALID"
+	IdempotencyKeyReused     = "IDEMPOTENCY_KEY_REUSED"
+	IdempotencyKeyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+)
+
+var (
+	idempotencyErrors = []apiError{
+		{
+			Id:      IdempotencyKeyInvalid,
+			Message: "Idempotency-Key must be at most 255 printable characters",
+			Status:  400,
+		},
+		{
+			Id:      IdempotencyKeyReused,
+			Message: "Idempotency-Key was already used with another request",
+			Status:  422,
+		},
+		{
+			Id:      IdempotencyKeyInProgress,
+			Message: "The request with the Idempotency-Key is still in progress, retry later",
+			Status:  409,
+		},
+	}
+)
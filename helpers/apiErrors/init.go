@@ -5,6 +5,8 @@ type apiError struct {
 	Message string `json:"message"`
 	Status  int    `json:"status"`
 	Detail  string `json:"detail,omitempty"`
+
+	Fields FieldErrors `json:"fields,omitempty"`
 }
 
 func (e *apiError) Error() string {
@@ -34,6 +36,14 @@ func init() {
 	ApiErrors = append(commonErrors, userErrors...)
 	ApiErrors = append(ApiErrors, apiErrorErrors...)
 	ApiErrors = append(ApiErrors, userProfileErrors...)
+	ApiErrors = append(ApiErrors, customerErrors...)
+	ApiErrors = append(ApiErrors, companyPersonErrors...)
+	ApiErrors = append(ApiErrors, approvalErrors...)
+	ApiErrors = append(ApiErrors, accountErrors...)
+	ApiErrors = append(ApiErrors, paymentErrors...)
+	ApiErrors = append(ApiErrors, idempotencyErrors...)
+	ApiErrors = append(ApiErrors, validationErrors...)
+	ApiErrors = append(ApiErrors, filterErrors...)
 }
 
 func cloneError(e *apiError) *apiError {
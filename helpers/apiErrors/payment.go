@@ -0,0 +1,51 @@
+package apiErrors
+
+const (
+	PaymentNotFound          = "PAYMENT_NOT_FOUND"
+	PaymentIdInvalid         = "PAYMENT_ID_INVALID"
+	PaymentStatusInvalid     = "PAYMENT_STATUS_INVALID"
+	PaymentInsufficientFunds = "PAYMENT_INSUFFICIENT_FUNDS"
+	PaymentAccountInactive   = "PAYMENT_ACCOUNT_INACTIVE"
+	PaymentConflict          = "PAYMENT_CONFLICT"
+	PaymentOnApproval        = "PAYMENT_ON_APPROVAL"
+)
+
+var (
+	paymentErrors = []apiError{
+		{
+			Id:      PaymentNotFound,
+			Message: "Payment not found",
+			Status:  404,
+		},
+		{
+			Id:      PaymentIdInvalid,
+			Message: "Payment id must be a positive integer",
+			Status:  400,
+		},
+		{
+			Id:      PaymentStatusInvalid,
+			Message: "The payment status doesn't allow the operation",
+			Status:  409,
+		},
+		{
+			Id:      PaymentInsufficientFunds,
+			Message: "The available balance of the account is less than the payment amount",
+			Status:  409,
+		},
+		{
+			Id:      PaymentAccountInactive,
+			Message: "The account of the payment is not active",
+			Status:  409,
+		},
+		{
+			Id:      PaymentConflict,
+			Message: "The payment was changed by another request, retry",
+			Status:  409,
+		},
+		{
+			Id:      PaymentOnApproval,
+			Message: "The payment is being signed, it can't be changed until its approval is resolved",
+			Status:  409,
+		},
+	}
+)
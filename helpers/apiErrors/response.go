@@ -8,6 +8,12 @@ func Send(c *fiber.Ctx, errorID string) error {
 }
 
 func SendErr(c *fiber.Ctx, err *apiError) error {
+	if len(err.Fields) > 0 {
+		return c.Status(err.Status).JSON(fiber.Map{
+			"error":  err.Message,
+			"fields": err.Fields,
+		})
+	}
 	return c.Status(err.Status).JSON(fiber.Map{
 		"error": err.Message,
 	})
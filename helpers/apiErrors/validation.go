@@ -0,0 +1,57 @@
+package apiErrors
+
+const (
+	ValidationFailed = "VALIDATION_FAILED"
+)
+
+// field error codes
+const (
+	FieldRequired        = "REQUIRED"
+	FieldInvalidEnum     = "INVALID_ENUM"
+	FieldInvalidFormat   = "INVALID_FORMAT"
+	FieldInvalidChecksum = "INVALID_CHECKSUM"
+	FieldTooLong         = "TOO_LONG"
+	FieldNotFound        = "NOT_FOUND"
+	FieldInvalidRange    = "INVALID_RANGE"
+	FieldOverlaps        = "OVERLAPS"
+	FieldForeignCompany  = "FOREIGN_COMPANY"
+	FieldInactive        = "INACTIVE"
+)
+
+var (
+	validationErrors = []apiError{
+		{
+			Id:      ValidationFailed,
+			Message: "Validation failed",
+			Status:  422,
+		},
+	}
+)
+
+// FieldError describes the problem with a single field of the request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// FieldErrors collects field problems of one request
+type FieldErrors []FieldError
+
+func (f *FieldErrors) Add(field, code, message string) {
+	*f = append(*f, FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+	})
+}
+
+// Err returns nil when there are no problems, otherwise ValidationFailed with the fields attached
+func (f FieldErrors) Err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	err := ThrowError(ValidationFailed)
+	err.Fields = f
+	return err
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces secrets in Redacted
+const RedactedValue = "******"
+
+// Redacted returns a copy of the config with every non-empty `secret:"true"` field replaced by RedactedValue
+func (cfg *Config) Redacted() *Config {
+	c := *cfg
+	redact(reflect.ValueOf(&c).Elem())
+	return &c
+}
+
+func redact(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch {
+		case f.Kind() == reflect.Struct:
+			redact(f)
+		case t.Field(i).Tag.Get("secret") == "true" && f.Kind() == reflect.String && f.String() != "":
+			f.SetString(RedactedValue)
+		}
+	}
+}
+
+// Print writes the redacted config to w in YAML format
+func (cfg *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/internet-banking-ul/internal/consts"
+	"github.com/internet-banking-ul/tools"
+)
+
+var (
+	environments = []string{
+		consts.EnvironmentDevelopment,
+		consts.EnvironmentStaging,
+		consts.EnvironmentProduction,
+	}
+	logLevels = []string{"debug", "info", "warn", "error", "dpanic", "panic", "fatal"}
+	dialects  = []string{
+		consts.DialectOracle,
+		consts.DialectPostgres,
+		consts.DialectSQLite,
+	}
+	isolationLevels   = []string{"read_committed", "repeatable_read", "serializable", "read_uncommitted"}
+	idempotencyStores = []string{
+		consts.IdempotencyStoreSQL,
+		consts.IdempotencyStoreMemory,
+	}
+	tracingExporters = []string{
+		"",
+		consts.TracingExporterStdout,
+		consts.TracingExporterOTLPFile,
+	}
+
+	// oracleIsolationLevels - Oracle has no repeatable read and read uncommitted transactions
+	oracleIsolationLevels = []string{"read_committed", "serializable"}
+)
+
+// ValidationError holds all problems found in the config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+func (e *ValidationError) add(format string, args ...interface{}) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// Validate checks the config and returns *ValidationError listing every problem
+func (cfg *Config) Validate() error {
+	e := &ValidationError{}
+
+	if !tools.StringInSlice(environments, cfg.Environment) {
+		e.add("environment must be one of %s, got %q", strings.Join(environments, ", "), cfg.Environment)
+	}
+
+	validatePort(e, "server_port", cfg.ServerPort)
+
+	if !tools.StringInSlice(logLevels, strings.ToLower(cfg.LogLevel)) {
+		e.add("log_level must be one of %s, got %q", strings.Join(logLevels, ", "), cfg.LogLevel)
+	}
+	if cfg.LogFile != "" {
+		validateParentDir(e, "log_file", cfg.LogFile)
+	}
+
+	validateDir(e, "data_dir", cfg.DataDir)
+	validateDir(e, "temp_dir", cfg.TempDir)
+	if cfg.PidFilePath != "" {
+		validateParentDir(e, "pid_file_path", cfg.PidFilePath)
+	}
+
+	if cfg.Environment == consts.EnvironmentProduction && cfg.CursorSecret == "" {
+		e.add("cursor_secret is required in production")
+	}
+	if cfg.Environment == consts.EnvironmentProduction && cfg.AuditKey == "" {
+		e.add("audit_key is required in production")
+	}
+
+	cfg.DB.validate(e)
+	cfg.Auth.validate(e)
+	cfg.Approvals.validate(e)
+	cfg.Idempotency.validate(e)
+	cfg.Shutdown.validate(e)
+	cfg.Handoff.validate(e)
+	cfg.Health.validate(e)
+	cfg.Metrics.validate(e)
+	cfg.Tracing.validate(e)
+
+	if len(e.Problems) > 0 {
+		return e
+	}
+	return nil
+}
+
+func (db *Database) validate(e *ValidationError) {
+	switch db.Dialect {
+	case consts.DialectSQLite:
+		if strings.TrimSpace(db.Path) == "" {
+			e.add("db.path is required for the sqlite dialect")
+		} else if db.Path != ":memory:" {
+			validateParentDir(e, "db.path", db.Path)
+		}
+		db.validatePool(e)
+		return
+	case consts.DialectOracle, consts.DialectPostgres:
+	default:
+		e.add("db.dialect must be one of %s, got %q", strings.Join(dialects, ", "), db.Dialect)
+		return
+	}
+
+	if strings.TrimSpace(db.Host) == "" {
+		e.add("db.host is required")
+	}
+	validatePort(e, "db.port", db.Port)
+	if strings.TrimSpace(db.ServiceName) == "" {
+		e.add("db.service_name is required")
+	}
+	if strings.TrimSpace(db.User) == "" {
+		e.add("db.user is required")
+	}
+	if strings.ContainsAny(db.User, "/@") {
+		e.add("db.user must not contain '/' or '@'")
+	}
+	db.validatePool(e)
+}
+
+func (db *Database) validatePool(e *ValidationError) {
+	if db.MaxOpenConns < 0 {
+		e.add("db.max_open_conns must not be negative")
+	}
+	if db.MaxIdleConns < 0 {
+		e.add("db.max_idle_conns must not be negative")
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		e.add("db.max_idle_conns (%d) must not exceed db.max_open_conns (%d)", db.MaxIdleConns, db.MaxOpenConns)
+	}
+	if db.IsolationLevel != "" && !tools.StringInSlice(isolationLevels, db.IsolationLevel) {
+		e.add("db.isolation_level must be one of %s, got %q", strings.Join(isolationLevels, ", "), db.IsolationLevel)
+	} else if db.IsolationLevel != "" && db.Dialect == consts.DialectOracle && !tools.StringInSlice(oracleIsolationLevels, db.IsolationLevel) {
+		e.add("db.isolation_level of oracle must be one of %s, got %q", strings.Join(oracleIsolationLevels, ", "), db.IsolationLevel)
+	}
+	if db.ConnMaxLifetime < 0 {
+		e.add("db.conn_max_lifetime must not be negative")
+	}
+}
+
+func (auth *Auth) validate(e *ValidationError) {
+	if auth.HMACSecret == "" && auth.PublicKeyFile == "" {
+		e.add("auth.hmac_secret or auth.public_key_file is required")
+	}
+	if auth.PublicKeyFile != "" {
+		if ok, _ := tools.PathExists(auth.PublicKeyFile); !ok {
+			e.add("auth.public_key_file %q does not exist", auth.PublicKeyFile)
+		}
+	}
+	if auth.ClockSkew < 0 {
+		e.add("auth.clock_skew must not be negative")
+	}
+}
+
+// validate checks the durations and that every rule is set, the rule syntax is checked when the approvals service starts
+func (approvals *Approvals) validate(e *ValidationError) {
+	if strings.TrimSpace(approvals.DefaultRule) == "" {
+		e.add("approvals.default_rule is required")
+	}
+	for operationType, rule := range approvals.Rules {
+		if strings.TrimSpace(rule) == "" {
+			e.add("approvals.rules.%s must not be empty", operationType)
+		}
+	}
+	if approvals.TTL <= 0 {
+		e.add("approvals.ttl must be positive")
+	}
+	if approvals.ExpireInterval <= 0 {
+		e.add("approvals.expire_interval must be positive")
+	}
+}
+
+func (idempotency *Idempotency) validate(e *ValidationError) {
+	if !tools.StringInSlice(idempotencyStores, idempotency.Store) {
+		e.add("idempotency.store must be one of %s, got %q", strings.Join(idempotencyStores, ", "), idempotency.Store)
+	}
+	if idempotency.Store == consts.IdempotencyStoreMemory && idempotency.Capacity <= 0 {
+		e.add("idempotency.capacity must be positive for the memory store")
+	}
+	if idempotency.TTL <= 0 {
+		e.add("idempotency.ttl must be positive")
+	}
+	if idempotency.Wait < 0 {
+		e.add("idempotency.wait must not be negative")
+	}
+	if idempotency.Lease <= 0 {
+		e.add("idempotency.lease must be positive")
+	}
+	if idempotency.ExpireInterval <= 0 {
+		e.add("idempotency.expire_interval must be positive")
+	}
+}
+
+func validatePort(e *ValidationError, name string, port int) {
+	if port < 1 || port > 65535 {
+		e.add("%s must be in range 1..65535, got %d", name, port)
+	}
+}
+
+// validateDir checks that dir is set, is not a regular file and can be created by tools.MakeDirectory
+func validateDir(e *ValidationError, name, dir string) {
+	if strings.TrimSpace(dir) == "" {
+		e.add("%s is required", name)
+		return
+	}
+
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			e.add("%s %q is not a directory", name, dir)
+		}
+		return
+	}
+	if !os.IsNotExist(err) {
+		e.add("%s %q: %s", name, dir, err)
+		return
+	}
+
+	validateParentDir(e, name, dir)
+}
+
+func validateParentDir(e *ValidationError, name, path string) {
+	parent := filepath.Dir(path)
+	info, err := os.Stat(parent)
+	if err != nil || !info.IsDir() {
+		e.add("%s: parent directory %q does not exist", name, parent)
+	}
+}
+
+func (shutdown *Shutdown) validate(e *ValidationError) {
+	if shutdown.Delay < 0 {
+		e.add("shutdown.delay must not be negative")
+	}
+	if shutdown.DrainTimeout <= 0 {
+		e.add("shutdown.drain_timeout must be positive")
+	}
+	if shutdown.CloseTimeout <= 0 {
+		e.add("shutdown.close_timeout must be positive")
+	}
+}
+
+func (handoff *Handoff) validate(e *ValidationError) {
+	if !handoff.Enabled {
+		return
+	}
+	if runtime.GOOS != "linux" {
+		e.add("handoff is supported on linux only")
+	}
+	if handoff.ReadyTimeout <= 0 {
+		e.add("handoff.ready_timeout must be positive")
+	}
+	if handoff.LockTimeout <= 0 {
+		e.add("handoff.lock_timeout must be positive")
+	}
+}
+
+func (health *Health) validate(e *ValidationError) {
+	if health.CheckTimeout <= 0 {
+		e.add("health.check_timeout must be positive")
+	}
+	if health.MaxPoolSaturation < 0 || health.MaxPoolSaturation > 1 {
+		e.add("health.max_pool_saturation must be between 0 and 1, got %v", health.MaxPoolSaturation)
+	}
+}
+
+func (metrics *Metrics) validate(e *ValidationError) {
+	if metrics.Enabled && metrics.MaxSeries <= 0 {
+		e.add("metrics.max_series must be positive")
+	}
+}
+
+func (tracing *Tracing) validate(e *ValidationError) {
+	if !tools.StringInSlice(tracingExporters, tracing.Exporter) {
+		e.add("tracing.exporter must be empty or one of %s, got %q", strings.Join(tracingExporters[1:], ", "), tracing.Exporter)
+	}
+	if tracing.Exporter == consts.TracingExporterOTLPFile {
+		if strings.TrimSpace(tracing.File) == "" {
+			e.add("tracing.file is required for the otlp_file exporter")
+		} else {
+			validateParentDir(e, "tracing.file", tracing.File)
+		}
+	}
+	if tracing.Exporter != "" && tracing.ServiceName == "" {
+		e.add("tracing.service_name is required to export the spans")
+	}
+}
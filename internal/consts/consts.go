@@ -8,6 +8,23 @@ const (
 
 	DriverName = "oci8"
 
+	// Dialects supported by internal/storage, selected with db.dialect in config
+	DialectOracle   = "oracle"
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+
+	// Stores of the Idempotency-Key, selected with idempotency.store in config
+	IdempotencyStoreSQL    = "sql"
+	IdempotencyStoreMemory = "memory"
+
+	// MaxInList is the largest IN (...) list accepted by every dialect of internal/storage, Oracle fails
+	// with ORA-01795 above it. Longer lists are split with tools.Chunk.
+	MaxInList = 1000
+
+	// Exporters of the spans, selected with tracing.exporter in config; empty exports nothing
+	TracingExporterStdout   = "stdout"
+	TracingExporterOTLPFile = "otlp_file"
+
 	// DefaultPidFilename is default filename of pid file
 	DefaultPidFilename = "al_hilal_core.pid"
 
@@ -0,0 +1,42 @@
+package accounts
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/internal/middles"
+	accountService "github.com/internet-banking-ul/internal/modules/accounts/services"
+)
+
+// PolicyView - reading the accounts of the current company, any active company person
+const PolicyView = "accounts.view"
+
+func init() {
+	middles.RegisterPolicy(middles.Policy{Name: PolicyView})
+}
+
+type AccountHandlerImpl struct {
+	accountService.AccountService
+}
+
+func NewAccountHandler(
+	accountService accountService.AccountService,
+) *AccountHandlerImpl {
+	return &AccountHandlerImpl{
+		AccountService: accountService,
+	}
+}
+
+func (h *AccountHandlerImpl) RegisterAccounts(r fiber.Router) {
+	accountGroup := r.Group("accounts")
+	r.Use(
+		middles.SetupContextHolder(),
+		middles.SetupLanguage(),
+		middles.SetupRequestInfo(),
+		middles.NewFiberRecovery(middles.FiberRecoveryConfig{}),
+	)
+	{
+		accountGroup.Get("", middles.RequirePolicy(PolicyView), h.AccountList)
+		accountGroup.Get("/iban/:iban", middles.RequirePolicy(PolicyView), h.AccountByIBAN)
+		accountGroup.Get("/:id", middles.RequirePolicy(PolicyView), h.AccountByID)
+		accountGroup.Get("/:id/statement", middles.RequirePolicy(PolicyView), h.AccountStatement)
+	}
+}
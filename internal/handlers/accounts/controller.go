@@ -0,0 +1,93 @@
+package accounts
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/handlers"
+	"github.com/internet-banking-ul/internal/modules/accounts/dto"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/export"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/tools"
+	"go.uber.org/zap"
+)
+
+func (h *AccountHandlerImpl) AccountList(ctx *fiber.Ctx) error {
+	baseFilter, err := entities.NewBaseFilterFromQuery(ctx)
+	if err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	accounts, count, nextCursor, err := h.AccountService.List(ctx.Context(), *baseFilter)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(handlers.NewResponse(accounts, count).WithNextCursor(nextCursor))
+}
+
+func (h *AccountHandlerImpl) AccountByID(ctx *fiber.Ctx) error {
+	id, ok := accountID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.AccountIdInvalid)
+	}
+
+	account, err := h.AccountService.ByID(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(account)
+}
+
+func (h *AccountHandlerImpl) AccountByIBAN(ctx *fiber.Ctx) error {
+	account, err := h.AccountService.ByIBAN(ctx.Context(), ctx.Params("iban"))
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(account)
+}
+
+// AccountStatement sends the statement file of ?format=csv|xlsx|pdf (csv by default) for ?dateFrom=&dateTo=
+// or ?period=. The statement is written by a goroutine into the pipe fiber streams to the client;
+// when the client goes away fasthttp closes the pipe and the writing stops.
+func (h *AccountHandlerImpl) AccountStatement(ctx *fiber.Ctx) error {
+	id, ok := accountID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.AccountIdInvalid)
+	}
+
+	statement, err := h.AccountService.Statement(ctx.Context(), id, dto.StatementRequest{
+		Format:   ctx.Query("format", export.FormatCSV),
+		Period:   ctx.Query("period"),
+		DateFrom: ctx.Query("dateFrom"),
+		DateTo:   ctx.Query("dateTo"),
+	})
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	streamCtx := utils.DetachContext(ctx.Context())
+	body, w := io.Pipe()
+	go func() {
+		err := h.AccountService.WriteStatement(streamCtx, statement, w)
+		if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+			logger.WorkLoggerWithContext(streamCtx).Named("AccountStatement").Error("WriteStatement", zap.Error(err))
+		}
+		w.CloseWithError(err)
+	}()
+
+	return tools.SendExportedFile(ctx, body, statement.FileName(), statement.Format)
+}
+
+// accountID parses the :id route param, ok is false when it isn't a positive integer
+func accountID(ctx *fiber.Ctx) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	return id, err == nil && id > 0
+}
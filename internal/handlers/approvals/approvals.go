@@ -0,0 +1,49 @@
+package approvals
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/internal/middles"
+	approvalModel "github.com/internet-banking-ul/internal/modules/approvals/entities"
+	approvalService "github.com/internet-banking-ul/internal/modules/approvals/services"
+)
+
+const (
+	// PolicyView - reading and creating the approvals of the current company, any active company person
+	PolicyView = "approvals.view"
+	// PolicySign - signing and rejecting, the persons with a signing level
+	PolicySign = "approvals.sign"
+)
+
+func init() {
+	middles.RegisterPolicy(middles.Policy{Name: PolicyView})
+	middles.RegisterPolicy(middles.Policy{Name: PolicySign, SignLevels: approvalModel.SigningLevels})
+}
+
+type ApprovalHandlerImpl struct {
+	approvalService.ApprovalService
+}
+
+func NewApprovalHandler(
+	approvalService approvalService.ApprovalService,
+) *ApprovalHandlerImpl {
+	return &ApprovalHandlerImpl{
+		ApprovalService: approvalService,
+	}
+}
+
+func (h *ApprovalHandlerImpl) RegisterApprovals(r fiber.Router) {
+	approvalGroup := r.Group("approvals")
+	r.Use(
+		middles.SetupContextHolder(),
+		middles.SetupLanguage(),
+		middles.SetupRequestInfo(),
+		middles.NewFiberRecovery(middles.FiberRecoveryConfig{}),
+	)
+	{
+		approvalGroup.Get("", middles.RequirePolicy(PolicyView), h.ApprovalList)
+		approvalGroup.Post("", middles.RequirePolicy(PolicyView), h.ApprovalCreate)
+		approvalGroup.Get("/:id", middles.RequirePolicy(PolicyView), h.ApprovalByID)
+		approvalGroup.Post("/:id/sign", middles.RequirePolicy(PolicySign), h.ApprovalSign)
+		approvalGroup.Post("/:id/reject", middles.RequirePolicy(PolicySign), h.ApprovalReject)
+	}
+}
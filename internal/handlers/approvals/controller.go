@@ -0,0 +1,88 @@
+package approvals
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/handlers"
+	"github.com/internet-banking-ul/internal/modules/approvals/dto"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/tools"
+)
+
+func (h *ApprovalHandlerImpl) ApprovalList(ctx *fiber.Ctx) error {
+	baseFilter, err := entities.NewBaseFilterFromQuery(ctx)
+	if err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	approvals, count, nextCursor, err := h.ApprovalService.List(ctx.Context(), *baseFilter)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(handlers.NewResponse(approvals, count).WithNextCursor(nextCursor))
+}
+
+func (h *ApprovalHandlerImpl) ApprovalByID(ctx *fiber.Ctx) error {
+	id, ok := approvalID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.ApprovalIdInvalid)
+	}
+
+	approval, err := h.ApprovalService.ByID(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(approval)
+}
+
+func (h *ApprovalHandlerImpl) ApprovalCreate(ctx *fiber.Ctx) error {
+	var req dto.ApprovalCreateRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	approval, err := h.ApprovalService.Create(ctx.Context(), req)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(approval)
+}
+
+func (h *ApprovalHandlerImpl) ApprovalSign(ctx *fiber.Ctx) error {
+	id, ok := approvalID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.ApprovalIdInvalid)
+	}
+
+	approval, err := h.ApprovalService.Sign(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(approval)
+}
+
+func (h *ApprovalHandlerImpl) ApprovalReject(ctx *fiber.Ctx) error {
+	id, ok := approvalID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.ApprovalIdInvalid)
+	}
+
+	approval, err := h.ApprovalService.Reject(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(approval)
+}
+
+// approvalID parses the :id route param, ok is false when it isn't a positive integer
+func approvalID(ctx *fiber.Ctx) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	return id, err == nil && id > 0
+}
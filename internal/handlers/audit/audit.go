@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/internal/middles"
+	auditService "github.com/internet-banking-ul/internal/modules/audit/services"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+)
+
+// PolicyView - reading the audit trail of the current company, the company admins
+const PolicyView = "audit.view"
+
+func init() {
+	middles.RegisterPolicy(middles.Policy{
+		Name:  PolicyView,
+		Roles: []string{companyPersonModel.RoleAdmin},
+	})
+}
+
+type AuditHandlerImpl struct {
+	auditService.AuditService
+}
+
+func NewAuditHandler(
+	auditService auditService.AuditService,
+) *AuditHandlerImpl {
+	return &AuditHandlerImpl{
+		AuditService: auditService,
+	}
+}
+
+func (h *AuditHandlerImpl) RegisterAudit(r fiber.Router) {
+	auditGroup := r.Group("audit")
+	r.Use(
+		middles.SetupContextHolder(),
+		middles.SetupLanguage(),
+		middles.SetupRequestInfo(),
+		middles.NewFiberRecovery(middles.FiberRecoveryConfig{}),
+	)
+	{
+		auditGroup.Get("", middles.RequirePolicy(PolicyView), h.AuditList)
+		auditGroup.Get("/verify", middles.RequirePolicy(PolicyView), h.AuditVerify)
+	}
+}
@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/internal/handlers"
+	"github.com/internet-banking-ul/internal/modules/entities"
+)
+
+func (h *AuditHandlerImpl) AuditList(ctx *fiber.Ctx) error {
+	baseFilter, err := entities.NewBaseFilterFromQuery(ctx)
+	if err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	records, count, nextCursor, err := h.AuditService.List(ctx.Context(), *baseFilter)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(handlers.NewResponse(records, count).WithNextCursor(nextCursor))
+}
+
+// AuditVerify checks the hash chain of the trail of the current company
+func (h *AuditHandlerImpl) AuditVerify(ctx *fiber.Ctx) error {
+	result, err := h.AuditService.Verify(ctx.Context())
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
@@ -3,9 +3,25 @@ package customer
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/internet-banking-ul/internal/middles"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
 	companyPersonService "github.com/internet-banking-ul/internal/modules/company_person/services"
 )
 
+const (
+	// PolicyView - reading the persons of the current company, every person of the company
+	PolicyView = "company_person.view"
+	// PolicyManage - attaching, changing and revoking the persons of the current company
+	PolicyManage = "company_person.manage"
+)
+
+func init() {
+	middles.RegisterPolicy(middles.Policy{Name: PolicyView})
+	middles.RegisterPolicy(middles.Policy{
+		Name:  PolicyManage,
+		Roles: []string{companyPersonModel.RoleAdmin},
+	})
+}
+
 type CompanyPersonHandlerImpl struct {
 	companyPersonService.CompanyPersonService
 }
@@ -27,6 +43,11 @@ func (h *CompanyPersonHandlerImpl) RegisterCompanyPerson(r fiber.Router) {
 		middles.NewFiberRecovery(middles.FiberRecoveryConfig{}),
 	)
 	{
-		customerGroup.Get("", h.CompanyPersonList)
+		customerGroup.Get("", middles.RequirePolicy(PolicyView), h.CompanyPersonList)
+		customerGroup.Post("", middles.RequirePolicy(PolicyManage), h.CompanyPersonAttach)
+		customerGroup.Get("/:id", middles.RequirePolicy(PolicyView), h.CompanyPersonByID)
+		customerGroup.Patch("/:id", middles.RequirePolicy(PolicyManage), h.CompanyPersonPatch)
+		customerGroup.Put("/:id/validity", middles.RequirePolicy(PolicyManage), h.CompanyPersonSetValidity)
+		customerGroup.Delete("/:id", middles.RequirePolicy(PolicyManage), h.CompanyPersonRevoke)
 	}
 }
@@ -1,9 +1,14 @@
 package customer
 
 import (
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/helpers/apiErrors"
 	"github.com/internet-banking-ul/internal/handlers"
+	"github.com/internet-banking-ul/internal/modules/company_person/dto"
 	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/tools"
 )
 
 func (h *CompanyPersonHandlerImpl) CompanyPersonList(ctx *fiber.Ctx) error {
@@ -12,10 +17,95 @@ func (h *CompanyPersonHandlerImpl) CompanyPersonList(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	customers, count, err := h.CompanyPersonService.List(ctx.Context(), *baseFilter)
+	customers, count, nextCursor, err := h.CompanyPersonService.List(ctx.Context(), *baseFilter)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(handlers.NewResponse(customers, count).WithNextCursor(nextCursor))
+}
+
+func (h *CompanyPersonHandlerImpl) CompanyPersonByID(ctx *fiber.Ctx) error {
+	id, ok := companyPersonID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.CompanyPersonIdInvalid)
+	}
+
+	companyPerson, err := h.CompanyPersonService.ByID(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(companyPerson)
+}
+
+func (h *CompanyPersonHandlerImpl) CompanyPersonAttach(ctx *fiber.Ctx) error {
+	var req dto.CompanyPersonCreateRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	companyPerson, err := h.CompanyPersonService.Attach(ctx.Context(), req)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(companyPerson)
+}
+
+func (h *CompanyPersonHandlerImpl) CompanyPersonPatch(ctx *fiber.Ctx) error {
+	id, ok := companyPersonID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.CompanyPersonIdInvalid)
+	}
+
+	var req dto.CompanyPersonPatchRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	companyPerson, err := h.CompanyPersonService.Patch(ctx.Context(), id, req)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(companyPerson)
+}
+
+func (h *CompanyPersonHandlerImpl) CompanyPersonSetValidity(ctx *fiber.Ctx) error {
+	id, ok := companyPersonID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.CompanyPersonIdInvalid)
+	}
+
+	var req dto.CompanyPersonValidityRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	companyPerson, err := h.CompanyPersonService.SetValidity(ctx.Context(), id, req)
 	if err != nil {
-		return ctx.Status(fiber.ErrInternalServerError.Code).JSON(fiber.Map{"error": err.Error()})
+		return handlers.SendError(ctx, err)
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(handlers.NewResponse(customers, count))
+	return ctx.Status(fiber.StatusOK).JSON(companyPerson)
+}
+
+func (h *CompanyPersonHandlerImpl) CompanyPersonRevoke(ctx *fiber.Ctx) error {
+	id, ok := companyPersonID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.CompanyPersonIdInvalid)
+	}
+
+	if err := h.CompanyPersonService.Revoke(ctx.Context(), id); err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// companyPersonID parses the :id route param, ok is false when it isn't a positive integer
+func companyPersonID(ctx *fiber.Ctx) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	return id, err == nil && id > 0
 }
@@ -1,8 +1,14 @@
 package customer
 
 import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/helpers/apiErrors"
 	"github.com/internet-banking-ul/internal/handlers"
+	"github.com/internet-banking-ul/internal/modules/customer/dto"
 	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/tools"
 )
 
 func (h *CustomerHandlerImpl) CustomerList(ctx *fiber.Ctx) error {
@@ -11,10 +17,104 @@ func (h *CustomerHandlerImpl) CustomerList(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	customers, count, err := h.CustomerService.List(ctx.Context(), *baseFilter)
+	customers, count, nextCursor, err := h.CustomerService.List(ctx.Context(), *baseFilter)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(handlers.NewResponse(customers, count).WithNextCursor(nextCursor))
+}
+
+func (h *CustomerHandlerImpl) CustomerByID(ctx *fiber.Ctx) error {
+	id, ok := customerID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.CustomerIdInvalid)
+	}
+
+	customer, err := h.CustomerService.ByID(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(customer)
+}
+
+func (h *CustomerHandlerImpl) CustomerByExternalID(ctx *fiber.Ctx) error {
+	customer, err := h.CustomerService.ByExternalID(ctx.Context(), ctx.Params("externalId"))
 	if err != nil {
-		return ctx.Status(fiber.ErrInternalServerError.Code).JSON(fiber.Map{"error": err.Error()})
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(customer)
+}
+
+func (h *CustomerHandlerImpl) CustomerCreate(ctx *fiber.Ctx) error {
+	var req dto.CustomerRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	customer, err := h.CustomerService.Create(ctx.Context(), req)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(customer)
+}
+
+func (h *CustomerHandlerImpl) CustomerUpdate(ctx *fiber.Ctx) error {
+	id, ok := customerID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.CustomerIdInvalid)
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(handlers.NewResponse(customers, count))
+	var req dto.CustomerRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	customer, err := h.CustomerService.Update(ctx.Context(), id, req)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(customer)
+}
+
+func (h *CustomerHandlerImpl) CustomerPatch(ctx *fiber.Ctx) error {
+	id, ok := customerID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.CustomerIdInvalid)
+	}
+
+	var req dto.CustomerPatchRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	customer, err := h.CustomerService.Patch(ctx.Context(), id, req)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(customer)
+}
+
+func (h *CustomerHandlerImpl) CustomerDelete(ctx *fiber.Ctx) error {
+	id, ok := customerID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.CustomerIdInvalid)
+	}
+
+	if err := h.CustomerService.Delete(ctx.Context(), id); err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// customerID parses the :id route param, ok is false when it isn't a positive integer
+func customerID(ctx *fiber.Ctx) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	return id, err == nil && id > 0
 }
@@ -3,9 +3,28 @@ package customer
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/internet-banking-ul/internal/middles"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
 	customersvc "github.com/internet-banking-ul/internal/modules/customer/services"
 )
 
+const (
+	// PolicyView - reading the customers of the bank, the back-office operators
+	PolicyView = "customer.view"
+	// PolicyManage - creating, changing and deleting the customers, the back-office operators
+	PolicyManage = "customer.manage"
+)
+
+func init() {
+	middles.RegisterPolicy(middles.Policy{
+		Name:  PolicyView,
+		Roles: []string{companyPersonModel.RoleBackOffice},
+	})
+	middles.RegisterPolicy(middles.Policy{
+		Name:  PolicyManage,
+		Roles: []string{companyPersonModel.RoleBackOffice},
+	})
+}
+
 type CustomerHandlerImpl struct {
 	customersvc.CustomerService
 }
@@ -27,6 +46,12 @@ func (h *CustomerHandlerImpl) RegisterCustomer(r fiber.Router) {
 		middles.NewFiberRecovery(middles.FiberRecoveryConfig{}),
 	)
 	{
-		customerGroup.Get("", h.CustomerList)
+		customerGroup.Get("", middles.RequirePolicy(PolicyView), h.CustomerList)
+		customerGroup.Post("", middles.RequirePolicy(PolicyManage), h.CustomerCreate)
+		customerGroup.Get("/by-external-id/:externalId", middles.RequirePolicy(PolicyView), h.CustomerByExternalID)
+		customerGroup.Get("/:id", middles.RequirePolicy(PolicyView), h.CustomerByID)
+		customerGroup.Put("/:id", middles.RequirePolicy(PolicyManage), h.CustomerUpdate)
+		customerGroup.Patch("/:id", middles.RequirePolicy(PolicyManage), h.CustomerPatch)
+		customerGroup.Delete("/:id", middles.RequirePolicy(PolicyManage), h.CustomerDelete)
 	}
 }
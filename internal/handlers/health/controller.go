@@ -0,0 +1,38 @@
+package health
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/modules/health"
+	"github.com/internet-banking-ul/modules/logger"
+	"go.uber.org/zap"
+)
+
+func (h *HealthHandlerImpl) HealthLive(ctx *fiber.Ctx) error {
+	return sendReport(ctx, h.Registry.Live())
+}
+
+// HealthReady runs every check, 503 when one fails
+func (h *HealthHandlerImpl) HealthReady(ctx *fiber.Ctx) error {
+	return sendReport(ctx, h.Registry.Ready(ctx.Context()))
+}
+
+// HealthStartup is 503 until the readiness passes once
+func (h *HealthHandlerImpl) HealthStartup(ctx *fiber.Ctx) error {
+	return sendReport(ctx, h.Registry.Startup(ctx.Context()))
+}
+
+// sendReport answers the names and the statuses of the checks, the errors of the failed ones are logged
+func sendReport(ctx *fiber.Ctx, report health.Report) error {
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	if !report.OK() {
+		l := logger.WorkLoggerWithContext(ctx.Context()).Named("Health")
+		for _, check := range report.Checks {
+			if check.Status != health.StatusOK {
+				l.Warn("Check failed", zap.String("path", ctx.Path()), zap.String("check", check.Name),
+					zap.Float64("latencyMs", check.LatencyMs), zap.String("error", check.Error))
+			}
+		}
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(report)
+}
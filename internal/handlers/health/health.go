@@ -0,0 +1,28 @@
+package health
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/modules/health"
+)
+
+type HealthHandlerImpl struct {
+	*health.Registry
+}
+
+func NewHealthHandler(
+	registry *health.Registry,
+) *HealthHandlerImpl {
+	return &HealthHandlerImpl{
+		Registry: registry,
+	}
+}
+
+// RegisterHealth adds the probes, they need no token and aren't logged
+func (h *HealthHandlerImpl) RegisterHealth(r fiber.Router) {
+	healthGroup := r.Group("health")
+	{
+		healthGroup.Get("/live", h.HealthLive)
+		healthGroup.Get("/ready", h.HealthReady)
+		healthGroup.Get("/startup", h.HealthStartup)
+	}
+}
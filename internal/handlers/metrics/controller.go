@@ -0,0 +1,15 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/modules/metrics"
+)
+
+// contentType is the Prometheus text format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+func (h *MetricsHandlerImpl) Metrics(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, contentType)
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return metrics.Write(ctx, h.Registries...)
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/modules/metrics"
+)
+
+type MetricsHandlerImpl struct {
+	Registries []*metrics.Registry
+}
+
+func NewMetricsHandler(
+	registries ...*metrics.Registry,
+) *MetricsHandlerImpl {
+	return &MetricsHandlerImpl{
+		Registries: registries,
+	}
+}
+
+// RegisterMetrics adds /metrics, it needs no token: keep it reachable from the internal network only
+func (h *MetricsHandlerImpl) RegisterMetrics(r fiber.Router) {
+	r.Get("/metrics", h.Metrics)
+}
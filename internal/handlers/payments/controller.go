@@ -0,0 +1,140 @@
+package payments
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/handlers"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/modules/payments/dto"
+	"github.com/internet-banking-ul/tools"
+)
+
+func (h *PaymentHandlerImpl) PaymentList(ctx *fiber.Ctx) error {
+	baseFilter, err := entities.NewBaseFilterFromQuery(ctx)
+	if err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	payments, count, nextCursor, err := h.PaymentService.List(ctx.Context(), *baseFilter)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(handlers.NewResponse(payments, count).WithNextCursor(nextCursor))
+}
+
+func (h *PaymentHandlerImpl) PaymentByID(ctx *fiber.Ctx) error {
+	id, ok := paymentID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.PaymentIdInvalid)
+	}
+
+	payment, err := h.PaymentService.ByID(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(payment)
+}
+
+func (h *PaymentHandlerImpl) PaymentCreate(ctx *fiber.Ctx) error {
+	var req dto.PaymentRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	payment, err := h.PaymentService.Create(ctx.Context(), req)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(payment)
+}
+
+func (h *PaymentHandlerImpl) PaymentUpdate(ctx *fiber.Ctx) error {
+	id, ok := paymentID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.PaymentIdInvalid)
+	}
+
+	var req dto.PaymentPatchRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	payment, err := h.PaymentService.Update(ctx.Context(), id, req)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(payment)
+}
+
+func (h *PaymentHandlerImpl) PaymentSign(ctx *fiber.Ctx) error {
+	id, ok := paymentID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.PaymentIdInvalid)
+	}
+
+	payment, err := h.PaymentService.Sign(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(payment)
+}
+
+func (h *PaymentHandlerImpl) PaymentSend(ctx *fiber.Ctx) error {
+	id, ok := paymentID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.PaymentIdInvalid)
+	}
+
+	payment, err := h.PaymentService.Send(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(payment)
+}
+
+func (h *PaymentHandlerImpl) PaymentExecute(ctx *fiber.Ctx) error {
+	id, ok := paymentID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.PaymentIdInvalid)
+	}
+
+	payment, err := h.PaymentService.Execute(ctx.Context(), id)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(payment)
+}
+
+func (h *PaymentHandlerImpl) PaymentReject(ctx *fiber.Ctx) error {
+	id, ok := paymentID(ctx)
+	if !ok {
+		return apiErrors.Send(ctx, apiErrors.PaymentIdInvalid)
+	}
+
+	var req dto.PaymentRejectRequest
+	if err := tools.JSONDecode(ctx, &req); err != nil {
+		return ctx.Status(fiber.ErrBadRequest.Code).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	payment, err := h.PaymentService.Reject(ctx.Context(), id, req)
+	if err != nil {
+		return handlers.SendError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(payment)
+}
+
+// paymentID parses the :id route param, ok is false when it isn't a positive integer
+func paymentID(ctx *fiber.Ctx) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	return id, err == nil && id > 0
+}
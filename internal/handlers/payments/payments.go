@@ -0,0 +1,59 @@
+package payments
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/internal/middles"
+	approvalModel "github.com/internet-banking-ul/internal/modules/approvals/entities"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+	paymentService "github.com/internet-banking-ul/internal/modules/payments/services"
+)
+
+const (
+	// PolicyView - reading the payments of the current company, any active company person
+	PolicyView = "payments.view"
+	// PolicyEdit - creating, editing and sending the payments, any active company person
+	PolicyEdit = "payments.edit"
+	// PolicySign - signing the drafts, the persons with a signing level
+	PolicySign = "payments.sign"
+	// PolicyProcess - recording the result of the bank for the sent payments, the back-office operators
+	PolicyProcess = "payments.process"
+)
+
+func init() {
+	middles.RegisterPolicy(middles.Policy{Name: PolicyView})
+	middles.RegisterPolicy(middles.Policy{Name: PolicyEdit})
+	middles.RegisterPolicy(middles.Policy{Name: PolicySign, SignLevels: approvalModel.SigningLevels})
+	middles.RegisterPolicy(middles.Policy{Name: PolicyProcess, Roles: []string{companyPersonModel.RoleBackOffice}})
+}
+
+type PaymentHandlerImpl struct {
+	paymentService.PaymentService
+}
+
+func NewPaymentHandler(
+	paymentService paymentService.PaymentService,
+) *PaymentHandlerImpl {
+	return &PaymentHandlerImpl{
+		PaymentService: paymentService,
+	}
+}
+
+func (h *PaymentHandlerImpl) RegisterPayments(r fiber.Router) {
+	paymentGroup := r.Group("payments")
+	r.Use(
+		middles.SetupContextHolder(),
+		middles.SetupLanguage(),
+		middles.SetupRequestInfo(),
+		middles.NewFiberRecovery(middles.FiberRecoveryConfig{}),
+	)
+	{
+		paymentGroup.Get("", middles.RequirePolicy(PolicyView), h.PaymentList)
+		paymentGroup.Post("", middles.RequirePolicy(PolicyEdit), h.PaymentCreate)
+		paymentGroup.Get("/:id", middles.RequirePolicy(PolicyView), h.PaymentByID)
+		paymentGroup.Patch("/:id", middles.RequirePolicy(PolicyEdit), h.PaymentUpdate)
+		paymentGroup.Post("/:id/sign", middles.RequirePolicy(PolicySign), h.PaymentSign)
+		paymentGroup.Post("/:id/send", middles.RequirePolicy(PolicyEdit), h.PaymentSend)
+		paymentGroup.Post("/:id/execute", middles.RequirePolicy(PolicyProcess), h.PaymentExecute)
+		paymentGroup.Post("/:id/reject", middles.RequirePolicy(PolicyProcess), h.PaymentReject)
+	}
+}
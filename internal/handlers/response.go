@@ -1,8 +1,15 @@
 package handlers
 
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/helpers/apiErrors"
+)
+
 type Response struct {
 	Rows  any   `json:"rows"`
 	Total int64 `json:"total"`
+	// NextCursor is passed as ?cursor= to get the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 func NewResponse(rows any, total int64) *Response {
@@ -11,3 +18,17 @@ func NewResponse(rows any, total int64) *Response {
 		Total: total,
 	}
 }
+
+// WithNextCursor sets the cursor of the next page
+func (r *Response) WithNextCursor(cursor string) *Response {
+	r.NextCursor = cursor
+	return r
+}
+
+// SendError answers with the apiErrors payload, or 500 for any other error
+func SendError(ctx *fiber.Ctx, err error) error {
+	if apiErr := apiErrors.ParseError(err); apiErr != nil {
+		return apiErrors.SendErr(ctx, apiErr)
+	}
+	return ctx.Status(fiber.ErrInternalServerError.Code).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,155 @@
+package middles
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/tools"
+	"go.uber.org/zap"
+)
+
+// AuthenticateConfig defines the config for Authenticate
+type AuthenticateConfig struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c *fiber.Ctx) bool
+
+	// HMACSecret verifies HS256 tokens, nil disables HS256
+	HMACSecret []byte
+
+	// PublicKey verifies RS256 tokens, nil disables RS256
+	PublicKey *rsa.PublicKey
+
+	// Issuer and Audience are checked when not empty
+	Issuer   string
+	Audience string
+
+	// ClockSkew is the leeway of exp, nbf and iat
+	ClockSkew time.Duration
+
+	// Now is the clock of the checks.
+	//
+	// Optional. Default: time.Now
+	Now func() time.Time
+}
+
+// Claims of the bearer token. Subject is the numeric user account id.
+type Claims struct {
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// NewAuthenticateConfig builds the config from the auth section, reading the RSA public key file
+func NewAuthenticateConfig(cfg config.Auth) (AuthenticateConfig, error) {
+	authCfg := AuthenticateConfig{
+		Issuer:    cfg.Issuer,
+		Audience:  cfg.Audience,
+		ClockSkew: cfg.ClockSkew,
+	}
+
+	if cfg.HMACSecret != "" {
+		authCfg.HMACSecret = []byte(cfg.HMACSecret)
+	}
+
+	if cfg.PublicKeyFile != "" {
+		pem, err := tools.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return authCfg, fmt.Errorf("reading auth public key: %w", err)
+		}
+		if authCfg.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+			return authCfg, fmt.Errorf("parsing auth public key %s: %w", cfg.PublicKeyFile, err)
+		}
+	}
+
+	return authCfg, nil
+}
+
+func (cfg AuthenticateConfig) methods() (methods []string) {
+	if cfg.HMACSecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.PublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	return methods
+}
+
+// key returns the key of the token alg, the parser has already rejected the algs not in methods
+func (cfg AuthenticateConfig) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return cfg.HMACSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		return cfg.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+// Authenticate verifies the `Authorization: Bearer <jwt>` header and stores the user of the token
+// in the context holder (utils.AttributeCurrentUserID, AttributeCurrentUsername, AttributeCurrentEmail).
+// A request without the token gets apiErrors.UserNotLogined, an invalid or expired token apiErrors.UserUnauthorized.
+// SetupContextHolder has to run before it.
+func Authenticate(cfg AuthenticateConfig) fiber.Handler {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(cfg.methods()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(cfg.ClockSkew),
+		jwt.WithTimeFunc(func() time.Time { return cfg.Now() }),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	parser := jwt.NewParser(options...)
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		header := c.Get(fiber.HeaderAuthorization)
+		scheme, raw, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(raw) == "" {
+			return apiErrors.Send(c, apiErrors.UserNotLogined)
+		}
+
+		claims := new(Claims)
+		if _, err := parser.ParseWithClaims(strings.TrimSpace(raw), claims, cfg.key); err != nil {
+			logger.WorkLoggerWithContext(c.Context()).Named("Authenticate").Info("Invalid token", zap.Error(err))
+			return apiErrors.Send(c, apiErrors.UserUnauthorized)
+		}
+
+		// the context holder keeps the user id as int32, see utils.ContextGetCurrentUserID
+		userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+		if err != nil || userID <= 0 || userID > math.MaxInt32 {
+			logger.WorkLoggerWithContext(c.Context()).Named("Authenticate").Info("Invalid token subject", zap.String("sub", claims.Subject))
+			return apiErrors.Send(c, apiErrors.UserUnauthorized)
+		}
+
+		utils.SetAttribute(c.Context(), utils.AttributeCurrentUserID, int32(userID))
+		utils.SetAttribute(c.Context(), utils.AttributeCurrentUsername, claims.Username)
+		utils.SetAttribute(c.Context(), utils.AttributeCurrentEmail, claims.Email)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,155 @@
+package middles
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var authNow = time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type authFixture struct {
+	app       *fiber.App
+	secret    []byte
+	key       *rsa.PrivateKey
+	publicPEM []byte
+}
+
+func newAuthFixture(t *testing.T) authFixture {
+	if logger.WorkLogger == nil {
+		logger.WorkLogger, logger.SqlLogger = zap.NewNop(), zap.NewNop()
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	keyFile := filepath.Join(t.TempDir(), "auth.pem")
+	require.NoError(t, ioutil.WriteFile(keyFile, publicPEM, 0600))
+
+	f := authFixture{secret: []byte("test-secret"), key: key, publicPEM: publicPEM}
+
+	cfg, err := NewAuthenticateConfig(config.Auth{
+		HMACSecret:    string(f.secret),
+		PublicKeyFile: keyFile,
+		Issuer:        "idp",
+		ClockSkew:     30 * time.Second,
+	})
+	require.NoError(t, err)
+	cfg.Now = func() time.Time { return authNow }
+
+	f.app = fiber.New()
+	f.app.Use(SetupContextHolder(), Authenticate(cfg), SetupContextHolder())
+	f.app.Get("/", func(c *fiber.Ctx) error {
+		userID, _ := utils.ContextGetCurrentUserID(c.Context())
+		return c.JSON(fiber.Map{"userID": userID})
+	})
+
+	return f
+}
+
+func (f authFixture) claims(sub string, exp time.Time) Claims {
+	return Claims{
+		Username: "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sub,
+			Issuer:    "idp",
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	}
+}
+
+func (f authFixture) do(t *testing.T, authorization string) (int, string) {
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if authorization != "" {
+		req.Header.Set(fiber.HeaderAuthorization, authorization)
+	}
+	resp, err := f.app.Test(req)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, claims jwt.Claims, key interface{}) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return "Bearer " + token
+}
+
+func TestAuthenticate(t *testing.T) {
+	f := newAuthFixture(t)
+	valid := f.claims("42", authNow.Add(time.Hour))
+
+	status, body := f.do(t, "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.JSONEq(t, `{"error":"You must login to do this"}`, body)
+
+	status, _ = f.do(t, "Basic dXNlcjpwYXNz")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	status, body = f.do(t, sign(t, jwt.SigningMethodHS256, valid, f.secret))
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.JSONEq(t, `{"userID":42}`, body)
+
+	status, body = f.do(t, sign(t, jwt.SigningMethodRS256, valid, f.key))
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.JSONEq(t, `{"userID":42}`, body)
+
+	// expired, but within the clock skew
+	status, _ = f.do(t, sign(t, jwt.SigningMethodHS256, f.claims("42", authNow.Add(-10*time.Second)), f.secret))
+	assert.Equal(t, fiber.StatusOK, status)
+
+	notBefore := valid
+	notBefore.NotBefore = jwt.NewNumericDate(authNow.Add(time.Minute))
+	foreignIssuer := valid
+	foreignIssuer.Issuer = "other"
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+
+	for name, authorization := range map[string]string{
+		"expired":        sign(t, jwt.SigningMethodHS256, f.claims("42", authNow.Add(-time.Minute)), f.secret),
+		"not before":     sign(t, jwt.SigningMethodHS256, notBefore, f.secret),
+		"issuer":         sign(t, jwt.SigningMethodHS256, foreignIssuer, f.secret),
+		"no expiry":      sign(t, jwt.SigningMethodHS256, noExpiry, f.secret),
+		"wrong secret":   sign(t, jwt.SigningMethodHS256, valid, []byte("other")),
+		"alg none":       sign(t, jwt.SigningMethodNone, valid, jwt.UnsafeAllowNoneSignatureType),
+		"alg HS512":      sign(t, jwt.SigningMethodHS512, valid, f.secret),
+		"public key PEM": sign(t, jwt.SigningMethodHS256, valid, f.publicPEM),
+		"subject":        sign(t, jwt.SigningMethodHS256, f.claims("admin", authNow.Add(time.Hour)), f.secret),
+		"subject range":  sign(t, jwt.SigningMethodHS256, f.claims("4294967296", authNow.Add(time.Hour)), f.secret),
+		"garbage":        "Bearer abc.def.ghi",
+	} {
+		status, body = f.do(t, authorization)
+		assert.Equal(t, fiber.StatusUnauthorized, status, name)
+		assert.JSONEq(t, `{"error":"unauthorized"}`, body, name)
+	}
+}
+
+func TestNewAuthenticateConfig_Errors(t *testing.T) {
+	_, err := NewAuthenticateConfig(config.Auth{PublicKeyFile: filepath.Join(t.TempDir(), "missing.pem")})
+	require.Error(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "bad.pem")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("not a key"), 0600))
+	_, err = NewAuthenticateConfig(config.Auth{PublicKeyFile: keyFile})
+	require.Error(t, err)
+}
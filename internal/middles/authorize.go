@@ -0,0 +1,206 @@
+package middles
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"go.uber.org/zap"
+)
+
+// HeaderCompanyID selects the company the caller acts for, it may be omitted when the caller belongs to one company only
+const HeaderCompanyID = "X-DigitalBank-company-id"
+
+// Policy is the declarative rule of a route: the caller needs an active assignment in the current company
+// matching both lists
+type Policy struct {
+	// Name is the key of RegisterPolicy and RequirePolicy
+	Name string
+
+	// Roles - any of the OrganizationRole values, empty allows every role
+	Roles []string
+
+	// SignLevels - any of the SignLevel values, empty allows every sign level
+	SignLevels []string
+}
+
+// Allows reports whether the assignment satisfies the policy, roles are compared case insensitive
+func (p Policy) Allows(person companyPersonModel.CompanyPerson) bool {
+	return matchesAny(p.Roles, person.OrganizationRole) && matchesAny(p.SignLevels, person.SignLevel)
+}
+
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompanyPersonsResolver returns the assignments of the user active at the time, in every company
+type CompanyPersonsResolver func(ctx context.Context, userAccountID int64, at time.Time) (companyPersonModel.CompanyPersonList, error)
+
+// companyPersonsResolverKey - the Locals key of the CompanyPersonsResolver of the request
+const companyPersonsResolverKey = "company_persons_resolver"
+
+var (
+	policiesMu sync.RWMutex
+	policies   = map[string]Policy{}
+)
+
+// RegisterPolicy registers the policy of a module, usually from init of its handlers package.
+// It panics on an empty or already registered name.
+func RegisterPolicy(policy Policy) {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+
+	if policy.Name == "" {
+		panic("middles: policy without name")
+	}
+	if _, ok := policies[policy.Name]; ok {
+		panic(fmt.Sprintf("middles: policy %q is already registered", policy.Name))
+	}
+	policies[policy.Name] = policy
+}
+
+// Policies returns the registered policies ordered by name
+func Policies() []Policy {
+	policiesMu.RLock()
+	defer policiesMu.RUnlock()
+
+	result := make([]Policy, 0, len(policies))
+	for _, p := range policies {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// CompanyPersons sets the source of the caller's assignments used by the policies of the following handlers,
+// it is added to the route group before them
+func CompanyPersons(resolver CompanyPersonsResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(companyPersonsResolverKey, resolver)
+		return c.Next()
+	}
+}
+
+// RequirePolicy checks the registered policy, it panics when the route is registered with an unknown name
+func RequirePolicy(name string) fiber.Handler {
+	policiesMu.RLock()
+	policy, ok := policies[name]
+	policiesMu.RUnlock()
+
+	if !ok {
+		panic(fmt.Sprintf("middles: policy %q is not registered", name))
+	}
+	return Require(policy)
+}
+
+// RequireRole allows the callers having any of the organization roles in the current company
+func RequireRole(roles ...string) fiber.Handler {
+	return Require(Policy{Name: "role:" + strings.Join(roles, ","), Roles: roles})
+}
+
+// RequireSignLevel allows the callers having any of the sign levels in the current company
+func RequireSignLevel(levels ...string) fiber.Handler {
+	return Require(Policy{Name: "sign_level:" + strings.Join(levels, ","), SignLevels: levels})
+}
+
+// Require resolves the caller's active CompanyPerson rows (validity window, not deleted), chooses the current company
+// (see HeaderCompanyID) and answers apiErrors.AccessDenied unless one of the rows satisfies the policy.
+// The current company and its rows are kept in the context holder, see utils.ContextGetCurrentCompanyID,
+// so the following policies of the request don't query them again. Authenticate and CompanyPersons have to run before it.
+func Require(policy Policy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		persons, err := currentCompanyPersons(c)
+		if err != nil {
+			if apiErr := apiErrors.ParseError(err); apiErr != nil {
+				return apiErrors.SendErr(c, apiErr)
+			}
+			logger.WorkLoggerWithContext(c.Context()).Named("Require").Error("Resolve company persons", zap.Error(err))
+			return apiErrors.Send(c, apiErrors.ServerError)
+		}
+
+		for _, person := range persons {
+			if policy.Allows(*person) {
+				return c.Next()
+			}
+		}
+
+		logger.WorkLoggerWithContext(c.Context()).Named("Require").Info("Access denied", zap.String("policy", policy.Name))
+		return apiErrors.Send(c, apiErrors.AccessDenied)
+	}
+}
+
+// currentCompanyPersons returns the caller's active rows in the current company, loading them on the first call of the request
+func currentCompanyPersons(c *fiber.Ctx) (companyPersonModel.CompanyPersonList, error) {
+	ctx := c.Context()
+
+	if persons, ok := companyPersonModel.ContextGetCurrentCompanyPersons(ctx); ok {
+		return persons, nil
+	}
+
+	userID, ok := utils.ContextGetCurrentUserID(ctx)
+	if !ok {
+		return nil, apiErrors.ThrowError(apiErrors.UserNotLogined)
+	}
+
+	resolve, _ := c.Locals(companyPersonsResolverKey).(CompanyPersonsResolver)
+	if resolve == nil {
+		return nil, fmt.Errorf("company persons resolver is not set")
+	}
+
+	active, err := resolve(ctx, int64(userID), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	companyID, ok := currentCompanyID(c, active)
+	if !ok {
+		return nil, apiErrors.ThrowError(apiErrors.AccessDenied)
+	}
+
+	persons := active.ByCompanyID(companyID)
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyID, companyID)
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyPersons, persons)
+
+	return persons, nil
+}
+
+// currentCompanyID is the company of HeaderCompanyID, or the only company of the caller when the header is omitted
+func currentCompanyID(c *fiber.Ctx, active companyPersonModel.CompanyPersonList) (int64, bool) {
+	companies := active.CompanyIDs()
+
+	header := strings.TrimSpace(c.Get(HeaderCompanyID))
+	if header == "" {
+		if len(companies) != 1 {
+			return 0, false
+		}
+		return companies[0], true
+	}
+
+	companyID, err := strconv.ParseInt(header, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	for _, id := range companies {
+		if id == companyID {
+			return companyID, true
+		}
+	}
+	return 0, false
+}
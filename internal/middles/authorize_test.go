@@ -0,0 +1,107 @@
+package middles
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRequire(t *testing.T) {
+	if logger.WorkLogger == nil {
+		logger.WorkLogger, logger.SqlLogger = zap.NewNop(), zap.NewNop()
+	}
+
+	// user 1 is the admin of company 10 and signs with B in company 20, user 2 signs with A in company 10
+	calls := 0
+	resolver := CompanyPersonsResolver(func(_ context.Context, userAccountID int64, _ time.Time) (companyPersonModel.CompanyPersonList, error) {
+		calls++
+		switch userAccountID {
+		case 1:
+			return companyPersonModel.CompanyPersonList{
+				{ID: 1, CompanyID: 10, UserAccountID: 1, SignLevel: companyPersonModel.SignLevelNone, OrganizationRole: "admin"},
+				{ID: 2, CompanyID: 20, UserAccountID: 1, SignLevel: companyPersonModel.SignLevelB, OrganizationRole: "ACCOUNTANT",
+					ValidTo: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}},
+			}, nil
+		case 2:
+			return companyPersonModel.CompanyPersonList{
+				{ID: 3, CompanyID: 10, UserAccountID: 2, SignLevel: companyPersonModel.SignLevelA, OrganizationRole: "DIRECTOR"},
+			}, nil
+		default:
+			return companyPersonModel.CompanyPersonList{}, nil
+		}
+	})
+
+	RegisterPolicy(Policy{Name: "test.sign", SignLevels: []string{companyPersonModel.SignLevelA, companyPersonModel.SignLevelB}})
+	assert.Panics(t, func() { RegisterPolicy(Policy{Name: "test.sign"}) })
+	assert.Panics(t, func() { RequirePolicy("test.unknown") })
+
+	app := fiber.New()
+	app.Use(SetupContextHolder(), CompanyPersons(resolver), func(c *fiber.Ctx) error {
+		if id, err := strconv.Atoi(c.Get("X-User")); err == nil {
+			utils.SetAttribute(c.Context(), utils.AttributeCurrentUserID, int32(id))
+		}
+		return c.Next()
+	})
+	ok := func(c *fiber.Ctx) error {
+		companyID, _ := utils.ContextGetCurrentCompanyID(c.Context())
+		return c.JSON(fiber.Map{"companyID": companyID})
+	}
+	app.Get("/admin", RequireRole(companyPersonModel.RoleAdmin), ok)
+	app.Get("/sign", RequirePolicy("test.sign"), ok)
+	app.Get("/both", RequireRole(companyPersonModel.RoleAdmin), RequireSignLevel(companyPersonModel.SignLevelA), ok)
+
+	do := func(path, user, company string) (int, string) {
+		req := httptest.NewRequest(fiber.MethodGet, path, nil)
+		req.Header.Set("X-User", user)
+		if company != "" {
+			req.Header.Set(HeaderCompanyID, company)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	for _, tc := range []struct {
+		path, user, company string
+		status              int
+		body                string
+	}{
+		{"/admin", "", "", fiber.StatusUnauthorized, `{"error":"You must login to do this"}`},
+		{"/admin", "1", "10", fiber.StatusOK, `{"companyID":10}`},
+		{"/admin", "1", "20", fiber.StatusForbidden, `{"error":"Access Denied"}`},
+		// user 1 belongs to two companies, the header is required
+		{"/admin", "1", "", fiber.StatusForbidden, `{"error":"Access Denied"}`},
+		{"/admin", "1", "abc", fiber.StatusForbidden, `{"error":"Access Denied"}`},
+		{"/admin", "2", "", fiber.StatusForbidden, `{"error":"Access Denied"}`},
+		{"/admin", "3", "", fiber.StatusForbidden, `{"error":"Access Denied"}`},
+		{"/sign", "1", "20", fiber.StatusOK, `{"companyID":20}`},
+		{"/sign", "1", "10", fiber.StatusForbidden, `{"error":"Access Denied"}`},
+		{"/sign", "2", "", fiber.StatusOK, `{"companyID":10}`},
+		{"/sign", "2", "30", fiber.StatusForbidden, `{"error":"Access Denied"}`},
+		{"/both", "1", "10", fiber.StatusForbidden, `{"error":"Access Denied"}`},
+	} {
+		status, body := do(tc.path, tc.user, tc.company)
+		assert.Equal(t, tc.status, status, "%+v", tc)
+		assert.JSONEq(t, tc.body, body, "%+v", tc)
+	}
+
+	// the rows are resolved once per request
+	calls = 0
+	status, _ := do("/both", "1", "10")
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Equal(t, 1, calls)
+}
@@ -7,9 +7,13 @@ import (
 	"github.com/internet-banking-ul/internal/utils"
 )
 
+// SetupContextHolder creates the per request attribute map. An already created map is kept,
+// so the handlers may register it again after Authenticate filled it.
 func SetupContextHolder() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		c.Locals(utils.ContextHolderKey, &sync.Map{})
+		if _, ok := c.Locals(utils.ContextHolderKey).(*sync.Map); !ok {
+			c.Locals(utils.ContextHolderKey, &sync.Map{})
+		}
 		return c.Next()
 	}
 }
@@ -0,0 +1,81 @@
+package middles
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag(t *testing.T) {
+	app := fiber.New()
+	app.Use(ETag())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("statement")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	assert.Equal(t, `"9-`, etag[:3])
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+		resp, err = app.Test(r)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotModified, resp.StatusCode, ifNoneMatch)
+	}
+}
+
+func TestETag_Stream(t *testing.T) {
+	produced := make(chan struct{})
+	app := fiber.New()
+	app.Use(ETag())
+	app.Get("/statement", func(c *fiber.Ctx) error {
+		body, w := io.Pipe()
+		go func() {
+			w.Write([]byte("opening balance\n"))
+			// the rest is written once the client has read the first line
+			<-produced
+			w.Write([]byte("closing balance\n"))
+			w.Close()
+		}()
+		return tools.SendExportedFile(c, body, "statement", "csv")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(ln)
+	defer app.Shutdown()
+	var once sync.Once
+	finish := func() { once.Do(func() { close(produced) }) }
+	// the producer is released when the first chunk doesn't come
+	defer finish()
+
+	// a kept-alive connection would hold Shutdown
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + ln.Addr().String() + "/statement")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Empty(t, resp.Header.Get(fiber.HeaderETag))
+
+	body := bufio.NewReader(resp.Body)
+	first, err := body.ReadString('\n')
+	require.NoError(t, err, "the first chunk is sent before the producer finishes")
+	assert.Equal(t, "opening balance\n", first)
+
+	finish()
+	rest, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "closing balance\n", string(rest))
+}
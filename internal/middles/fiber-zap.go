@@ -53,11 +53,13 @@ func Logger(duration time.Duration, logger *zap.Logger) fiber.Handler {
 		method := c.Method()
 		statusCode := c.Response().StatusCode()
 		path := c.Path()
+		// the request and trace IDs of Tracing
+		l := logger2.WithContext(logger, c.Context())
 
 		switch {
 		case statusCode >= 400 && statusCode <= 499:
 			{
-				logger.Warn("[Fiber]",
+				l.Warn("[Fiber]",
 					zap.Int("statusCode", statusCode),
 					zap.String("latency", latency.String()),
 					zap.String("clientIP", clientIP),
@@ -68,7 +70,7 @@ func Logger(duration time.Duration, logger *zap.Logger) fiber.Handler {
 			}
 		case statusCode >= 500:
 			{
-				logger.Error("[Fiber]",
+				l.Error("[Fiber]",
 					zap.Int("statusCode", statusCode),
 					zap.String("latency", latency.String()),
 					zap.String("clientIP", clientIP),
@@ -81,7 +83,7 @@ func Logger(duration time.Duration, logger *zap.Logger) fiber.Handler {
 			if err != nil {
 
 			}
-			logger.Info("[Fiber]",
+			l.Info("[Fiber]",
 				zap.Int("statusCode", statusCode),
 				zap.String("latency", latency.String()),
 				zap.String("clientIP", clientIP),
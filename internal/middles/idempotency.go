@@ -0,0 +1,411 @@
+package middles
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	fiberUtils "github.com/gofiber/fiber/v2/utils"
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/internal/consts"
+	idempotencyModel "github.com/internet-banking-ul/internal/modules/idempotency/entities"
+	idempotencyRepo "github.com/internet-banking-ul/internal/modules/idempotency/repositories"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/health"
+	"github.com/internet-banking-ul/modules/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+
+	// HeaderIdempotentReplayed marks the stored response sent again
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	idempotencyKeyMaxLength = 255
+)
+
+// IdempotencyConfig defines the config for Idempotency
+type IdempotencyConfig struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c *fiber.Ctx) bool
+
+	// Store keeps the keys and the responses, required
+	Store idempotencyRepo.RepositoryIdempotency
+
+	// TTL - a key older than TTL is forgotten, zero keeps the keys until the store evicts them
+	TTL time.Duration
+
+	// Wait is how long a retry waits for the first request with the key to finish
+	// before apiErrors.IdempotencyKeyInProgress.
+	//
+	// Optional. Default: 0, the retry doesn't wait
+	Wait time.Duration
+
+	// Lease is how long the key stays reserved without a heartbeat of the request holding it. The request
+	// extends it every Lease/3 while it runs; a retry takes over the key whose lease has passed, e.g. when
+	// the instance running the first request has crashed.
+	//
+	// Optional. Default: 30s
+	Lease time.Duration
+
+	// PollInterval is how often a waiting retry reads the store, the first request may run on another instance.
+	//
+	// Optional. Default: 100ms
+	PollInterval time.Duration
+
+	// CallerID identifies the caller the keys belong to.
+	//
+	// Optional. Default: utils.ContextGetCurrentUserID
+	CallerID func(c *fiber.Ctx) (int64, bool)
+
+	// Now is the clock of the keys.
+	//
+	// Optional. Default: time.Now
+	Now func() time.Time
+
+	// Expiry beats after every run of RunExpiry, the server registers it as a health check.
+	//
+	// Optional. Default: nil
+	Expiry *health.Heartbeat
+}
+
+// NewIdempotencyConfig builds the config from the idempotency section, the sql store keeps the keys in db
+func NewIdempotencyConfig(cfg config.Idempotency, db *storage.DB) IdempotencyConfig {
+	idempotencyCfg := IdempotencyConfig{
+		TTL:   cfg.TTL,
+		Wait:  cfg.Wait,
+		Lease: cfg.Lease,
+	}
+
+	if cfg.Store == consts.IdempotencyStoreMemory {
+		idempotencyCfg.Store = idempotencyRepo.NewMemoryRepository(cfg.Capacity)
+	} else {
+		idempotencyCfg.Store = idempotencyRepo.NewIdempotencyRepository(db)
+	}
+	if cfg.TTL > 0 {
+		idempotencyCfg.Expiry = health.NewHeartbeat("idempotency_expiry", 3*cfg.ExpireInterval)
+	}
+
+	return idempotencyCfg
+}
+
+// RunExpiry deletes the keys past TTL every interval until ctx is done
+func (cfg IdempotencyConfig) RunExpiry(ctx context.Context, every time.Duration) {
+	if cfg.TTL <= 0 {
+		return
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := cfg.Store.DeleteExpiredIdempotencyKeys(ctx, now().UTC().Add(-cfg.TTL))
+			if err != nil {
+				logger.WorkLoggerWithContext(ctx).Error("Error delete expired idempotency keys", zap.Error(err))
+				continue
+			}
+			cfg.Expiry.Beat()
+			if deleted > 0 {
+				logger.WorkLoggerWithContext(ctx).Info("Idempotency keys expired", zap.Int64("count", deleted))
+			}
+		}
+	}
+}
+
+// Idempotency makes a POST, PUT, PATCH or DELETE request with the Idempotency-Key header run once per caller
+// and key. The first request reserves the key and stores its response; a retry with the same method, URL,
+// company (HeaderCompanyID) and body gets the stored response again with HeaderIdempotentReplayed. Another
+// request with the key gets apiErrors.IdempotencyKeyReused. A retry while the first request still runs waits
+// up to Wait for it and gets apiErrors.IdempotencyKeyInProgress when it is still running, unless the lease of
+// the first request has passed (see Lease) and the retry runs instead. A failed request (an error or a 5xx
+// status) or a response that can't be stored releases the key, so it may be retried. The requests without
+// the header aren't protected.
+//
+// The key is copied unless the app is Immutable and the captured body is always copied, fasthttp reuses its
+// buffers after the handler. Register it after Authenticate and inside compress and etag, so the plain body is
+// stored and a replay is compressed and tagged like the first response; a body encoded already isn't stored.
+func Idempotency(config IdempotencyConfig) fiber.Handler {
+	cfg := config
+	if cfg.Store == nil {
+		panic("middles: idempotency store is nil")
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 30 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 100 * time.Millisecond
+	}
+	if cfg.CallerID == nil {
+		cfg.CallerID = func(c *fiber.Ctx) (int64, bool) {
+			userID, ok := utils.ContextGetCurrentUserID(c.Context())
+			return int64(userID), ok
+		}
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	running := &runningKeys{done: map[string]chan struct{}{}}
+
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" || !mutatingMethod(c.Method()) {
+			return c.Next()
+		}
+		if !validIdempotencyKey(key) {
+			return apiErrors.Send(c, apiErrors.IdempotencyKeyInvalid)
+		}
+		if !c.App().Config().Immutable {
+			key = fiberUtils.CopyString(key)
+		}
+
+		callerID, ok := cfg.CallerID(c)
+		if !ok {
+			return apiErrors.Send(c, apiErrors.UserNotLogined)
+		}
+
+		ctx := c.Context()
+		l := logger.WorkLoggerWithContext(ctx).Named("Idempotency")
+
+		record := idempotencyModel.IdempotencyKey{
+			CallerID:    callerID,
+			Key:         key,
+			RequestHash: requestHash(c),
+		}
+		runningKey := strconv.FormatInt(callerID, 10) + ":" + key
+		deadline := time.Now().Add(cfg.Wait)
+
+		var reserveErr error
+		for {
+			stored, err := cfg.Store.IdempotencyKey(ctx, callerID, key)
+			now := cfg.Now().UTC()
+			if err == nil {
+				reserveErr = nil
+			}
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				if reserveErr != nil {
+					l.Error("ReserveIdempotencyKey", zap.Error(reserveErr))
+					return apiErrors.Send(c, apiErrors.ServerError)
+				}
+				record.CreatedAt = now
+				record.LockedUntil = sql.NullTime{Time: record.CreatedAt.Add(cfg.Lease), Valid: true}
+				if reserveErr = cfg.Store.ReserveIdempotencyKey(ctx, record); reserveErr == nil {
+					return cfg.handle(c, l, record, running.start(runningKey))
+				}
+				// a concurrent request has reserved the key first, read it again
+				continue
+			case err != nil:
+				l.Error("IdempotencyKey", zap.Error(err))
+				return apiErrors.Send(c, apiErrors.ServerError)
+			case stored.Expired(now, cfg.TTL):
+				if err := cfg.Store.ReleaseIdempotencyKey(ctx, callerID, key); err != nil && !errors.Is(err, sql.ErrNoRows) {
+					l.Error("ReleaseIdempotencyKey", zap.Error(err))
+					return apiErrors.Send(c, apiErrors.ServerError)
+				}
+				continue
+			case stored.RequestHash != record.RequestHash:
+				return apiErrors.Send(c, apiErrors.IdempotencyKeyReused)
+			case stored.Completed():
+				c.Set(HeaderIdempotentReplayed, "true")
+				c.Set(fiber.HeaderContentType, stored.ContentType.String)
+				return c.Status(int(stored.ResponseStatus.Int64)).Send(stored.ResponseBody)
+			case stored.Stale(now):
+				record.CreatedAt = stored.CreatedAt
+				record.LockedUntil = sql.NullTime{Time: now.Add(cfg.Lease), Valid: true}
+				err := cfg.Store.TakeOverIdempotencyKey(ctx, record, now)
+				if err == nil {
+					l.Warn("Stale idempotency key is taken over", zap.Int64("callerID", callerID), zap.String("key", key))
+					return cfg.handle(c, l, record, running.start(runningKey))
+				}
+				if !errors.Is(err, sql.ErrNoRows) {
+					l.Error("TakeOverIdempotencyKey", zap.Error(err))
+					return apiErrors.Send(c, apiErrors.ServerError)
+				}
+				// another retry has taken it over or the first request has just finished, read it again
+				continue
+			}
+
+			// the first request is in progress
+			if !running.wait(runningKey, deadline, cfg.PollInterval) {
+				return apiErrors.Send(c, apiErrors.IdempotencyKeyInProgress)
+			}
+		}
+	}
+}
+
+// handle runs the request of the reserved key, extending its lease meanwhile, and stores its response.
+// done wakes the waiting retries.
+func (cfg IdempotencyConfig) handle(c *fiber.Ctx, l *zap.Logger, record idempotencyModel.IdempotencyKey, done func()) error {
+	defer done()
+
+	ctx := c.Context()
+	stopHeartbeat := cfg.heartbeat(ctx, l, record)
+	err := c.Next()
+	stopHeartbeat()
+
+	resp := c.Response()
+	status := resp.StatusCode()
+	if err != nil || status >= fiber.StatusInternalServerError || resp.IsBodyStream() || len(resp.Header.Peek(fiber.HeaderContentEncoding)) > 0 {
+		if err == nil && status < fiber.StatusInternalServerError {
+			l.Warn("Response isn't stored, the body is a stream or encoded", zap.String("path", c.Path()))
+		}
+		if e := cfg.Store.ReleaseIdempotencyKey(ctx, record.CallerID, record.Key); e != nil {
+			l.Error("ReleaseIdempotencyKey", zap.Error(e))
+		}
+		return err
+	}
+
+	record.ResponseStatus = sql.NullInt64{Int64: int64(status), Valid: true}
+	record.ContentType = sql.NullString{String: string(resp.Header.ContentType()), Valid: true}
+	record.ResponseBody = append([]byte(nil), resp.Body()...)
+	if err := cfg.Store.CompleteIdempotencyKey(ctx, record); err != nil {
+		// the response is sent anyway, the retry runs the request again instead of waiting for the lease
+		l.Error("CompleteIdempotencyKey", zap.Error(err))
+		if e := cfg.Store.ReleaseIdempotencyKey(ctx, record.CallerID, record.Key); e != nil {
+			l.Error("ReleaseIdempotencyKey", zap.Error(e))
+		}
+	}
+
+	return nil
+}
+
+// heartbeat extends the lease of the key every Lease/3 until the returned func is called,
+// which waits for the running extension
+func (cfg IdempotencyConfig) heartbeat(ctx context.Context, l *zap.Logger, record idempotencyModel.IdempotencyKey) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(cfg.Lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				lockedUntil := cfg.Now().UTC().Add(cfg.Lease)
+				if err := cfg.Store.ExtendIdempotencyKey(ctx, record.CallerID, record.Key, lockedUntil); err != nil {
+					l.Warn("ExtendIdempotencyKey", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// runningKeys lets the retries on this instance wake as soon as the first request with the key is done
+type runningKeys struct {
+	mu   sync.Mutex
+	done map[string]chan struct{}
+}
+
+// start registers the running request, the returned func wakes the waiting retries
+func (r *runningKeys) start(key string) func() {
+	r.mu.Lock()
+	ch := make(chan struct{})
+	r.done[key] = ch
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		if r.done[key] == ch {
+			delete(r.done, key)
+		}
+		r.mu.Unlock()
+		close(ch)
+	}
+}
+
+// wait sleeps until the request with the key is done on this instance or poll passes,
+// false when the deadline has passed already
+func (r *runningKeys) wait(key string, deadline time.Time, poll time.Duration) bool {
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return false
+	}
+	if poll > remaining {
+		poll = remaining
+	}
+
+	r.mu.Lock()
+	ch := r.done[key]
+	r.mu.Unlock()
+
+	timer := time.NewTimer(poll)
+	defer timer.Stop()
+
+	select {
+	case <-ch:
+	case <-timer.C:
+	}
+	return true
+}
+
+func mutatingMethod(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestHash - SHA-256 of the method, the path, the query, the company the caller acts for and the body,
+// the key is scoped to the caller already
+func requestHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write(c.Request().URI().QueryString())
+	h.Write([]byte{0})
+	h.Write(c.Request().Header.Peek(HeaderCompanyID))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// validIdempotencyKey - printable ASCII up to idempotencyKeyMaxLength
+func validIdempotencyKey(key string) bool {
+	if len(key) > idempotencyKeyMaxLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7E {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,340 @@
+package middles
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+	idempotencyModel "github.com/internet-banking-ul/internal/modules/idempotency/entities"
+	idempotencyRepo "github.com/internet-banking-ul/internal/modules/idempotency/repositories"
+	"github.com/internet-banking-ul/modules/health"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type idempotencyFixture struct {
+	app   *fiber.App
+	calls int32
+	// release unblocks the handler of the /slow route
+	release chan struct{}
+}
+
+// faultyStore drops the lease extensions like a crashed instance, or fails to store the responses
+type faultyStore struct {
+	idempotencyRepo.RepositoryIdempotency
+	noHeartbeat  bool
+	failComplete bool
+}
+
+func (s faultyStore) ExtendIdempotencyKey(ctx context.Context, callerID int64, key string, lockedUntil time.Time) error {
+	if s.noHeartbeat {
+		return nil
+	}
+	return s.RepositoryIdempotency.ExtendIdempotencyKey(ctx, callerID, key, lockedUntil)
+}
+
+func (s faultyStore) CompleteIdempotencyKey(ctx context.Context, key idempotencyModel.IdempotencyKey) error {
+	if s.failComplete {
+		return errors.New("ORA-03113")
+	}
+	return s.RepositoryIdempotency.CompleteIdempotencyKey(ctx, key)
+}
+
+func newIdempotencyFixture(t *testing.T, immutable bool, wait time.Duration, configure ...func(cfg *IdempotencyConfig)) *idempotencyFixture {
+	if logger.WorkLogger == nil {
+		logger.WorkLogger, logger.SqlLogger = zap.NewNop(), zap.NewNop()
+	}
+
+	f := &idempotencyFixture{release: make(chan struct{})}
+	f.app = fiber.New(fiber.Config{Immutable: immutable})
+	f.app.Use(etag.New(), compress.New(compress.Config{Level: compress.LevelBestSpeed}), SetupContextHolder())
+	cfg := IdempotencyConfig{
+		Store:        idempotencyRepo.NewMemoryRepository(100),
+		Wait:         wait,
+		PollInterval: 10 * time.Millisecond,
+		CallerID: func(c *fiber.Ctx) (int64, bool) {
+			id, err := strconv.ParseInt(c.Get("X-Caller"), 10, 64)
+			return id, err == nil
+		},
+	}
+	for _, fn := range configure {
+		fn(&cfg)
+	}
+	f.app.Use(Idempotency(cfg))
+
+	f.app.Post("/payments", func(c *fiber.Ctx) error {
+		n := atomic.AddInt32(&f.calls, 1)
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": n, "body": string(c.Body()), "padding": strings.Repeat("x", 512)})
+	})
+	f.app.Post("/slow", func(c *fiber.Ctx) error {
+		n := atomic.AddInt32(&f.calls, 1)
+		<-f.release
+		return c.JSON(fiber.Map{"id": n})
+	})
+	f.app.Post("/fail", func(c *fiber.Ctx) error {
+		atomic.AddInt32(&f.calls, 1)
+		return c.SendStatus(fiber.StatusServiceUnavailable)
+	})
+	f.app.Get("/payments", func(c *fiber.Ctx) error {
+		atomic.AddInt32(&f.calls, 1)
+		return c.SendString("list")
+	})
+
+	return f
+}
+
+func (f *idempotencyFixture) do(t *testing.T, method, path, key, body string, header ...string) *http.Response {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-Caller", "1")
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := f.app.Test(req, -1)
+	require.NoError(t, err)
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	var r io.Reader = resp.Body
+	if resp.Header.Get(fiber.HeaderContentEncoding) == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		require.NoError(t, err)
+		r = gz
+	}
+	body, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	for _, immutable := range []bool{true, false} {
+		f := newIdempotencyFixture(t, immutable, 0)
+
+		first := f.do(t, fiber.MethodPost, "/payments", "key-1", `{"amount":"10"}`)
+		assert.Equal(t, fiber.StatusCreated, first.StatusCode)
+		assert.Empty(t, first.Header.Get(HeaderIdempotentReplayed))
+		firstBody := readBody(t, first)
+
+		// the retry is compressed and tagged as the first response would be
+		retry := f.do(t, fiber.MethodPost, "/payments", "key-1", `{"amount":"10"}`, fiber.HeaderAcceptEncoding, "gzip")
+		assert.Equal(t, fiber.StatusCreated, retry.StatusCode)
+		assert.Equal(t, "true", retry.Header.Get(HeaderIdempotentReplayed))
+		assert.Equal(t, "gzip", retry.Header.Get(fiber.HeaderContentEncoding))
+		assert.Equal(t, fiber.MIMEApplicationJSON, retry.Header.Get(fiber.HeaderContentType))
+		assert.Equal(t, firstBody, readBody(t, retry))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&f.calls), "immutable %v", immutable)
+
+		resp := f.do(t, fiber.MethodPost, "/payments", "key-1", `{"amount":"20"}`)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp = f.do(t, fiber.MethodPost, "/payments?draft=1", "key-1", `{"amount":"10"}`)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+
+		// the same request for another company
+		resp = f.do(t, fiber.MethodPost, "/payments", "key-1", `{"amount":"10"}`, HeaderCompanyID, "20")
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+
+		// the key of another caller
+		resp = f.do(t, fiber.MethodPost, "/payments", "key-1", `{"amount":"10"}`, "X-Caller", "2")
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(HeaderIdempotentReplayed))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&f.calls))
+	}
+}
+
+func TestIdempotency_Skipped(t *testing.T) {
+	f := newIdempotencyFixture(t, true, 0)
+
+	for i := 0; i < 2; i++ {
+		resp := f.do(t, fiber.MethodGet, "/payments", "key-1", "")
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		resp = f.do(t, fiber.MethodPost, "/payments", "", `{}`)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&f.calls))
+
+	resp := f.do(t, fiber.MethodPost, "/payments", "key with spaces", `{}`)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = f.do(t, fiber.MethodPost, "/payments", strings.Repeat("k", idempotencyKeyMaxLength+1), `{}`)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestIdempotency_ReleaseOnFailure(t *testing.T) {
+	f := newIdempotencyFixture(t, true, 0)
+
+	for i := 0; i < 2; i++ {
+		resp := f.do(t, fiber.MethodPost, "/fail", "key-1", `{}`)
+		assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(HeaderIdempotentReplayed))
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&f.calls))
+}
+
+func TestIdempotency_ReleaseOnCompleteFailure(t *testing.T) {
+	f := newIdempotencyFixture(t, true, 0, func(cfg *IdempotencyConfig) {
+		cfg.Store = faultyStore{RepositoryIdempotency: cfg.Store, failComplete: true}
+	})
+
+	for i := 0; i < 2; i++ {
+		resp := f.do(t, fiber.MethodPost, "/payments", "key-1", `{}`)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(HeaderIdempotentReplayed))
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&f.calls), "the retry isn't blocked by the reservation")
+}
+
+func TestIdempotency_Lease(t *testing.T) {
+	const lease = 30 * time.Millisecond
+
+	t.Run("extended while running", func(t *testing.T) {
+		f := newIdempotencyFixture(t, true, 0, func(cfg *IdempotencyConfig) { cfg.Lease = lease })
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			f.do(t, fiber.MethodPost, "/slow", "key-1", `{}`)
+		}()
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&f.calls) == 1 }, time.Second, 5*time.Millisecond)
+
+		time.Sleep(3 * lease)
+		resp := f.do(t, fiber.MethodPost, "/slow", "key-1", `{}`)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+		close(f.release)
+		<-done
+		assert.Equal(t, int32(1), atomic.LoadInt32(&f.calls))
+	})
+
+	t.Run("stale taken over", func(t *testing.T) {
+		// the instance of the first request stops extending the lease, the retry comes to another one
+		store := idempotencyRepo.NewMemoryRepository(100)
+		crashed := newIdempotencyFixture(t, true, 0, func(cfg *IdempotencyConfig) {
+			cfg.Store, cfg.Lease = faultyStore{RepositoryIdempotency: store, noHeartbeat: true}, lease
+		})
+		other := newIdempotencyFixture(t, true, 0, func(cfg *IdempotencyConfig) {
+			cfg.Store, cfg.Lease = store, lease
+		})
+		close(other.release)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			crashed.do(t, fiber.MethodPost, "/slow", "key-1", `{}`)
+		}()
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&crashed.calls) == 1 }, time.Second, 5*time.Millisecond)
+
+		resp := other.do(t, fiber.MethodPost, "/slow", "key-1", `{}`)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode, "the lease is valid")
+
+		time.Sleep(2 * lease)
+		resp = other.do(t, fiber.MethodPost, "/slow", "key-1", `{}`)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&other.calls))
+
+		resp = other.do(t, fiber.MethodPost, "/slow", "key-1", `{}`)
+		assert.Equal(t, "true", resp.Header.Get(HeaderIdempotentReplayed))
+
+		close(crashed.release)
+		<-done
+	})
+}
+
+func TestIdempotency_ConcurrentRetry(t *testing.T) {
+	t.Run("waits", func(t *testing.T) {
+		f := newIdempotencyFixture(t, true, 5*time.Second)
+
+		var wg sync.WaitGroup
+		bodies := make([]string, 3)
+		for i := range bodies {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				resp := f.do(t, fiber.MethodPost, "/slow", "key-1", `{}`)
+				assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+				bodies[i] = readBody(t, resp)
+			}(i)
+		}
+
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&f.calls) == 1 }, time.Second, 5*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		close(f.release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&f.calls))
+		for _, body := range bodies {
+			assert.JSONEq(t, `{"id":1}`, body)
+		}
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		f := newIdempotencyFixture(t, true, 30*time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			f.do(t, fiber.MethodPost, "/slow", "key-1", `{}`)
+		}()
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&f.calls) == 1 }, time.Second, 5*time.Millisecond)
+
+		resp := f.do(t, fiber.MethodPost, "/slow", "key-1", `{}`)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+		close(f.release)
+		<-done
+	})
+}
+
+// failingExpiryStore fails every deletion of the expired keys
+type failingExpiryStore struct {
+	idempotencyRepo.RepositoryIdempotency
+}
+
+func (s failingExpiryStore) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	return 0, errors.New("ORA-03113")
+}
+
+func TestIdempotencyConfig_RunExpiry(t *testing.T) {
+	if logger.WorkLogger == nil {
+		logger.WorkLogger, logger.SqlLogger = zap.NewNop(), zap.NewNop()
+	}
+
+	for _, failing := range []bool{false, true} {
+		var store idempotencyRepo.RepositoryIdempotency = idempotencyRepo.NewMemoryRepository(10)
+		if failing {
+			store = failingExpiryStore{store}
+		}
+		// the job is overdue from the start
+		heartbeat := health.NewHeartbeat("idempotency_expiry", time.Minute)
+		heartbeat.Now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		cfg := IdempotencyConfig{Store: store, TTL: time.Hour, Expiry: heartbeat}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go cfg.RunExpiry(ctx, 5*time.Millisecond)
+
+		if failing {
+			time.Sleep(50 * time.Millisecond)
+			assert.Error(t, heartbeat.Check(ctx), "a failed run doesn't beat")
+		} else {
+			assert.Eventually(t, func() bool { return heartbeat.Check(ctx) == nil }, time.Second, 5*time.Millisecond)
+		}
+		cancel()
+	}
+}
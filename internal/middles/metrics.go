@@ -0,0 +1,46 @@
+package middles
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/modules/metrics"
+)
+
+// Metrics records the duration of the requests by method, route template and status, and the requests in flight.
+// The route template (/api/v1/payments/:id) keeps the IDs out of the labels: a request stopped by a middleware
+// (401 of /api/v1) or matching no route is labelled with the path of the last middleware it passed.
+func Metrics(registry *metrics.Registry) fiber.Handler {
+	duration := registry.NewHistogramVec("http_request_duration_seconds",
+		"Duration of the HTTP requests by method, route template and status", nil, "method", "route", "status")
+	inFlight := registry.NewGaugeVec("http_requests_in_flight", "HTTP requests being served by method", "method")
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		method := c.Method()
+
+		gauge := inFlight.WithLabelValues(method)
+		gauge.Inc()
+		defer gauge.Dec()
+
+		err := c.Next()
+
+		duration.WithLabelValues(method, c.Route().Path, strconv.Itoa(responseStatus(c, err))).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// responseStatus is the status of the response to the error returned by the next handlers,
+// the error handler sets it after the middlewares
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,62 @@
+package middles
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/modules/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	app := fiber.New()
+	app.Use(Metrics(registry))
+
+	v1 := app.Group("/api/v1", func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+		return c.Next()
+	})
+	v1.Get("/payments/:id", func(c *fiber.Ctx) error {
+		return c.SendString(c.Params("id"))
+	})
+	v1.Post("/payments", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "invalid")
+	})
+
+	for _, req := range []struct {
+		method, path string
+		auth         bool
+		status       int
+	}{
+		{"GET", "/api/v1/payments/1", true, 200},
+		{"GET", "/api/v1/payments/2", true, 200},
+		{"POST", "/api/v1/payments", true, 422},
+		{"GET", "/api/v1/payments/3", false, 401},
+		{"GET", "/api/v1/nothing/4", true, 404},
+	} {
+		r := httptest.NewRequest(req.method, req.path, nil)
+		if req.auth {
+			r.Header.Set(fiber.HeaderAuthorization, "Bearer t")
+		}
+		resp, err := app.Test(r)
+		require.NoError(t, err)
+		assert.Equal(t, req.status, resp.StatusCode, req.path)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, metrics.Write(&buf, registry))
+	out := buf.String()
+
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/api/v1/payments/:id",status="200"} 2`)
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="POST",route="/api/v1/payments",status="422"} 1`)
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/api/v1",status="401"} 1`, "stopped by the middleware")
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/api/v1",status="404"} 1`, "no route matched")
+	assert.NotContains(t, out, "/payments/1")
+	assert.Contains(t, out, `http_requests_in_flight{method="GET"} 0`)
+}
@@ -0,0 +1,85 @@
+package middles
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/hashicorp/go-uuid"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+)
+
+// maxRequestIDLength bounds the X-Request-ID of the client, a longer one is replaced
+const maxRequestIDLength = 128
+
+// Tracing starts the server span of the request, continuing the trace of a valid traceparent header,
+// and keeps a valid X-Request-ID of the client or creates one. Both are returned in the response headers
+// and stored as the logger.Metadata of the context holder, so every line of logger.WorkLoggerWithContext
+// carries the request, trace and span IDs. The span is named by the route template once routed.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		remote, _ := tracing.ParseTraceparent(c.Get(tracing.TraceparentHeader))
+		span := tracing.StartServer(c.Method(), remote)
+		defer span.End()
+		c.Locals(tracing.SpanKey, span)
+		sc := span.SpanContext()
+
+		requestID := c.Get(fiber.HeaderXRequestID)
+		if validRequestID(requestID) {
+			// the header is backed by the request buffer, reused after the request
+			requestID = strings.Clone(requestID)
+		} else if requestID, _ = uuid.GenerateUUID(); requestID == "" {
+			requestID = sc.TraceID.String()
+		}
+
+		holder, ok := c.Locals(utils.ContextHolderKey).(*sync.Map)
+		if !ok {
+			holder = &sync.Map{}
+			c.Locals(utils.ContextHolderKey, holder)
+		}
+		holder.Store(utils.AttributeTracingMetadata, logger.Metadata{
+			ReqID:   requestID,
+			TraceID: sc.TraceID.String(),
+			SpanID:  sc.SpanID.String(),
+		})
+
+		c.Set(fiber.HeaderXRequestID, requestID)
+		c.Set(tracing.TraceparentHeader, sc.Traceparent())
+
+		err := c.Next()
+
+		// the client errors don't fail the span of the server
+		status := responseStatus(c, err)
+		method := strings.Clone(c.Method())
+		span.SetName(method + " " + c.Route().Path)
+		span.SetAttribute("http.method", method)
+		span.SetAttribute("http.route", c.Route().Path)
+		span.SetAttribute("http.status_code", status)
+		span.SetAttribute("http.request_id", requestID)
+		if status >= fiber.StatusInternalServerError {
+			failure := err
+			if failure == nil {
+				failure = errors.New(http.StatusText(status))
+			}
+			span.SetError(failure)
+		}
+		return err
+	}
+}
+
+// validRequestID accepts the printable ASCII without spaces, which is safe in the logs and the headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package middles
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *spanRecorder) Export(span tracing.SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	recorder := &spanRecorder{}
+	tracing.SetExporter(recorder, nil)
+	defer tracing.SetExporter(nil, nil)
+
+	var metadata interface{}
+	app := fiber.New()
+	app.Use(Tracing())
+	app.Get("/payments/:id", func(c *fiber.Ctx) error {
+		metadata, _ = c.Locals(utils.ContextHolderKey).(*sync.Map).Load(utils.AttributeTracingMetadata)
+		_, span := tracing.Start(c.Context(), "PaymentService.ByID")
+		span.End()
+		return c.SendString(c.Params("id"))
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return errors.New("ORA-03113")
+	})
+
+	r := httptest.NewRequest("GET", "/payments/1", nil)
+	r.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.Header.Set(fiber.HeaderXRequestID, "req-1")
+	resp, err := app.Test(r)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "req-1", resp.Header.Get(fiber.HeaderXRequestID))
+
+	sc, err := tracing.ParseTraceparent(resp.Header.Get(tracing.TraceparentHeader))
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String(), "the trace of the caller")
+	assert.Equal(t, logger.Metadata{ReqID: "req-1", TraceID: sc.TraceID.String(), SpanID: sc.SpanID.String()}, metadata)
+
+	require.Len(t, recorder.spans, 2)
+	service, server := recorder.spans[0], recorder.spans[1]
+	assert.Equal(t, "GET /payments/:id", server.Name)
+	assert.Equal(t, sc.SpanID, server.SpanContext.SpanID)
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.String())
+	assert.Contains(t, server.Attributes, tracing.Attribute{Key: "http.status_code", Value: 200})
+	assert.Empty(t, server.Error)
+	assert.Equal(t, sc.SpanID, service.Parent)
+
+	recorder.spans = nil
+	r = httptest.NewRequest("GET", "/fail", nil)
+	r.Header.Set(tracing.TraceparentHeader, "garbage")
+	r.Header.Set(fiber.HeaderXRequestID, strings.Repeat("x", maxRequestIDLength+1))
+	resp, err = app.Test(r)
+	require.NoError(t, err)
+	assert.Equal(t, 500, resp.StatusCode)
+	assert.Len(t, resp.Header.Get(fiber.HeaderXRequestID), 36, "a new UUID")
+
+	require.Len(t, recorder.spans, 1)
+	assert.False(t, recorder.spans[0].Parent.IsValid(), "a new trace")
+	assert.Equal(t, "ORA-03113", recorder.spans[0].Error)
+}
+
+func TestValidRequestID(t *testing.T) {
+	assert.True(t, validRequestID("4bf92f35-77b3-4da6-a3ce-929d0e0e4736"))
+	assert.False(t, validRequestID(""))
+	assert.False(t, validRequestID("a b"))
+	assert.False(t, validRequestID("a\nlevel=error"))
+	assert.False(t, validRequestID("идентификатор"))
+}
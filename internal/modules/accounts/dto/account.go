@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/internet-banking-ul/internal/modules/accounts/entities"
+	"github.com/internet-banking-ul/tools/money"
+)
+
+// AccountResponse - the balances are integer minor units, Scale is the number of minor units digits of the currency
+type AccountResponse struct {
+	ID               int64      `json:"id"`
+	CustomerID       int64      `json:"customerID"`
+	IBAN             string     `json:"iban"`
+	Currency         string     `json:"currency"`
+	Scale            int        `json:"scale"`
+	Status           string     `json:"status"`
+	Name             string     `json:"name"`
+	AvailableBalance int64      `json:"availableBalance"`
+	LedgerBalance    int64      `json:"ledgerBalance"`
+	OpenedAt         time.Time  `json:"openedAt"`
+	ClosedAt         *time.Time `json:"closedAt"`
+}
+
+func CreateAccountResponse(account entities.Account) AccountResponse {
+	resp := AccountResponse{
+		ID:               account.ID,
+		CustomerID:       account.CustomerID,
+		IBAN:             account.IBAN,
+		Currency:         account.Currency,
+		Status:           account.Status,
+		Name:             account.Name,
+		AvailableBalance: account.AvailableBalance,
+		LedgerBalance:    account.LedgerBalance,
+		OpenedAt:         account.OpenedAt,
+	}
+
+	if currency, ok := money.CurrencyByCode(account.Currency); ok {
+		resp.Scale = currency.Scale
+	}
+
+	if account.ClosedAt.Valid {
+		closedAt := account.ClosedAt.Time
+		resp.ClosedAt = &closedAt
+	}
+
+	return resp
+}
+
+type AccountListResponse []*AccountResponse
+
+func CreateAccountListResponse(accountList entities.AccountList) AccountListResponse {
+	accountListResp := AccountListResponse{}
+	for _, a := range accountList {
+		account := CreateAccountResponse(*a)
+		accountListResp = append(accountListResp, &account)
+	}
+	return accountListResp
+}
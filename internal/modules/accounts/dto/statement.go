@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/internet-banking-ul/internal/modules/accounts/entities"
+	"github.com/internet-banking-ul/tools/money"
+)
+
+// StatementDateLayout - the layout of StatementRequest dates and of the statement rows
+const StatementDateLayout = "2006-01-02"
+
+// StatementRequest - Format is csv, xlsx or pdf; the period is DateFrom and DateTo (both or none)
+// or Period (all, month, week, today, a year otherwise) ending today
+type StatementRequest struct {
+	Format   string
+	Period   string
+	DateFrom string
+	DateTo   string
+}
+
+// Statement is the checked statement of an account of the current customer, the whole days From..To
+type Statement struct {
+	Account  entities.Account
+	Currency money.Currency
+	From     time.Time
+	To       time.Time
+	Format   string
+}
+
+// FileName - tools.SendExportedFile adds the date and the extension
+func (s Statement) FileName() string {
+	return "statement_" + s.Account.IBAN
+}
@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/internet-banking-ul/tools/money"
+)
+
+const (
+	StatusActive  = "ACTIVE"
+	StatusBlocked = "BLOCKED"
+	StatusClosed  = "CLOSED"
+)
+
+// Statuses - allowed values of Account.Status
+var Statuses = []string{StatusActive, StatusBlocked, StatusClosed}
+
+// Account of a customer. The balances are integer minor units of Currency (tiyn for KZT, cents for USD),
+// the ledger balance is the booked one, the available one is less the holds.
+type Account struct {
+	ID               int64        `db:"ID" json:"id"`
+	CustomerID       int64        `db:"CUSTOMER_ID" json:"customer_id"`
+	IBAN             string       `db:"IBAN" json:"iban"`
+	Currency         string       `db:"CURRENCY" json:"currency"`
+	Status           string       `db:"STATUS" json:"status"`
+	Name             string       `db:"NAME" json:"name"`
+	AvailableBalance int64        `db:"AVAILABLE_BALANCE" json:"available_balance"`
+	LedgerBalance    int64        `db:"LEDGER_BALANCE" json:"ledger_balance"`
+	OpenedAt         time.Time    `db:"OPENED_AT" json:"opened_at"`
+	ClosedAt         sql.NullTime `db:"CLOSED_AT" json:"closed_at"`
+}
+
+// Available returns AvailableBalance as money.Amount, ok is false for a currency money doesn't support
+func (a Account) Available() (money.Amount, bool) {
+	return a.amount(a.AvailableBalance)
+}
+
+// Ledger returns LedgerBalance as money.Amount, ok is false for a currency money doesn't support
+func (a Account) Ledger() (money.Amount, bool) {
+	return a.amount(a.LedgerBalance)
+}
+
+func (a Account) amount(minor int64) (money.Amount, bool) {
+	currency, ok := money.CurrencyByCode(a.Currency)
+	if !ok {
+		return money.Amount{}, false
+	}
+	return money.New(minor, currency), true
+}
+
+type AccountList []*Account
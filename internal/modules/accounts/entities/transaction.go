@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// Transaction is a booked entry of an account. Amount is signed minor units: a credit is positive,
+// a debit negative; BalanceAfter is the ledger balance after the entry.
+type Transaction struct {
+	ID               int64     `db:"ID" json:"id"`
+	AccountID        int64     `db:"ACCOUNT_ID" json:"account_id"`
+	BookedAt         time.Time `db:"BOOKED_AT" json:"booked_at"`
+	DocumentNumber   string    `db:"DOCUMENT_NUMBER" json:"document_number"`
+	Amount           int64     `db:"AMOUNT" json:"amount"`
+	BalanceAfter     int64     `db:"BALANCE_AFTER" json:"balance_after"`
+	CounterpartyName string    `db:"COUNTERPARTY_NAME" json:"counterparty_name"`
+	CounterpartyIBAN string    `db:"COUNTERPARTY_IBAN" json:"counterparty_iban"`
+	Description      string    `db:"DESCRIPTION" json:"description"`
+}
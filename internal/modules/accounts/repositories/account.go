@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+
+	accountModel "github.com/internet-banking-ul/internal/modules/accounts/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/modules/repository"
+	"github.com/internet-banking-ul/internal/storage"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+)
+
+type RepositoryAccountQuery interface {
+	ByID(ctx context.Context, id int64) (result accountModel.Account, err error)
+	LockByID(ctx context.Context, id int64) error
+	ByIBAN(ctx context.Context, iban string) (result accountModel.Account, err error)
+	List(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (accountModel.AccountList, int64, string, error)
+	Count(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (count int64, err error)
+}
+
+// accountListColumns - the sort, search and filter whitelist of List
+var accountListColumns = entities.ListColumns{
+	Sortable: map[string]string{
+		"id":               "ID",
+		"iban":             "IBAN",
+		"currency":         "CURRENCY",
+		"status":           "STATUS",
+		"name":             "NAME",
+		"availableBalance": "AVAILABLE_BALANCE",
+		"ledgerBalance":    "LEDGER_BALANCE",
+		"openedAt":         "OPENED_AT",
+	},
+	DefaultSort: "id",
+	Key:         "ID",
+	Searchable:  []string{"IBAN", "NAME"},
+	Filterable: map[string]entities.FilterField{
+		"iban":             {Column: "IBAN", Ops: []string{entities.FilterEq, entities.FilterIn, entities.FilterPrefix}},
+		"currency":         {Column: "CURRENCY", Ops: []string{entities.FilterEq, entities.FilterNe, entities.FilterIn}},
+		"status":           {Column: "STATUS", Ops: []string{entities.FilterEq, entities.FilterNe, entities.FilterIn}},
+		"availableBalance": {Column: "AVAILABLE_BALANCE", Type: entities.FilterInt},
+		"openedAt":         {Column: "OPENED_AT", Type: entities.FilterTime},
+	},
+}
+
+// RepositoryAccountQueryImpl - the accounts of the customers
+type RepositoryAccountQueryImpl struct {
+	*repository.Repository[accountModel.Account]
+}
+
+func newAccountQuery(db *storage.DB) *RepositoryAccountQueryImpl {
+	return &RepositoryAccountQueryImpl{
+		Repository: repository.New[accountModel.Account](db, "ACCOUNT", accountListColumns, nil),
+	}
+}
+
+// ByID returns sql.ErrNoRows when the account doesn't exist
+func (repo *RepositoryAccountQueryImpl) ByID(ctx context.Context, id int64) (result accountModel.Account, err error) {
+	return repo.GetByID(ctx, id)
+}
+
+// ByIBAN returns sql.ErrNoRows when there is no account with the normalized IBAN
+func (repo *RepositoryAccountQueryImpl) ByIBAN(ctx context.Context, iban string) (result accountModel.Account, err error) {
+	return repo.GetBy(ctx, sq.Eq{"IBAN": iban})
+}
+
+// List returns the page of the customer accounts selected by baseFilter, the total of the filtered accounts
+// and the cursor of the next page ("" on the last page)
+func (repo *RepositoryAccountQueryImpl) List(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (accountModel.AccountList, int64, string, error) {
+	return repo.Repository.List(ctx, baseFilter, sq.Eq{"CUSTOMER_ID": customerID})
+}
+
+// Count returns the number of customer accounts matching the search and the filters of baseFilter
+func (repo *RepositoryAccountQueryImpl) Count(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (count int64, err error) {
+	return repo.Repository.Count(ctx, baseFilter, sq.Eq{"CUSTOMER_ID": customerID})
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+)
+
+// RepositoryAccountCommand - writes, every method runs in the transaction of ctx started by the service (storage.DB.Runner)
+type RepositoryAccountCommand interface {
+	AddBalances(ctx context.Context, id int64, available, ledger int64) error
+}
+
+type RepositoryAccountCommandImpl struct {
+	DB *storage.DB
+}
+
+// AddBalances adds the minor units to the balances of the account, negative ones are taken off:
+// a payment sent holds its amount (available only), its execution books it (ledger only) and its
+// rejection releases the hold. Returns sql.ErrNoRows when the account doesn't exist.
+// The caller locks the account row (LockByID) before checking the balances it changes.
+func (repo *RepositoryAccountCommandImpl) AddBalances(ctx context.Context, id int64, available, ledger int64) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("AddBalances")
+
+	q := repo.DB.Builder().
+		Update("ACCOUNT").
+		Set("AVAILABLE_BALANCE", sq.Expr("AVAILABLE_BALANCE + ?", available)).
+		Set("LEDGER_BALANCE", sq.Expr("LEDGER_BALANCE + ?", ledger)).
+		Where(sq.Eq{"ID": id})
+
+	return storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx)))
+}
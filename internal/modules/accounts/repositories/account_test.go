@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	accountModel "github.com/internet-banking-ul/internal/modules/accounts/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryAccountQueryImpl(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+	date := func(month time.Month) time.Time { return time.Date(2022, month, 10, 0, 0, 0, 0, time.UTC) }
+
+	for _, id := range []int{10, 20} {
+		storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+			VALUES (?, 'LEGAL', ?, 'Company', 'Company LLP', 'TOO', '17', '050140000120')`, id, id)
+	}
+	storagetest.Exec(t, db, `INSERT INTO ACCOUNT (ID, CUSTOMER_ID, IBAN, CURRENCY, STATUS, NAME, AVAILABLE_BALANCE, LEDGER_BALANCE, OPENED_AT) VALUES
+		(1, 10, 'KZ86125KZT5004100100', 'KZT', 'ACTIVE', 'Current', 1050075, 1100075, ?),
+		(2, 10, 'KZ71125USD5004100101', 'USD', 'ACTIVE', 'Currency', 250, 250, ?),
+		(3, 20, 'KZ90601A861000000123', 'KZT', 'BLOCKED', 'Other', 0, 0, ?)`,
+		date(1), date(2), date(3))
+
+	account, err := repo.ByIBAN(ctx, "KZ86125KZT5004100100")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), account.ID)
+	assert.Equal(t, int64(1050075), account.AvailableBalance)
+	assert.Equal(t, int64(1100075), account.LedgerBalance)
+	assert.False(t, account.ClosedAt.Valid)
+
+	_, err = repo.ByID(ctx, 99)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	filter := entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 1, Sort: "openedAt", Order: "desc"}}
+	list, count, nextCursor, err := repo.List(ctx, 10, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(2), list[0].ID)
+	require.NotEmpty(t, nextCursor)
+
+	filter.Cursor = nextCursor
+	list, _, nextCursor, err = repo.List(ctx, 10, filter)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(1), list[0].ID)
+	assert.Empty(t, nextCursor)
+
+	filter = entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}}
+	filter.AddFilter("currency", entities.FilterEq, "USD")
+	list, count, _, err = repo.List(ctx, 10, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, "USD", list[0].Currency)
+
+	filter = entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}}
+	filter.AddFilter("status", entities.FilterEq, accountModel.StatusActive)
+	list, count, _, err = repo.List(ctx, 20, filter)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Empty(t, list)
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"github.com/internet-banking-ul/internal/storage"
+)
+
+// Repositories - the accounts are opened and booked by the core banking system, the API only reads them
+// and holds the amounts of the payments it sends (RepositoryAccountCommand)
+type Repositories interface {
+	RepositoryAccountQuery
+	RepositoryAccountCommand
+	RepositoryTransactionQuery
+}
+
+type RepositoriesImpl struct {
+	// inject db impl to RepositoriesImpl event the db is being used by the child struct impl
+	db *storage.DB
+	*RepositoryAccountQueryImpl
+	*RepositoryAccountCommandImpl
+	*RepositoryTransactionQueryImpl
+}
+
+func NewAccountRepository(
+	db *storage.DB,
+) *RepositoriesImpl {
+	return &RepositoriesImpl{
+		db:                         db,
+		RepositoryAccountQueryImpl: newAccountQuery(db),
+		RepositoryAccountCommandImpl: &RepositoryAccountCommandImpl{
+			DB: db,
+		},
+		RepositoryTransactionQueryImpl: &RepositoryTransactionQueryImpl{
+			DB: db,
+		},
+	}
+}
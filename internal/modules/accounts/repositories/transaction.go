@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	accountModel "github.com/internet-banking-ul/internal/modules/accounts/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+type RepositoryTransactionQuery interface {
+	EachTransaction(ctx context.Context, accountID int64, from, to time.Time, fn func(*accountModel.Transaction) error) error
+	BalanceBefore(ctx context.Context, accountID int64, at time.Time) (int64, error)
+}
+
+var transactionColumns = []string{
+	"ID",
+	"ACCOUNT_ID",
+	"BOOKED_AT",
+	"DOCUMENT_NUMBER",
+	"AMOUNT",
+	"BALANCE_AFTER",
+	"COUNTERPARTY_NAME",
+	"COUNTERPARTY_IBAN",
+	"DESCRIPTION",
+}
+
+type RepositoryTransactionQueryImpl struct {
+	DB *storage.DB
+}
+
+// EachTransaction calls fn for every transaction of the account booked from..to inclusive in the booking order.
+// The rows are read one by one and the row passed to fn is reused, so a statement of any length is streamed;
+// an error of fn stops the query and is returned.
+func (repo *RepositoryTransactionQueryImpl) EachTransaction(ctx context.Context, accountID int64, from, to time.Time, fn func(*accountModel.Transaction) error) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("EachTransaction")
+
+	q := repo.DB.Builder().
+		Select(transactionColumns...).
+		From("ACCOUNT_TRANSACTION").
+		Where(sq.And{
+			sq.Eq{"ACCOUNT_ID": accountID},
+			sq.GtOrEq{"BOOKED_AT": from},
+			sq.LtOrEq{"BOOKED_AT": to},
+		}).
+		OrderBy("BOOKED_AT", "ID")
+
+	sql, args, e := q.ToSql()
+	if e != nil {
+		l.Error("ToSql", zap.Error(e))
+		return e
+	}
+
+	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := q.RunWith(repo.DB.Runner(ctx)).QueryContext(ctx)
+	if err != nil {
+		l.Error("QueryContext", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	row := new(accountModel.Transaction)
+	for rows.Next() {
+		if err := rows.Scan(
+			&row.ID,
+			&row.AccountID,
+			&row.BookedAt,
+			&row.DocumentNumber,
+			&row.Amount,
+			&row.BalanceAfter,
+			&row.CounterpartyName,
+			&row.CounterpartyIBAN,
+			&row.Description,
+		); err != nil {
+			l.Error("Scan", zap.Error(err))
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	return rows.Err()
+}
+
+// BalanceBefore returns the ledger balance of the account before the time, 0 when nothing was booked before
+func (repo *RepositoryTransactionQueryImpl) BalanceBefore(ctx context.Context, accountID int64, at time.Time) (balance int64, err error) {
+	if repo.DB == nil {
+		return balance, fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("BalanceBefore")
+
+	q := repo.DB.Builder().
+		Select("BALANCE_AFTER").
+		From("ACCOUNT_TRANSACTION").
+		Where(sq.And{
+			sq.Eq{"ACCOUNT_ID": accountID},
+			sq.Lt{"BOOKED_AT": at},
+		}).
+		OrderBy("BOOKED_AT DESC", "ID DESC").
+		Limit(1)
+
+	query, args, e := q.ToSql()
+	if e != nil {
+		l.Error("ToSql", zap.Error(e))
+		return balance, e
+	}
+
+	l.Debug("Info", zap.String("sql", query), zap.Any("args", args))
+
+	err = q.RunWith(repo.DB.Runner(ctx)).QueryRowContext(ctx).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return balance, err
+}
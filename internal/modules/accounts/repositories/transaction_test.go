@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	accountModel "github.com/internet-banking-ul/internal/modules/accounts/entities"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryTransactionQueryImpl(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+	at := func(day int) time.Time { return time.Date(2022, 1, day, 12, 0, 0, 0, time.UTC) }
+
+	storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+		VALUES (10, 'LEGAL', 'c-10', 'Company', 'Company LLP', 'TOO', '17', '050140000120')`)
+	storagetest.Exec(t, db, `INSERT INTO ACCOUNT (ID, CUSTOMER_ID, IBAN, CURRENCY, STATUS, NAME, OPENED_AT)
+		VALUES (1, 10, 'KZ86125KZT5004100100', 'KZT', 'ACTIVE', 'Current', ?)`, at(1))
+	for id, day := range []int{3, 2, 5, 5} {
+		storagetest.Exec(t, db, `INSERT INTO ACCOUNT_TRANSACTION (ID, ACCOUNT_ID, BOOKED_AT, DOCUMENT_NUMBER, AMOUNT, BALANCE_AFTER, COUNTERPARTY_NAME, COUNTERPARTY_IBAN, DESCRIPTION)
+			VALUES (?, 1, ?, '', 100, ?, '', '', '')`, id+1, at(day), 100*(id+1))
+	}
+
+	balance, err := repo.BalanceBefore(ctx, 1, at(1))
+	require.NoError(t, err)
+	assert.Zero(t, balance)
+
+	balance, err = repo.BalanceBefore(ctx, 1, at(4))
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), balance, "the latest booked, not the latest inserted")
+
+	var ids []int64
+	require.NoError(t, repo.EachTransaction(ctx, 1, at(2), at(5), func(tx *accountModel.Transaction) error {
+		ids = append(ids, tx.ID)
+		return nil
+	}))
+	assert.Equal(t, []int64{2, 1, 3, 4}, ids)
+
+	stop := errors.New("stop")
+	ids = nil
+	err = repo.EachTransaction(ctx, 1, at(2), at(5), func(tx *accountModel.Transaction) error {
+		ids = append(ids, tx.ID)
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, []int64{2}, ids)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/modules/accounts/dto"
+	accountModel "github.com/internet-banking-ul/internal/modules/accounts/entities"
+	accountRepo "github.com/internet-banking-ul/internal/modules/accounts/repositories"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+	"github.com/internet-banking-ul/tools"
+	"go.uber.org/zap"
+)
+
+type AccountService interface {
+	List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.AccountListResponse, int64, string, error)
+	ByID(ctx context.Context, id int64) (*dto.AccountResponse, error)
+	ByIBAN(ctx context.Context, iban string) (*dto.AccountResponse, error)
+	Statement(ctx context.Context, id int64, req dto.StatementRequest) (*dto.Statement, error)
+	WriteStatement(ctx context.Context, statement *dto.Statement, w io.Writer) error
+}
+
+// AccountServiceImpl - every method works with the accounts of the authenticated customer,
+// the current company set by middles.Require. An account of another customer is not found.
+type AccountServiceImpl struct {
+	AccountRepository accountRepo.Repositories
+}
+
+func NewAccountService(
+	db *storage.DB,
+) *AccountServiceImpl {
+	return &AccountServiceImpl{
+		AccountRepository: accountRepo.NewAccountRepository(db),
+	}
+}
+
+func (s AccountServiceImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.AccountListResponse, int64, string, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.List")
+	defer span.End()
+
+	customerID, err := currentCustomerID(ctx)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	accountList, count, nextCursor, err := s.AccountRepository.List(ctx, customerID, baseFilter)
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch AccountList from DB", zap.Error(err))
+		return nil, 0, "", err
+	}
+
+	return dto.CreateAccountListResponse(accountList), count, nextCursor, nil
+}
+
+// ByID returns apiErrors.AccountNotFound when the customer has no such account
+func (s AccountServiceImpl) ByID(ctx context.Context, id int64) (*dto.AccountResponse, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.ByID")
+	defer span.End()
+
+	return s.one(ctx, func() (accountModel.Account, error) {
+		return s.AccountRepository.ByID(ctx, id)
+	})
+}
+
+// ByIBAN accepts the printed form with spaces, apiErrors.AccountIbanInvalid is returned for a malformed IBAN
+func (s AccountServiceImpl) ByIBAN(ctx context.Context, iban string) (*dto.AccountResponse, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.ByIBAN")
+	defer span.End()
+
+	iban = tools.NormalizeIBAN(iban)
+	if !tools.ValidateKZIBAN(iban) {
+		return nil, apiErrors.ThrowError(apiErrors.AccountIbanInvalid)
+	}
+
+	return s.one(ctx, func() (accountModel.Account, error) {
+		return s.AccountRepository.ByIBAN(ctx, iban)
+	})
+}
+
+func (s AccountServiceImpl) one(ctx context.Context, fetch func() (accountModel.Account, error)) (*dto.AccountResponse, error) {
+	account, err := owned(ctx, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.CreateAccountResponse(account)
+	return &resp, nil
+}
+
+// owned returns the fetched account of the current customer, apiErrors.AccountNotFound for the others
+func owned(ctx context.Context, fetch func() (accountModel.Account, error)) (accountModel.Account, error) {
+	customerID, err := currentCustomerID(ctx)
+	if err != nil {
+		return accountModel.Account{}, err
+	}
+
+	account, err := fetch()
+	if errors.Is(err, sql.ErrNoRows) || err == nil && account.CustomerID != customerID {
+		return accountModel.Account{}, apiErrors.ThrowError(apiErrors.AccountNotFound)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch Account from DB", zap.Error(err))
+		return accountModel.Account{}, err
+	}
+
+	return account, nil
+}
+
+func currentCustomerID(ctx context.Context) (int64, error) {
+	customerID, ok := utils.ContextGetCurrentCompanyID(ctx)
+	if !ok {
+		return 0, apiErrors.ThrowError(apiErrors.AccessDenied)
+	}
+	return customerID, nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireAPIError(t *testing.T, err error, id string) {
+	t.Helper()
+
+	apiErr := apiErrors.ParseError(err)
+	require.NotNil(t, apiErr, "%v", err)
+	assert.Equal(t, apiErrors.ThrowError(id).Error(), apiErr.Error())
+}
+
+func TestAccountServiceImpl_ScopedByCustomer(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	for _, id := range []int{10, 20} {
+		storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+			VALUES (?, 'LEGAL', ?, 'Company', 'Company LLP', 'TOO', '17', '050140000120')`, id, id)
+	}
+	storagetest.Exec(t, db, `INSERT INTO ACCOUNT (ID, CUSTOMER_ID, IBAN, CURRENCY, STATUS, NAME, AVAILABLE_BALANCE, LEDGER_BALANCE, OPENED_AT) VALUES
+		(1, 10, 'KZ86125KZT5004100100', 'KZT', 'ACTIVE', 'Current', 1050075, 1100075, ?),
+		(2, 20, 'KZ90601A861000000123', 'KZT', 'ACTIVE', 'Other', 0, 0, ?)`,
+		time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC), time.Date(2022, 3, 10, 0, 0, 0, 0, time.UTC))
+
+	s := NewAccountService(db)
+	ctx := context.WithValue(context.Background(), utils.ContextHolderKey, &sync.Map{})
+
+	// no current customer
+	_, err := s.ByID(ctx, 1)
+	requireAPIError(t, err, apiErrors.AccessDenied)
+
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyID, int64(10))
+
+	account, err := s.ByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1050075), account.AvailableBalance)
+	assert.Equal(t, 2, account.Scale)
+
+	_, err = s.ByID(ctx, 2)
+	requireAPIError(t, err, apiErrors.AccountNotFound)
+
+	account, err = s.ByIBAN(ctx, "kz86 125K ZT50 0410 0100")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), account.ID)
+
+	_, err = s.ByIBAN(ctx, "KZ90601A861000000123")
+	requireAPIError(t, err, apiErrors.AccountNotFound)
+
+	_, err = s.ByIBAN(ctx, "KZ87125KZT5004100100")
+	requireAPIError(t, err, apiErrors.AccountIbanInvalid)
+
+	list, count, _, err := s.List(ctx, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, "KZ86125KZT5004100100", list[0].IBAN)
+}
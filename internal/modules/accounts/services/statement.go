@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/modules/accounts/dto"
+	accountModel "github.com/internet-banking-ul/internal/modules/accounts/entities"
+	"github.com/internet-banking-ul/modules/export"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+	"github.com/internet-banking-ul/tools"
+	"github.com/internet-banking-ul/tools/money"
+	"go.uber.org/zap"
+)
+
+var statementHeader = []string{"Date", "Document", "Counterparty", "Counterparty IBAN", "Description", "Debit", "Credit", "Balance"}
+
+// statementWidths - the relative PDF column widths of statementHeader
+var statementWidths = []float64{1.3, 1, 2.2, 1.8, 3, 1, 1, 1.1}
+
+// Statement checks the statement request of the account, nothing is read but the account.
+// The statement is written by WriteStatement.
+func (s AccountServiceImpl) Statement(ctx context.Context, id int64, req dto.StatementRequest) (*dto.Statement, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.Statement")
+	defer span.End()
+
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	if !tools.Contains(format, export.Formats) {
+		return nil, apiErrors.ThrowError(apiErrors.AccountStatementFormatInvalid)
+	}
+
+	from, to, err := statementPeriod(req)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := owned(ctx, func() (accountModel.Account, error) {
+		return s.AccountRepository.ByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	currency, ok := money.CurrencyByCode(account.Currency)
+	if !ok {
+		return nil, fmt.Errorf("account %d: %w %q", account.ID, money.ErrUnknownCurrency, account.Currency)
+	}
+
+	return &dto.Statement{Account: account, Currency: currency, From: from, To: to, Format: format}, nil
+}
+
+// statementPeriod returns the whole days of the request in the server time zone,
+// the Period ending today when the dates are missing
+func statementPeriod(req dto.StatementRequest) (from, to time.Time, err error) {
+	if (req.DateFrom == "") != (req.DateTo == "") {
+		return from, to, apiErrors.ThrowError(apiErrors.AccountStatementPeriodInvalid)
+	}
+
+	if req.DateFrom != "" {
+		if from, err = time.ParseInLocation(dto.StatementDateLayout, req.DateFrom, time.Local); err != nil {
+			return from, to, apiErrors.ThrowError(apiErrors.AccountStatementPeriodInvalid)
+		}
+		if to, err = time.ParseInLocation(dto.StatementDateLayout, req.DateTo, time.Local); err != nil {
+			return from, to, apiErrors.ThrowError(apiErrors.AccountStatementPeriodInvalid)
+		}
+		if from.After(to) {
+			return from, to, apiErrors.ThrowError(apiErrors.AccountStatementPeriodInvalid)
+		}
+	} else {
+		to = time.Now()
+		from = periodStart(req.Period, to)
+	}
+
+	return tools.StartOfDay(from), tools.EndOfDay(to), nil
+}
+
+// periodStart is the start of the period ending at to: all, month, week, today (day), a year otherwise
+func periodStart(period string, to time.Time) time.Time {
+	switch strings.ToLower(period) {
+	case "all":
+		return time.Time{}
+	case "month":
+		return to.AddDate(0, -1, 0)
+	case "week":
+		return to.AddDate(0, 0, -7)
+	case "today", "day":
+		return to
+	}
+	return to.AddDate(-1, 0, 0)
+}
+
+// WriteStatement streams the statement into w: the opening balance, the transactions of the period
+// as they are read from the DB, the turnover and the closing balance. Only the current row is in memory.
+func (s AccountServiceImpl) WriteStatement(ctx context.Context, statement *dto.Statement, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "AccountService.WriteStatement")
+	defer span.End()
+
+	l := logger.WorkLoggerWithContext(ctx).Named("WriteStatement")
+	account := statement.Account
+
+	opening, err := s.AccountRepository.BalanceBefore(ctx, account.ID, statement.From)
+	if err != nil {
+		l.Error("BalanceBefore", zap.Error(err))
+		return err
+	}
+
+	period := statement.From.Format(dto.StatementDateLayout) + " - " + statement.To.Format(dto.StatementDateLayout)
+	writer, err := export.NewWriter(statement.Format, w, export.Options{
+		Title:  "Statement " + account.IBAN,
+		Notes:  []string{account.Name, "Account: " + account.IBAN + " " + statement.Currency.Code, "Period: " + period},
+		Widths: statementWidths,
+	})
+	if err != nil {
+		return err
+	}
+
+	amount := func(minor int64) string {
+		return money.New(minor, statement.Currency).Decimal()
+	}
+
+	if err := writer.Write(statementHeader); err != nil {
+		return err
+	}
+	if err := writer.Write([]string{statement.From.Format(dto.StatementDateLayout), "", "", "", "Opening balance", "", "", amount(opening)}); err != nil {
+		return err
+	}
+
+	var debit, credit int64
+	err = s.AccountRepository.EachTransaction(ctx, account.ID, statement.From, statement.To, func(t *accountModel.Transaction) error {
+		row := []string{
+			t.BookedAt.In(time.Local).Format("2006-01-02 15:04"),
+			t.DocumentNumber,
+			t.CounterpartyName,
+			t.CounterpartyIBAN,
+			t.Description,
+			"",
+			"",
+			amount(t.BalanceAfter),
+		}
+		if t.Amount < 0 {
+			debit -= t.Amount
+			row[5] = amount(-t.Amount)
+		} else {
+			credit += t.Amount
+			row[6] = amount(t.Amount)
+		}
+		return writer.Write(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	date := statement.To.Format(dto.StatementDateLayout)
+	if err := writer.Write([]string{date, "", "", "", "Turnover", amount(debit), amount(credit), ""}); err != nil {
+		return err
+	}
+	if err := writer.Write([]string{date, "", "", "", "Closing balance", "", "", amount(opening + credit - debit)}); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
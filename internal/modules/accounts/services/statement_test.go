@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/modules/accounts/dto"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountServiceImpl_Statement(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+		VALUES (10, 'LEGAL', 'c-10', 'Company', 'Company LLP', 'TOO', '17', '050140000120')`)
+	storagetest.Exec(t, db, `INSERT INTO ACCOUNT (ID, CUSTOMER_ID, IBAN, CURRENCY, STATUS, NAME, AVAILABLE_BALANCE, LEDGER_BALANCE, OPENED_AT)
+		VALUES (1, 10, 'KZ86125KZT5004100100', 'KZT', 'ACTIVE', 'Current', 0, 0, ?)`, time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local))
+
+	at := func(day, hour int) time.Time { return time.Date(2022, 1, day, hour, 0, 0, 0, time.Local) }
+	for _, tx := range []struct {
+		id      int
+		at      time.Time
+		amount  int64
+		balance int64
+	}{
+		{1, at(5, 10), 100000, 100000}, // before the period
+		{2, at(10, 0), -2550, 97450},   // the first moment of the period
+		{3, at(12, 15), 50075, 147525},
+		{4, at(15, 23), -100, 147425},   // the last day of the period
+		{5, at(16, 0), 999999, 1147424}, // after the period
+	} {
+		storagetest.Exec(t, db, `INSERT INTO ACCOUNT_TRANSACTION (ID, ACCOUNT_ID, BOOKED_AT, DOCUMENT_NUMBER, AMOUNT, BALANCE_AFTER, COUNTERPARTY_NAME, COUNTERPARTY_IBAN, DESCRIPTION)
+			VALUES (?, 1, ?, ?, ?, ?, 'ТОО "Альфа"', 'KZ90601A861000000123', 'Payment')`, tx.id, tx.at, "00"+string(rune('0'+tx.id)), tx.amount, tx.balance)
+	}
+
+	s := NewAccountService(db)
+	ctx := context.WithValue(context.Background(), utils.ContextHolderKey, &sync.Map{})
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyID, int64(10))
+
+	for _, tc := range []struct {
+		req dto.StatementRequest
+		id  string
+	}{
+		{dto.StatementRequest{Format: "docx"}, apiErrors.AccountStatementFormatInvalid},
+		{dto.StatementRequest{Format: "csv", DateFrom: "2022-01-10"}, apiErrors.AccountStatementPeriodInvalid},
+		{dto.StatementRequest{Format: "csv", DateFrom: "2022-01-10", DateTo: "15.01.2022"}, apiErrors.AccountStatementPeriodInvalid},
+		{dto.StatementRequest{Format: "csv", DateFrom: "2022-01-15", DateTo: "2022-01-10"}, apiErrors.AccountStatementPeriodInvalid},
+	} {
+		_, err := s.Statement(ctx, 1, tc.req)
+		requireAPIError(t, err, tc.id)
+	}
+
+	_, err := s.Statement(ctx, 2, dto.StatementRequest{Format: "csv"})
+	requireAPIError(t, err, apiErrors.AccountNotFound)
+
+	statement, err := s.Statement(ctx, 1, dto.StatementRequest{Format: "CSV", DateFrom: "2022-01-10", DateTo: "2022-01-15"})
+	require.NoError(t, err)
+	assert.Equal(t, "csv", statement.Format)
+	assert.Equal(t, "statement_KZ86125KZT5004100100", statement.FileName())
+
+	var buf bytes.Buffer
+	require.NoError(t, s.WriteStatement(ctx, statement, &buf))
+	assert.Equal(t, "\xEF\xBB\xBF"+
+		"Date,Document,Counterparty,Counterparty IBAN,Description,Debit,Credit,Balance\n"+
+		"2022-01-10,,,,Opening balance,,,1000.00\n"+
+		"2022-01-10 00:00,002,\"ТОО \"\"Альфа\"\"\",KZ90601A861000000123,Payment,25.50,,974.50\n"+
+		"2022-01-12 15:00,003,\"ТОО \"\"Альфа\"\"\",KZ90601A861000000123,Payment,,500.75,1475.25\n"+
+		"2022-01-15 23:00,004,\"ТОО \"\"Альфа\"\"\",KZ90601A861000000123,Payment,1.00,,1474.25\n"+
+		"2022-01-15,,,,Turnover,26.50,500.75,\n"+
+		"2022-01-15,,,,Closing balance,,,1474.25\n", buf.String())
+
+	for _, format := range []string{"xlsx", "pdf"} {
+		statement.Format = format
+		buf.Reset()
+		require.NoError(t, s.WriteStatement(ctx, statement, &buf), format)
+		assert.NotZero(t, buf.Len(), format)
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	to := time.Date(2022, 3, 15, 10, 0, 0, 0, time.Local)
+	for period, from := range map[string]time.Time{
+		"all":   {},
+		"month": time.Date(2022, 2, 15, 10, 0, 0, 0, time.Local),
+		"week":  time.Date(2022, 3, 8, 10, 0, 0, 0, time.Local),
+		"today": to,
+		"":      time.Date(2021, 3, 15, 10, 0, 0, 0, time.Local),
+	} {
+		assert.Equal(t, from, periodStart(period, to), period)
+	}
+}
@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/internet-banking-ul/internal/modules/approvals/entities"
+)
+
+type SignatureResponse struct {
+	ID              int64     `json:"id"`
+	CompanyPersonID int64     `json:"companyPersonID"`
+	UserAccountID   int64     `json:"userAccountID"`
+	SignLevel       string    `json:"signLevel"`
+	Decision        string    `json:"decision"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+type ApprovalResponse struct {
+	ID            int64      `json:"id"`
+	CompanyID     int64      `json:"companyID"`
+	OperationType string     `json:"operationType"`
+	OperationID   string     `json:"operationID"`
+	Rule          string     `json:"rule"`
+	Status        string     `json:"status"`
+	CreatedBy     int64      `json:"createdBy"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+	ResolvedAt    *time.Time `json:"resolvedAt"`
+	// Missing - the signatures still required per sign level, empty once the approval is resolved
+	Missing    entities.Rule        `json:"missing"`
+	Signatures []*SignatureResponse `json:"signatures"`
+}
+
+func CreateApprovalResponse(approval entities.Approval) ApprovalResponse {
+	resp := ApprovalResponse{
+		ID:            approval.ID,
+		CompanyID:     approval.CompanyID,
+		OperationType: approval.OperationType,
+		OperationID:   approval.OperationID,
+		Rule:          approval.Rule,
+		Status:        approval.Status,
+		CreatedBy:     approval.CreatedBy,
+		CreatedAt:     approval.CreatedAt,
+		ExpiresAt:     approval.ExpiresAt,
+		Missing:       entities.Rule{},
+		Signatures:    []*SignatureResponse{},
+	}
+
+	if approval.ResolvedAt.Valid {
+		resolvedAt := approval.ResolvedAt.Time
+		resp.ResolvedAt = &resolvedAt
+	}
+
+	// the stored rule is written by ParseRule().String(), it always parses
+	if rule, err := entities.ParseRule(approval.Rule); err == nil && approval.Status == entities.StatusPending {
+		resp.Missing = rule.Missing(approval.Signatures.SignLevels())
+	}
+
+	for _, s := range approval.Signatures {
+		resp.Signatures = append(resp.Signatures, &SignatureResponse{
+			ID:              s.ID,
+			CompanyPersonID: s.CompanyPersonID,
+			UserAccountID:   s.UserAccountID,
+			SignLevel:       s.SignLevel,
+			Decision:        s.Decision,
+			CreatedAt:       s.CreatedAt,
+		})
+	}
+
+	return resp
+}
+
+type ApprovalListResponse []*ApprovalResponse
+
+func CreateApprovalListResponse(approvalList entities.ApprovalList) ApprovalListResponse {
+	approvalListResp := ApprovalListResponse{}
+	for _, a := range approvalList {
+		approval := CreateApprovalResponse(*a)
+		approvalListResp = append(approvalListResp, &approval)
+	}
+	return approvalListResp
+}
+
+// ApprovalCreateRequest - puts the operation of the current company on approval
+type ApprovalCreateRequest struct {
+	OperationType string `json:"operationType"`
+	OperationID   string `json:"operationID"`
+}
+
+// Normalize trims the fields, the operation type is upper case like the keys of approvals.rules
+func (r ApprovalCreateRequest) Normalize() ApprovalCreateRequest {
+	return ApprovalCreateRequest{
+		OperationType: strings.ToUpper(strings.TrimSpace(r.OperationType)),
+		OperationID:   strings.TrimSpace(r.OperationID),
+	}
+}
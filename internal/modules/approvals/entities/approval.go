@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	StatusPending  = "PENDING"
+	StatusApproved = "APPROVED"
+	StatusRejected = "REJECTED"
+	StatusExpired  = "EXPIRED"
+)
+
+// Statuses - allowed values of Approval.Status
+var Statuses = []string{StatusPending, StatusApproved, StatusRejected, StatusExpired}
+
+const (
+	DecisionSign   = "SIGN"
+	DecisionReject = "REJECT"
+)
+
+// Approval is the pending operation of a company waiting for the signatures required by Rule.
+// Version is incremented by every change, an update of a stale version fails (optimistic locking).
+type Approval struct {
+	ID            int64        `db:"ID" json:"id"`
+	CompanyID     int64        `db:"COMPANY_ID" json:"company_id"`
+	OperationType string       `db:"OPERATION_TYPE" json:"operation_type"`
+	OperationID   string       `db:"OPERATION_ID" json:"operation_id"`
+	Rule          string       `db:"RULE" json:"rule"`
+	Status        string       `db:"STATUS" json:"status"`
+	Version       int64        `db:"VERSION" json:"version"`
+	CreatedBy     int64        `db:"CREATED_BY" json:"created_by"`
+	CreatedAt     time.Time    `db:"CREATED_AT" json:"created_at"`
+	ExpiresAt     time.Time    `db:"EXPIRES_AT" json:"expires_at"`
+	ResolvedAt    sql.NullTime `db:"RESOLVED_AT" json:"resolved_at"`
+
+	Signatures SignatureList `db:"-" json:"signatures"`
+}
+
+type ApprovalList []*Approval
+
+// Signature is the decision of a company person on the approval
+type Signature struct {
+	ID              int64     `db:"ID" json:"id"`
+	ApprovalID      int64     `db:"APPROVAL_ID" json:"approval_id"`
+	CompanyPersonID int64     `db:"COMPANY_PERSON_ID" json:"company_person_id"`
+	UserAccountID   int64     `db:"USER_ACCOUNT_ID" json:"user_account_id"`
+	SignLevel       string    `db:"SIGN_LEVEL" json:"sign_level"`
+	Decision        string    `db:"DECISION" json:"decision"`
+	CreatedAt       time.Time `db:"CREATED_AT" json:"created_at"`
+}
+
+type SignatureList []*Signature
+
+// ExpiredAt reports whether the approval is still pending at t although its time is over
+func (a Approval) ExpiredAt(t time.Time) bool {
+	return a.Status == StatusPending && !t.Before(a.ExpiresAt)
+}
+
+// SignedBy reports whether the user has already decided on the approval
+func (a Approval) SignedBy(userAccountID int64) bool {
+	for _, s := range a.Signatures {
+		if s.UserAccountID == userAccountID {
+			return true
+		}
+	}
+	return false
+}
+
+// SignLevels returns the sign levels of the SIGN decisions
+func (l SignatureList) SignLevels() []string {
+	levels := make([]string, 0, len(l))
+	for _, s := range l {
+		if s.Decision == DecisionSign {
+			levels = append(levels, s.SignLevel)
+		}
+	}
+	return levels
+}
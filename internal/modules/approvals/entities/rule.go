@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+	"github.com/internet-banking-ul/tools"
+)
+
+// SigningLevels - the sign levels a rule may require, SignLevelNone never signs
+var SigningLevels = []string{companyPersonModel.SignLevelA, companyPersonModel.SignLevelB}
+
+// Rule is the number of signatures required per sign level.
+// A signature fills only a slot of its own level, an A signature doesn't replace a missing B.
+type Rule map[string]int
+
+// ParseRule parses "A+B" (one A and one B signature), "2A" (two A signatures) or "2A+B",
+// the levels are case insensitive and may be repeated ("A+A" is "2A")
+func ParseRule(s string) (Rule, error) {
+	rule := Rule{}
+	for _, term := range strings.Split(s, "+") {
+		term = strings.ToUpper(strings.TrimSpace(term))
+
+		digits := strings.IndexFunc(term, func(r rune) bool { return !unicode.IsDigit(r) })
+		if digits < 0 {
+			return nil, fmt.Errorf("approval rule %q: term %q has no sign level", s, term)
+		}
+
+		count := 1
+		if digits > 0 {
+			n, err := strconv.Atoi(term[:digits])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("approval rule %q: invalid count in %q", s, term)
+			}
+			count = n
+		}
+
+		level := term[digits:]
+		if !tools.StringInSlice(SigningLevels, level) {
+			return nil, fmt.Errorf("approval rule %q: sign level must be one of %s, got %q", s, strings.Join(SigningLevels, ", "), level)
+		}
+		rule[level] += count
+	}
+	return rule, nil
+}
+
+// String returns the canonical form of the rule, levels in alphabetical order: "2A+B"
+func (r Rule) String() string {
+	levels := make([]string, 0, len(r))
+	for level := range r {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+
+	terms := make([]string, 0, len(levels))
+	for _, level := range levels {
+		if r[level] == 1 {
+			terms = append(terms, level)
+		} else {
+			terms = append(terms, strconv.Itoa(r[level])+level)
+		}
+	}
+	return strings.Join(terms, "+")
+}
+
+// Missing returns the slots not filled by the signatures of the levels, empty when the rule is satisfied
+func (r Rule) Missing(levels []string) Rule {
+	missing := Rule{}
+	for level, count := range r {
+		missing[level] = count
+	}
+	for _, level := range levels {
+		if missing[level] > 0 {
+			missing[level]--
+		}
+		if missing[level] == 0 {
+			delete(missing, level)
+		}
+	}
+	return missing
+}
+
+// SatisfiedBy reports whether the signatures of the levels fill every slot
+func (r Rule) SatisfiedBy(levels []string) bool {
+	return len(r.Missing(levels)) == 0
+}
@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	for s, want := range map[string]string{
+		"A+B":       "A+B",
+		" b + a ":   "A+B",
+		"A+A":       "2A",
+		"2A+B":      "2A+B",
+		"B+1A+A+2B": "2A+3B",
+	} {
+		rule, err := ParseRule(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, rule.String(), s)
+	}
+
+	for _, s := range []string{"", "A+", "C", "NONE", "0A", "2", "A B"} {
+		_, err := ParseRule(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRule_Missing(t *testing.T) {
+	rule, err := ParseRule("2A+B")
+	require.NoError(t, err)
+
+	assert.Equal(t, Rule{"A": 2, "B": 1}, rule.Missing(nil))
+	assert.Equal(t, Rule{"A": 1}, rule.Missing([]string{"B", "A", "B"}))
+	assert.False(t, rule.SatisfiedBy([]string{"A", "A", "A"}))
+	assert.True(t, rule.SatisfiedBy([]string{"A", "B", "A"}))
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/internet-banking-ul/internal/consts"
+	approvalModel "github.com/internet-banking-ul/internal/modules/approvals/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/modules/repository"
+	"github.com/internet-banking-ul/internal/storage"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"github.com/internet-banking-ul/tools"
+)
+
+type RepositoryApprovalQuery interface {
+	ByID(ctx context.Context, id int64) (result approvalModel.Approval, err error)
+	PendingByOperation(ctx context.Context, companyID int64, operationType, operationID string) (result approvalModel.Approval, err error)
+	List(ctx context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (approvalModel.ApprovalList, int64, string, error)
+	Count(ctx context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (count int64, err error)
+}
+
+// approvalListColumns - the sort, search and filter whitelist of List
+var approvalListColumns = entities.ListColumns{
+	Sortable: map[string]string{
+		"id":            "ID",
+		"operationType": "OPERATION_TYPE",
+		"status":        "STATUS",
+		"createdAt":     "CREATED_AT",
+		"expiresAt":     "EXPIRES_AT",
+	},
+	DefaultSort: "id",
+	Key:         "ID",
+	Searchable:  []string{"OPERATION_TYPE", "OPERATION_ID"},
+	Filterable: map[string]entities.FilterField{
+		"status":        {Column: "STATUS", Ops: []string{entities.FilterEq, entities.FilterNe, entities.FilterIn}},
+		"operationType": {Column: "OPERATION_TYPE", Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"operationID":   {Column: "OPERATION_ID", Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"createdBy":     {Column: "CREATED_BY", Type: entities.FilterInt, Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"createdAt":     {Column: "CREATED_AT", Type: entities.FilterTime},
+		"expiresAt":     {Column: "EXPIRES_AT", Type: entities.FilterTime},
+	},
+}
+
+// RepositoryApprovalQueryImpl - the approvals of the companies, read with their signatures
+type RepositoryApprovalQueryImpl struct {
+	*repository.Repository[approvalModel.Approval]
+	signatureRepository *repository.Repository[approvalModel.Signature]
+}
+
+func newApprovalQuery(db *storage.DB) *RepositoryApprovalQueryImpl {
+	return &RepositoryApprovalQueryImpl{
+		Repository:          repository.New[approvalModel.Approval](db, "APPROVAL", approvalListColumns, nil),
+		signatureRepository: repository.New[approvalModel.Signature](db, "APPROVAL_SIGNATURE", entities.ListColumns{}, nil),
+	}
+}
+
+// ByID returns the approval with its signatures, sql.ErrNoRows when it doesn't exist
+func (repo *RepositoryApprovalQueryImpl) ByID(ctx context.Context, id int64) (result approvalModel.Approval, err error) {
+	if result, err = repo.GetByID(ctx, id); err != nil {
+		return result, err
+	}
+	return result, repo.withSignatures(ctx, &result)
+}
+
+// PendingByOperation returns the pending approval of the operation, sql.ErrNoRows when there is none
+func (repo *RepositoryApprovalQueryImpl) PendingByOperation(ctx context.Context, companyID int64, operationType, operationID string) (result approvalModel.Approval, err error) {
+	result, err = repo.GetBy(ctx, sq.Eq{
+		"COMPANY_ID":     companyID,
+		"OPERATION_TYPE": operationType,
+		"OPERATION_ID":   operationID,
+		"STATUS":         approvalModel.StatusPending,
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, repo.withSignatures(ctx, &result)
+}
+
+// List returns the page of the company approvals selected by baseFilter with their signatures,
+// the total of the filtered approvals and the cursor of the next page ("" on the last page)
+func (repo *RepositoryApprovalQueryImpl) List(ctx context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (results approvalModel.ApprovalList, count int64, nextCursor string, err error) {
+	results, count, nextCursor, err = repo.Repository.List(ctx, baseFilter, sq.Eq{"COMPANY_ID": companyID})
+	if err != nil {
+		return results, count, nextCursor, err
+	}
+	return results, count, nextCursor, repo.withSignatures(ctx, results...)
+}
+
+// Count returns the number of company approvals matching the search and the filters of baseFilter
+func (repo *RepositoryApprovalQueryImpl) Count(ctx context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (count int64, err error) {
+	return repo.Repository.Count(ctx, baseFilter, sq.Eq{"COMPANY_ID": companyID})
+}
+
+// withSignatures sets the signatures of the approvals ordered by ID, the IDs are queried consts.MaxInList at a time
+func (repo *RepositoryApprovalQueryImpl) withSignatures(ctx context.Context, approvals ...*approvalModel.Approval) error {
+	byID := make(map[int64]*approvalModel.Approval, len(approvals))
+	ids := make([]int64, 0, len(approvals))
+	for _, approval := range approvals {
+		byID[approval.ID] = approval
+		ids = append(ids, approval.ID)
+	}
+
+	for _, chunk := range tools.Chunk(tools.RemoveDuplicatesInt64(ids), consts.MaxInList) {
+		signatures, err := repo.signatureRepository.FindBy(ctx, sq.Eq{"APPROVAL_ID": chunk}, "APPROVAL_ID", "ID")
+		if err != nil {
+			return err
+		}
+
+		for _, signature := range signatures {
+			approval := byID[signature.ApprovalID]
+			approval.Signatures = append(approval.Signatures, signature)
+		}
+	}
+
+	return nil
+}
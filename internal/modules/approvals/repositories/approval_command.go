@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	approvalModel "github.com/internet-banking-ul/internal/modules/approvals/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+const (
+	approvalSequence  = "APPROVAL_SEQ"
+	signatureSequence = "APPROVAL_SIGNATURE_SEQ"
+)
+
+// RepositoryApprovalCommand - writes, every method runs in the transaction of ctx started by the service (storage.DB.Runner)
+type RepositoryApprovalCommand interface {
+	Create(ctx context.Context, approval *approvalModel.Approval) error
+	Update(ctx context.Context, approval *approvalModel.Approval) error
+	AddSignature(ctx context.Context, signature *approvalModel.Signature) error
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
+}
+
+type RepositoryApprovalCommandImpl struct {
+	DB *storage.DB
+}
+
+// Create takes the next ID from APPROVAL_SEQ, inserts the approval and sets approval.ID
+func (repo *RepositoryApprovalCommandImpl) Create(ctx context.Context, approval *approvalModel.Approval) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Create")
+
+	id, err := repo.DB.NextID(ctx, approvalSequence, "APPROVAL")
+	if err != nil {
+		l.Error("NextID", zap.Error(err))
+		return err
+	}
+
+	q := repo.DB.Builder().
+		Insert("APPROVAL").
+		Columns(
+			"ID",
+			"COMPANY_ID",
+			"OPERATION_TYPE",
+			"OPERATION_ID",
+			"RULE",
+			"STATUS",
+			"VERSION",
+			"CREATED_BY",
+			"CREATED_AT",
+			"EXPIRES_AT",
+			"RESOLVED_AT",
+		).
+		Values(
+			id,
+			approval.CompanyID,
+			approval.OperationType,
+			approval.OperationID,
+			approval.Rule,
+			approval.Status,
+			approval.Version,
+			approval.CreatedBy,
+			approval.CreatedAt,
+			approval.ExpiresAt,
+			approval.ResolvedAt,
+		)
+
+	// a concurrent pending approval of the operation breaks APPROVAL_PENDING_UK
+	if err = storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return repo.DB.Dialect.UniqueViolation(err)
+	}
+
+	approval.ID = id
+	return nil
+}
+
+// Update writes the status of the approval if nobody changed it since it was read and increments approval.Version.
+// Returns sql.ErrNoRows when the version in the database differs.
+func (repo *RepositoryApprovalCommandImpl) Update(ctx context.Context, approval *approvalModel.Approval) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Update")
+
+	q := repo.DB.Builder().
+		Update("APPROVAL").
+		SetMap(map[string]interface{}{
+			"STATUS":      approval.Status,
+			"RESOLVED_AT": approval.ResolvedAt,
+			"VERSION":     approval.Version + 1,
+		}).
+		Where(sq.Eq{"ID": approval.ID, "VERSION": approval.Version})
+
+	if err := storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return err
+	}
+
+	approval.Version++
+	return nil
+}
+
+// AddSignature takes the next ID from APPROVAL_SIGNATURE_SEQ, inserts the signature and sets signature.ID
+func (repo *RepositoryApprovalCommandImpl) AddSignature(ctx context.Context, signature *approvalModel.Signature) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("AddSignature")
+
+	id, err := repo.DB.NextID(ctx, signatureSequence, "APPROVAL_SIGNATURE")
+	if err != nil {
+		l.Error("NextID", zap.Error(err))
+		return err
+	}
+
+	q := repo.DB.Builder().
+		Insert("APPROVAL_SIGNATURE").
+		Columns(
+			"ID",
+			"APPROVAL_ID",
+			"COMPANY_PERSON_ID",
+			"USER_ACCOUNT_ID",
+			"SIGN_LEVEL",
+			"DECISION",
+			"CREATED_AT",
+		).
+		Values(
+			id,
+			signature.ApprovalID,
+			signature.CompanyPersonID,
+			signature.UserAccountID,
+			signature.SignLevel,
+			signature.Decision,
+			signature.CreatedAt,
+		)
+
+	if err = storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return err
+	}
+
+	signature.ID = id
+	return nil
+}
+
+// ExpirePending marks the pending approvals whose EXPIRES_AT has passed expired and returns their number
+func (repo *RepositoryApprovalCommandImpl) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	if repo.DB == nil {
+		return 0, fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("ExpirePending")
+
+	q := repo.DB.Builder().
+		Update("APPROVAL").
+		Set("STATUS", approvalModel.StatusExpired).
+		Set("RESOLVED_AT", now).
+		Set("VERSION", sq.Expr("VERSION + 1")).
+		Where(sq.Eq{"STATUS": approvalModel.StatusPending}).
+		Where(sq.LtOrEq{"EXPIRES_AT": now})
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		l.Error("ToSql", zap.Error(err))
+		return 0, err
+	}
+
+	l.Debug("Info", zap.String("sql", query), zap.Any("args", args))
+
+	res, err := q.RunWith(repo.DB.Runner(ctx)).ExecContext(ctx)
+	if err != nil {
+		l.Error("ExecContext", zap.Error(err))
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	approvalModel "github.com/internet-banking-ul/internal/modules/approvals/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryApproval(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	repo := NewApprovalRepository(db)
+	ctx := context.Background()
+	now := time.Date(2022, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+		VALUES (10, 'LEGAL', 'c-10', 'Company', 'Company LLP', 'TOO', '17', '123456789012')`)
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, SIGN_LEVEL, ORGANIZATION_ROLE)
+		VALUES (1, 'cp-1', 10, 100, 'A', 'DIRECTOR')`)
+
+	create := func(operationID string, expiresAt time.Time) approvalModel.Approval {
+		approval := approvalModel.Approval{
+			CompanyID: 10, OperationType: "PAYMENT", OperationID: operationID, Rule: "A+B",
+			Status: approvalModel.StatusPending, CreatedBy: 100, CreatedAt: now, ExpiresAt: expiresAt,
+		}
+		require.NoError(t, db.WithinTx(ctx, func(ctx context.Context) error {
+			return repo.Create(ctx, &approval)
+		}))
+		return approval
+	}
+
+	first := create("p-1", now.Add(time.Hour))
+	second := create("p-2", now.Add(2*time.Hour))
+	assert.Equal(t, int64(1), first.ID)
+	assert.Equal(t, int64(2), second.ID)
+
+	// one pending approval per operation
+	duplicate := second
+	err := db.WithinTx(ctx, func(ctx context.Context) error {
+		return repo.Create(ctx, &duplicate)
+	})
+	assert.ErrorIs(t, err, storage.ErrUniqueViolation)
+
+	signature := approvalModel.Signature{
+		ApprovalID: first.ID, CompanyPersonID: 1, UserAccountID: 100, SignLevel: "A",
+		Decision: approvalModel.DecisionSign, CreatedAt: now,
+	}
+	stale := first
+	require.NoError(t, db.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repo.Update(ctx, &first); err != nil {
+			return err
+		}
+		return repo.AddSignature(ctx, &signature)
+	}))
+	assert.Equal(t, int64(1), first.Version)
+
+	// the version read before the signature is stale
+	err = db.WithinTx(ctx, func(ctx context.Context) error {
+		return repo.Update(ctx, &stale)
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	read, err := repo.ByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), read.Version)
+	assert.True(t, now.Equal(read.ExpiresAt.Add(-time.Hour)))
+	require.Len(t, read.Signatures, 1)
+	assert.Equal(t, "A", read.Signatures[0].SignLevel)
+
+	pending, err := repo.PendingByOperation(ctx, 10, "PAYMENT", "p-2")
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, pending.ID)
+	assert.Empty(t, pending.Signatures)
+
+	_, err = repo.ByID(ctx, 99)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	var expired int64
+	require.NoError(t, db.WithinTx(ctx, func(ctx context.Context) (err error) {
+		expired, err = repo.ExpirePending(ctx, now.Add(time.Hour))
+		return err
+	}))
+	assert.Equal(t, int64(1), expired)
+
+	_, err = repo.PendingByOperation(ctx, 10, "PAYMENT", "p-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	filter := entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 1, Sort: "expiresAt", Order: "desc"}}
+	list, count, nextCursor, err := repo.List(ctx, 10, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, second.ID, list[0].ID)
+	require.NotEmpty(t, nextCursor)
+
+	filter.Cursor = nextCursor
+	list, _, nextCursor, err = repo.List(ctx, 10, filter)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, first.ID, list[0].ID)
+	assert.Equal(t, approvalModel.StatusExpired, list[0].Status)
+	assert.Len(t, list[0].Signatures, 1)
+	assert.Empty(t, nextCursor)
+
+	filter = entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}}
+	filter.AddFilter("status", entities.FilterEq, approvalModel.StatusPending)
+	list, count, _, err = repo.List(ctx, 10, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, list, 1)
+
+	list, count, _, err = repo.List(ctx, 11, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Empty(t, list)
+
+	// the expired approval doesn't hold the operation
+	again := create("p-1", now.Add(3*time.Hour))
+	assert.Equal(t, int64(3), again.ID)
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"github.com/internet-banking-ul/internal/storage"
+)
+
+type Repositories interface {
+	RepositoryApprovalQuery
+	RepositoryApprovalCommand
+}
+
+type RepositoriesImpl struct {
+	// inject db impl to RepositoriesImpl event the db is being used by the child struct impl
+	db *storage.DB
+	*RepositoryApprovalQueryImpl
+	*RepositoryApprovalCommandImpl
+}
+
+func NewApprovalRepository(
+	db *storage.DB,
+) *RepositoriesImpl {
+	return &RepositoriesImpl{
+		db:                          db,
+		RepositoryApprovalQueryImpl: newApprovalQuery(db),
+		RepositoryApprovalCommandImpl: &RepositoryApprovalCommandImpl{
+			DB: db,
+		},
+	}
+}
@@ -0,0 +1,488 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/internal/modules/approvals/dto"
+	approvalModel "github.com/internet-banking-ul/internal/modules/approvals/entities"
+	approvalRepo "github.com/internet-banking-ul/internal/modules/approvals/repositories"
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	auditService "github.com/internet-banking-ul/internal/modules/audit/services"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	paymentModel "github.com/internet-banking-ul/internal/modules/payments/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/health"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/metrics"
+	"github.com/internet-banking-ul/modules/tracing"
+	"go.uber.org/zap"
+)
+
+var approvalsExpired = metrics.Default.NewCounter("approvals_expired_total", "Pending approvals expired")
+
+// ownedOperationTypes - the operations their module puts on approval and signs through SignOperation, moving
+// the operation along with its approval; the generic Create and Sign refuse them
+var ownedOperationTypes = []string{paymentModel.OperationType}
+
+type ApprovalService interface {
+	List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.ApprovalListResponse, int64, string, error)
+	ByID(ctx context.Context, id int64) (*dto.ApprovalResponse, error)
+	Create(ctx context.Context, req dto.ApprovalCreateRequest) (*dto.ApprovalResponse, error)
+	Sign(ctx context.Context, id int64) (*dto.ApprovalResponse, error)
+	Reject(ctx context.Context, id int64) (*dto.ApprovalResponse, error)
+	SignOperation(ctx context.Context, operationType, operationID string) (*dto.ApprovalResponse, error)
+	IsPending(ctx context.Context, operationType, operationID string) (bool, error)
+	ExpireStale(ctx context.Context) (int64, error)
+}
+
+// ApprovalServiceImpl - every method works with the approvals of the current company (utils.ContextGetCurrentCompanyID)
+// and signs with the current company persons of the caller, both are set by middles.Require
+type ApprovalServiceImpl struct {
+	// Tx runs the writes in one transaction, the storage.DB by default
+	Tx                 storage.TxManager
+	ApprovalRepository approvalRepo.Repositories
+	// Audit records the signatures and the rejections in their transaction
+	Audit auditService.Recorder
+
+	// Rules by operation type, DefaultRule applies to the other types
+	Rules       map[string]approvalModel.Rule
+	DefaultRule approvalModel.Rule
+	// TTL - a pending approval expires TTL after it is created
+	TTL time.Duration
+	Now func() time.Time
+	// Expiry beats after every run of RunExpiry, nil when nobody checks it
+	Expiry *health.Heartbeat
+}
+
+// NewApprovalService parses the rules of cfg, an invalid rule is an error
+func NewApprovalService(
+	db *storage.DB,
+	cfg config.Approvals,
+) (*ApprovalServiceImpl, error) {
+	s := &ApprovalServiceImpl{
+		Tx:                 db,
+		ApprovalRepository: approvalRepo.NewApprovalRepository(db),
+		Audit:              auditService.NewAuditService(db),
+		Rules:              make(map[string]approvalModel.Rule, len(cfg.Rules)),
+		TTL:                cfg.TTL,
+		Now:                time.Now,
+		Expiry:             health.NewHeartbeat("approvals_expiry", 3*cfg.ExpireInterval),
+	}
+
+	var err error
+	if s.DefaultRule, err = approvalModel.ParseRule(cfg.DefaultRule); err != nil {
+		return nil, fmt.Errorf("approvals.default_rule: %w", err)
+	}
+	for operationType, rule := range cfg.Rules {
+		if s.Rules[strings.ToUpper(operationType)], err = approvalModel.ParseRule(rule); err != nil {
+			return nil, fmt.Errorf("approvals.rules.%s: %w", operationType, err)
+		}
+	}
+
+	return s, nil
+}
+
+// RunExpiry marks the stale approvals expired every interval until ctx is done
+func (s ApprovalServiceImpl) RunExpiry(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ExpireStale(ctx); err != nil {
+				logger.WorkLoggerWithContext(ctx).Error("Error expire stale approvals", zap.Error(err))
+				continue
+			}
+			s.Expiry.Beat()
+		}
+	}
+}
+
+// ExpireStale marks the pending approvals past their EXPIRES_AT expired and returns their number
+func (s ApprovalServiceImpl) ExpireStale(ctx context.Context) (expired int64, err error) {
+	ctx, span := tracing.Start(ctx, "ApprovalService.ExpireStale")
+	defer span.End()
+
+	err = s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		expired, err = s.ApprovalRepository.ExpirePending(ctx, s.Now().UTC())
+		return err
+	})
+	if expired > 0 {
+		logger.WorkLoggerWithContext(ctx).Info("Approvals expired", zap.Int64("count", expired))
+	}
+	if err == nil {
+		approvalsExpired.Add(float64(expired))
+	}
+	return expired, err
+}
+
+func (s ApprovalServiceImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.ApprovalListResponse, int64, string, error) {
+	ctx, span := tracing.Start(ctx, "ApprovalService.List")
+	defer span.End()
+
+	companyID, err := currentCompanyID(ctx)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	approvalList, count, nextCursor, err := s.ApprovalRepository.List(ctx, companyID, baseFilter)
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch ApprovalList from DB", zap.Error(err))
+		return nil, 0, "", err
+	}
+
+	return dto.CreateApprovalListResponse(approvalList), count, nextCursor, nil
+}
+
+// ByID returns apiErrors.ApprovalNotFound when the current company has no such approval
+func (s ApprovalServiceImpl) ByID(ctx context.Context, id int64) (*dto.ApprovalResponse, error) {
+	ctx, span := tracing.Start(ctx, "ApprovalService.ByID")
+	defer span.End()
+
+	approval, err := s.byID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the expiry job may not have run yet
+	if approval.ExpiredAt(s.Now()) {
+		approval.Status = approvalModel.StatusExpired
+	}
+
+	resp := dto.CreateApprovalResponse(approval)
+	return &resp, nil
+}
+
+// Create puts the operation on approval with the rule configured for its type,
+// an operation may have one pending approval at a time (the unique index APPROVAL_PENDING_UK).
+// apiErrors.ApprovalOperationOwned for the operation types of ownedOperationTypes.
+func (s ApprovalServiceImpl) Create(ctx context.Context, req dto.ApprovalCreateRequest) (*dto.ApprovalResponse, error) {
+	ctx, span := tracing.Start(ctx, "ApprovalService.Create")
+	defer span.End()
+
+	req = req.Normalize()
+	if ownedOperation(req.OperationType) {
+		return nil, apiErrors.ThrowError(apiErrors.ApprovalOperationOwned)
+	}
+	return s.create(ctx, req)
+}
+
+func (s ApprovalServiceImpl) create(ctx context.Context, req dto.ApprovalCreateRequest) (*dto.ApprovalResponse, error) {
+	companyID, err := currentCompanyID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Normalize()
+	if fields := validateCreate(req); len(fields) > 0 {
+		return nil, fields.Err()
+	}
+
+	now := s.Now().UTC()
+
+	pending, err := s.ApprovalRepository.PendingByOperation(ctx, companyID, req.OperationType, req.OperationID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch pending Approval from DB", zap.Error(err))
+		return nil, err
+	case !pending.ExpiredAt(now):
+		return nil, apiErrors.ThrowError(apiErrors.ApprovalAlreadyExists)
+	default:
+		if err = s.expire(ctx, &pending, now); err != nil {
+			return nil, err
+		}
+	}
+
+	approval := approvalModel.Approval{
+		CompanyID:     companyID,
+		OperationType: req.OperationType,
+		OperationID:   req.OperationID,
+		Rule:          s.rule(req.OperationType).String(),
+		Status:        approvalModel.StatusPending,
+		CreatedBy:     userID,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(s.TTL),
+	}
+
+	err = s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.ApprovalRepository.Create(ctx, &approval)
+	})
+	if errors.Is(err, storage.ErrUniqueViolation) {
+		// a concurrent request has put the operation on approval after the check above
+		return nil, apiErrors.ThrowError(apiErrors.ApprovalAlreadyExists)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error create Approval", zap.Error(err))
+		return nil, err
+	}
+
+	return s.ByID(ctx, approval.ID)
+}
+
+// Sign adds the signature of the caller, the approval is approved once the signatures satisfy its rule.
+// The caller signs with the sign level of a current company person still missing in the rule.
+// apiErrors.ApprovalOperationOwned for the operation types of ownedOperationTypes.
+func (s ApprovalServiceImpl) Sign(ctx context.Context, id int64) (*dto.ApprovalResponse, error) {
+	ctx, span := tracing.Start(ctx, "ApprovalService.Sign")
+	defer span.End()
+
+	approval, err := s.byID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ownedOperation(approval.OperationType) {
+		return nil, apiErrors.ThrowError(apiErrors.ApprovalOperationOwned)
+	}
+
+	return s.decide(ctx, id, approvalModel.DecisionSign)
+}
+
+// Reject rejects the approval, any person with a sign level of the rule may reject it
+func (s ApprovalServiceImpl) Reject(ctx context.Context, id int64) (*dto.ApprovalResponse, error) {
+	ctx, span := tracing.Start(ctx, "ApprovalService.Reject")
+	defer span.End()
+
+	return s.decide(ctx, id, approvalModel.DecisionReject)
+}
+
+// SignOperation signs the pending approval of the operation of the current company, the operation is put on
+// approval first when it has none; the module of the operation checks the status of the returned approval
+func (s ApprovalServiceImpl) SignOperation(ctx context.Context, operationType, operationID string) (*dto.ApprovalResponse, error) {
+	ctx, span := tracing.Start(ctx, "ApprovalService.SignOperation")
+	defer span.End()
+
+	req := dto.ApprovalCreateRequest{OperationType: operationType, OperationID: operationID}
+
+	approval, err := s.create(ctx, req)
+	if apiErr := apiErrors.ParseError(err); apiErr != nil && apiErr.Id == apiErrors.ApprovalAlreadyExists {
+		pending, err := s.pending(ctx, req.Normalize())
+		if errors.Is(err, sql.ErrNoRows) {
+			// resolved meanwhile
+			return nil, apiErrors.ThrowError(apiErrors.ApprovalConflict)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return s.decide(ctx, pending.ID, approvalModel.DecisionSign)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decide(ctx, approval.ID, approvalModel.DecisionSign)
+}
+
+// IsPending reports whether the operation of the current company is on approval, an expired approval isn't pending
+func (s ApprovalServiceImpl) IsPending(ctx context.Context, operationType, operationID string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "ApprovalService.IsPending")
+	defer span.End()
+
+	pending, err := s.pending(ctx, dto.ApprovalCreateRequest{OperationType: operationType, OperationID: operationID}.Normalize())
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !pending.ExpiredAt(s.Now()), nil
+}
+
+// pending returns the pending approval of the operation of the current company, sql.ErrNoRows when there is none
+func (s ApprovalServiceImpl) pending(ctx context.Context, req dto.ApprovalCreateRequest) (approvalModel.Approval, error) {
+	companyID, err := currentCompanyID(ctx)
+	if err != nil {
+		return approvalModel.Approval{}, err
+	}
+
+	pending, err := s.ApprovalRepository.PendingByOperation(ctx, companyID, req.OperationType, req.OperationID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch pending Approval from DB", zap.Error(err))
+	}
+	return pending, err
+}
+
+func (s ApprovalServiceImpl) decide(ctx context.Context, id int64, decision string) (*dto.ApprovalResponse, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	approval, err := s.byID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Now().UTC()
+	if approval.ExpiredAt(now) {
+		if err = s.expire(ctx, &approval, now); err != nil {
+			return nil, err
+		}
+		return nil, apiErrors.ThrowError(apiErrors.ApprovalExpired)
+	}
+	if approval.Status != approvalModel.StatusPending {
+		return nil, apiErrors.ThrowError(apiErrors.ApprovalNotPending)
+	}
+	if approval.SignedBy(userID) {
+		return nil, apiErrors.ThrowError(apiErrors.ApprovalAlreadySigned)
+	}
+
+	rule, err := approvalModel.ParseRule(approval.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	// a rejection needs any level of the rule, a signature a level still missing
+	needed := rule
+	if decision == approvalModel.DecisionSign {
+		needed = rule.Missing(approval.Signatures.SignLevels())
+	}
+	signer, ok := signerFor(ctx, needed)
+	if !ok {
+		return nil, apiErrors.ThrowError(apiErrors.ApprovalSignLevelNotNeeded)
+	}
+
+	signature := approvalModel.Signature{
+		ApprovalID:      approval.ID,
+		CompanyPersonID: signer.ID,
+		UserAccountID:   userID,
+		SignLevel:       signer.SignLevel,
+		Decision:        decision,
+		CreatedAt:       now,
+	}
+	before := approval
+	approval.Signatures = append(approval.Signatures, &signature)
+
+	switch {
+	case decision == approvalModel.DecisionReject:
+		approval.Status = approvalModel.StatusRejected
+	case rule.SatisfiedBy(approval.Signatures.SignLevels()):
+		approval.Status = approvalModel.StatusApproved
+	}
+	if approval.Status != approvalModel.StatusPending {
+		approval.ResolvedAt = sql.NullTime{Time: now, Valid: true}
+	}
+
+	// the version check of Update serializes concurrent decisions on the same approval
+	err = s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.ApprovalRepository.Update(ctx, &approval); err != nil {
+			return err
+		}
+		if err := s.ApprovalRepository.AddSignature(ctx, &signature); err != nil {
+			return err
+		}
+		return s.Audit.Record(ctx, auditService.Entry{
+			CustomerID: approval.CompanyID,
+			Entity:     auditModel.EntityApproval,
+			EntityID:   strconv.FormatInt(approval.ID, 10),
+			Action:     auditModel.ActionUpdate,
+			Before:     &before,
+			After:      &approval,
+		})
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apiErrors.ThrowError(apiErrors.ApprovalConflict)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error sign Approval", zap.Error(err))
+		return nil, err
+	}
+
+	return s.ByID(ctx, approval.ID)
+}
+
+// expire writes the expired status of the stale approval
+func (s ApprovalServiceImpl) expire(ctx context.Context, approval *approvalModel.Approval, now time.Time) error {
+	approval.Status = approvalModel.StatusExpired
+	approval.ResolvedAt = sql.NullTime{Time: now, Valid: true}
+
+	err := s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.ApprovalRepository.Update(ctx, approval)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return apiErrors.ThrowError(apiErrors.ApprovalConflict)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error expire Approval", zap.Error(err))
+	}
+	return err
+}
+
+// ownedOperation reports whether the normalized operation type is one of ownedOperationTypes
+func ownedOperation(operationType string) bool {
+	for _, owned := range ownedOperationTypes {
+		if owned == operationType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s ApprovalServiceImpl) rule(operationType string) approvalModel.Rule {
+	if rule, ok := s.Rules[operationType]; ok {
+		return rule
+	}
+	return s.DefaultRule
+}
+
+// byID returns the approval of the current company, an approval of another company is not found
+func (s ApprovalServiceImpl) byID(ctx context.Context, id int64) (approvalModel.Approval, error) {
+	companyID, err := currentCompanyID(ctx)
+	if err != nil {
+		return approvalModel.Approval{}, err
+	}
+
+	approval, err := s.ApprovalRepository.ByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && approval.CompanyID != companyID {
+		return approval, apiErrors.ThrowError(apiErrors.ApprovalNotFound)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch Approval from DB", zap.Error(err))
+	}
+
+	return approval, err
+}
+
+// signerFor returns the current company person of the caller whose sign level is needed
+func signerFor(ctx context.Context, needed approvalModel.Rule) (*companyPersonModel.CompanyPerson, bool) {
+	persons, _ := companyPersonModel.ContextGetCurrentCompanyPersons(ctx)
+	for _, person := range persons {
+		if needed[person.SignLevel] > 0 {
+			return person, true
+		}
+	}
+	return nil, false
+}
+
+func currentCompanyID(ctx context.Context) (int64, error) {
+	companyID, ok := utils.ContextGetCurrentCompanyID(ctx)
+	if !ok {
+		return 0, apiErrors.ThrowError(apiErrors.AccessDenied)
+	}
+	return companyID, nil
+}
+
+func currentUserID(ctx context.Context) (int64, error) {
+	userID, ok := utils.ContextGetCurrentUserID(ctx)
+	if !ok {
+		return 0, apiErrors.ThrowError(apiErrors.UserNotLogined)
+	}
+	return int64(userID), nil
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/internal/modules/approvals/dto"
+	approvalModel "github.com/internet-banking-ul/internal/modules/approvals/entities"
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newTestService(t *testing.T, clock *testClock) *ApprovalServiceImpl {
+	t.Helper()
+
+	if logger.WorkLogger == nil {
+		logger.WorkLogger, logger.SqlLogger = zap.NewNop(), zap.NewNop()
+	}
+
+	return &ApprovalServiceImpl{
+		Tx:                 memoryTx{},
+		ApprovalRepository: newMemoryRepository(),
+		Audit:              &memoryAudit{},
+		Rules:              map[string]approvalModel.Rule{"SALARY": {companyPersonModel.SignLevelA: 2}},
+		DefaultRule:        approvalModel.Rule{companyPersonModel.SignLevelA: 1, companyPersonModel.SignLevelB: 1},
+		TTL:                time.Hour,
+		Now:                clock.Now,
+	}
+}
+
+// callerCtx is the context filled by Authenticate and Require for the user acting for the company with the sign level
+func callerCtx(userID int32, companyID int64, signLevel string) context.Context {
+	ctx := context.WithValue(context.Background(), utils.ContextHolderKey, &sync.Map{})
+	utils.SetAttribute(ctx, utils.AttributeCurrentUserID, userID)
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyID, companyID)
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyPersons, companyPersonModel.CompanyPersonList{
+		{ID: int64(userID) * 10, CompanyID: companyID, UserAccountID: int64(userID), SignLevel: signLevel, OrganizationRole: "DIRECTOR"},
+	})
+	return ctx
+}
+
+func requireError(t *testing.T, err error, id string) {
+	t.Helper()
+
+	apiErr := apiErrors.ParseError(err)
+	require.NotNil(t, apiErr, "%v", err)
+	assert.Equal(t, id, apiErr.Id)
+}
+
+func TestApprovalServiceImpl_SignAB(t *testing.T) {
+	clock := &testClock{now: time.Date(2022, 3, 1, 9, 0, 0, 0, time.UTC)}
+	s := newTestService(t, clock)
+
+	accountant := callerCtx(1, 10, companyPersonModel.SignLevelB)
+	director := callerCtx(2, 10, companyPersonModel.SignLevelA)
+	secondDirector := callerCtx(3, 10, companyPersonModel.SignLevelA)
+	foreign := callerCtx(4, 20, companyPersonModel.SignLevelA)
+
+	_, err := s.Create(accountant, dto.ApprovalCreateRequest{})
+	require.Error(t, err)
+	assert.Len(t, apiErrors.ParseError(err).Fields, 2)
+
+	approval, err := s.Create(accountant, dto.ApprovalCreateRequest{OperationType: " invoice ", OperationID: "i-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "INVOICE", approval.OperationType)
+	assert.Equal(t, "A+B", approval.Rule)
+	assert.Equal(t, approvalModel.StatusPending, approval.Status)
+	assert.Equal(t, approvalModel.Rule{"A": 1, "B": 1}, approval.Missing)
+	assert.Equal(t, clock.now.Add(time.Hour), approval.ExpiresAt)
+
+	_, err = s.Create(director, dto.ApprovalCreateRequest{OperationType: "INVOICE", OperationID: "i-1"})
+	requireError(t, err, apiErrors.ApprovalAlreadyExists)
+
+	_, err = s.ByID(foreign, approval.ID)
+	requireError(t, err, apiErrors.ApprovalNotFound)
+	_, err = s.Sign(foreign, approval.ID)
+	requireError(t, err, apiErrors.ApprovalNotFound)
+
+	signed, err := s.Sign(accountant, approval.ID)
+	require.NoError(t, err)
+	assert.Equal(t, approvalModel.StatusPending, signed.Status)
+	assert.Equal(t, approvalModel.Rule{"A": 1}, signed.Missing)
+	require.Len(t, signed.Signatures, 1)
+	assert.Equal(t, int64(10), signed.Signatures[0].CompanyPersonID)
+
+	_, err = s.Sign(accountant, approval.ID)
+	requireError(t, err, apiErrors.ApprovalAlreadySigned)
+
+	// a second B signature isn't needed
+	_, err = s.Sign(callerCtx(5, 10, companyPersonModel.SignLevelB), approval.ID)
+	requireError(t, err, apiErrors.ApprovalSignLevelNotNeeded)
+	_, err = s.Sign(callerCtx(6, 10, companyPersonModel.SignLevelNone), approval.ID)
+	requireError(t, err, apiErrors.ApprovalSignLevelNotNeeded)
+
+	signed, err = s.Sign(director, approval.ID)
+	require.NoError(t, err)
+	assert.Equal(t, approvalModel.StatusApproved, signed.Status)
+	assert.Empty(t, signed.Missing)
+	require.NotNil(t, signed.ResolvedAt)
+	assert.Len(t, signed.Signatures, 2)
+
+	_, err = s.Sign(secondDirector, approval.ID)
+	requireError(t, err, apiErrors.ApprovalNotPending)
+	_, err = s.Reject(secondDirector, approval.ID)
+	requireError(t, err, apiErrors.ApprovalNotPending)
+
+	// the operation may go on approval again once the previous one is resolved
+	_, err = s.Create(director, dto.ApprovalCreateRequest{OperationType: "INVOICE", OperationID: "i-1"})
+	require.NoError(t, err)
+
+	list, count, _, err := s.List(accountant, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Len(t, list, 2)
+
+	list, count, _, err = s.List(foreign, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Empty(t, list)
+}
+
+func TestApprovalServiceImpl_OwnedOperation(t *testing.T) {
+	clock := &testClock{now: time.Date(2022, 3, 1, 9, 0, 0, 0, time.UTC)}
+	s := newTestService(t, clock)
+
+	accountant := callerCtx(1, 10, companyPersonModel.SignLevelB)
+	director := callerCtx(2, 10, companyPersonModel.SignLevelA)
+
+	// the payments are signed by the payments module only
+	_, err := s.Create(accountant, dto.ApprovalCreateRequest{OperationType: " payment ", OperationID: "1"})
+	requireError(t, err, apiErrors.ApprovalOperationOwned)
+
+	approval, err := s.SignOperation(accountant, "PAYMENT", "1")
+	require.NoError(t, err)
+	assert.Equal(t, approvalModel.StatusPending, approval.Status)
+
+	_, err = s.Sign(director, approval.ID)
+	requireError(t, err, apiErrors.ApprovalOperationOwned)
+
+	// a rejection lets the module start the signing over
+	rejected, err := s.Reject(director, approval.ID)
+	require.NoError(t, err)
+	assert.Equal(t, approvalModel.StatusRejected, rejected.Status)
+}
+
+func TestApprovalServiceImpl_RuleByOperationTypeAndReject(t *testing.T) {
+	clock := &testClock{now: time.Date(2022, 3, 1, 9, 0, 0, 0, time.UTC)}
+	s := newTestService(t, clock)
+
+	first := callerCtx(1, 10, companyPersonModel.SignLevelA)
+	second := callerCtx(2, 10, companyPersonModel.SignLevelA)
+
+	approval, err := s.Create(first, dto.ApprovalCreateRequest{OperationType: "SALARY", OperationID: "s-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "2A", approval.Rule)
+
+	signed, err := s.Sign(first, approval.ID)
+	require.NoError(t, err)
+	assert.Equal(t, approvalModel.Rule{"A": 1}, signed.Missing)
+
+	// B is not part of the rule, it can neither sign nor reject
+	_, err = s.Reject(callerCtx(3, 10, companyPersonModel.SignLevelB), approval.ID)
+	requireError(t, err, apiErrors.ApprovalSignLevelNotNeeded)
+
+	rejected, err := s.Reject(second, approval.ID)
+	require.NoError(t, err)
+	assert.Equal(t, approvalModel.StatusRejected, rejected.Status)
+	assert.Empty(t, rejected.Missing)
+	require.Len(t, rejected.Signatures, 2)
+	assert.Equal(t, approvalModel.DecisionReject, rejected.Signatures[1].Decision)
+
+	// the signature and the rejection are audited
+	entries := s.Audit.(*memoryAudit).entries
+	require.Len(t, entries, 2)
+	assert.Equal(t, auditModel.EntityApproval, entries[1].Entity)
+	assert.Equal(t, int64(10), entries[1].CustomerID)
+	changes := auditModel.Diff(entries[1].Before, entries[1].After)
+	require.Len(t, changes, 4)
+	assert.Equal(t, []string{"resolved_at", "signatures", "status", "version"},
+		[]string{changes[0].Field, changes[1].Field, changes[2].Field, changes[3].Field})
+	assert.Equal(t, auditModel.Change{Field: "status", Before: approvalModel.StatusPending, After: approvalModel.StatusRejected}, changes[2])
+}
+
+func TestApprovalServiceImpl_Expiry(t *testing.T) {
+	clock := &testClock{now: time.Date(2022, 3, 1, 9, 0, 0, 0, time.UTC)}
+	s := newTestService(t, clock)
+	ctx := callerCtx(1, 10, companyPersonModel.SignLevelA)
+
+	stale, err := s.Create(ctx, dto.ApprovalCreateRequest{OperationType: "INVOICE", OperationID: "i-1"})
+	require.NoError(t, err)
+	fresh, err := s.Create(ctx, dto.ApprovalCreateRequest{OperationType: "INVOICE", OperationID: "i-2"})
+	require.NoError(t, err)
+
+	clock.now = clock.now.Add(time.Hour)
+
+	// the stale approval reads expired before the job runs and can't be signed
+	read, err := s.ByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, approvalModel.StatusExpired, read.Status)
+
+	_, err = s.Sign(ctx, stale.ID)
+	requireError(t, err, apiErrors.ApprovalExpired)
+
+	// an expired pending approval doesn't block a new one of the operation
+	_, err = s.Create(ctx, dto.ApprovalCreateRequest{OperationType: "INVOICE", OperationID: "i-2"})
+	require.NoError(t, err)
+
+	expired, err := s.ExpireStale(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), expired, "both were expired on access")
+
+	read, err = s.ByID(ctx, fresh.ID)
+	require.NoError(t, err)
+	assert.Equal(t, approvalModel.StatusExpired, read.Status)
+	require.NotNil(t, read.ResolvedAt)
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	expired, err = s.ExpireStale(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+}
+
+func TestApprovalServiceImpl_CreateConcurrent(t *testing.T) {
+	clock := &testClock{now: time.Date(2022, 3, 1, 9, 0, 0, 0, time.UTC)}
+	s := newTestService(t, clock)
+	s.ApprovalRepository = racingRepository{newMemoryRepository()}
+	ctx := callerCtx(1, 10, companyPersonModel.SignLevelA)
+
+	_, err := s.Create(ctx, dto.ApprovalCreateRequest{OperationType: "INVOICE", OperationID: "i-1"})
+	require.NoError(t, err)
+
+	_, err = s.Create(ctx, dto.ApprovalCreateRequest{OperationType: "INVOICE", OperationID: "i-1"})
+	requireError(t, err, apiErrors.ApprovalAlreadyExists)
+}
+
+func TestNewApprovalService_Rules(t *testing.T) {
+	s, err := NewApprovalService(nil, config.Approvals{DefaultRule: "a+b", Rules: map[string]string{"salary": "A+A"}, TTL: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, "A+B", s.DefaultRule.String())
+	assert.Equal(t, "2A", s.rule("SALARY").String())
+	assert.Equal(t, "A+B", s.rule("INVOICE").String())
+
+	_, err = NewApprovalService(nil, config.Approvals{DefaultRule: "A+C"})
+	assert.Error(t, err)
+	_, err = NewApprovalService(nil, config.Approvals{DefaultRule: "A", Rules: map[string]string{"INVOICE": "3"}})
+	assert.Error(t, err)
+}
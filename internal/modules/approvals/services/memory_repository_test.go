@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	approvalModel "github.com/internet-banking-ul/internal/modules/approvals/entities"
+	auditService "github.com/internet-banking-ul/internal/modules/audit/services"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage"
+)
+
+// memoryRepository keeps the approvals in a map, List ignores everything of baseFilter but the page size
+type memoryRepository struct {
+	mu         sync.Mutex
+	approvals  map[int64]approvalModel.Approval
+	signatures map[int64]approvalModel.SignatureList
+	nextID     int64
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		approvals:  map[int64]approvalModel.Approval{},
+		signatures: map[int64]approvalModel.SignatureList{},
+	}
+}
+
+// memoryTx runs fn without a transaction, memoryRepository has none
+type memoryTx struct{}
+
+func (memoryTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error, _ ...storage.TxOption) error {
+	return fn(ctx)
+}
+
+func (r *memoryRepository) withSignatures(approval approvalModel.Approval) approvalModel.Approval {
+	approval.Signatures = nil
+	for _, s := range r.signatures[approval.ID] {
+		signature := *s
+		approval.Signatures = append(approval.Signatures, &signature)
+	}
+	return approval
+}
+
+func (r *memoryRepository) ByID(_ context.Context, id int64) (approvalModel.Approval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	approval, ok := r.approvals[id]
+	if !ok {
+		return approval, sql.ErrNoRows
+	}
+	return r.withSignatures(approval), nil
+}
+
+func (r *memoryRepository) PendingByOperation(_ context.Context, companyID int64, operationType, operationID string) (approvalModel.Approval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, a := range r.approvals {
+		if a.CompanyID == companyID && a.OperationType == operationType && a.OperationID == operationID && a.Status == approvalModel.StatusPending {
+			return r.withSignatures(a), nil
+		}
+	}
+	return approvalModel.Approval{}, sql.ErrNoRows
+}
+
+func (r *memoryRepository) List(_ context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (approvalModel.ApprovalList, int64, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := approvalModel.ApprovalList{}
+	for _, a := range r.approvals {
+		if a.CompanyID == companyID {
+			approval := r.withSignatures(a)
+			results = append(results, &approval)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+
+	count := int64(len(results))
+	if size := int(baseFilter.GetSize()); len(results) > size {
+		results = results[:size]
+	}
+	return results, count, "", nil
+}
+
+func (r *memoryRepository) Count(ctx context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (int64, error) {
+	_, count, _, err := r.List(ctx, companyID, baseFilter)
+	return count, err
+}
+
+func (r *memoryRepository) Create(_ context.Context, approval *approvalModel.Approval) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// APPROVAL_PENDING_UK
+	for _, a := range r.approvals {
+		if a.CompanyID == approval.CompanyID && a.OperationType == approval.OperationType && a.OperationID == approval.OperationID &&
+			a.Status == approvalModel.StatusPending && approval.Status == approvalModel.StatusPending {
+			return storage.ErrUniqueViolation
+		}
+	}
+
+	r.nextID++
+	approval.ID = r.nextID
+	stored := *approval
+	stored.Signatures = nil
+	r.approvals[approval.ID] = stored
+	return nil
+}
+
+func (r *memoryRepository) Update(_ context.Context, approval *approvalModel.Approval) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.approvals[approval.ID]
+	if !ok || stored.Version != approval.Version {
+		return sql.ErrNoRows
+	}
+
+	stored.Status = approval.Status
+	stored.ResolvedAt = approval.ResolvedAt
+	stored.Version++
+	r.approvals[approval.ID] = stored
+
+	approval.Version++
+	return nil
+}
+
+func (r *memoryRepository) AddSignature(_ context.Context, signature *approvalModel.Signature) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	signature.ID = r.nextID
+	stored := *signature
+	r.signatures[signature.ApprovalID] = append(r.signatures[signature.ApprovalID], &stored)
+	return nil
+}
+
+func (r *memoryRepository) ExpirePending(_ context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired int64
+	for id, a := range r.approvals {
+		if a.ExpiredAt(now) {
+			a.Status = approvalModel.StatusExpired
+			a.ResolvedAt = sql.NullTime{Time: now, Valid: true}
+			a.Version++
+			r.approvals[id] = a
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// racingRepository misses the pending approvals, as a concurrent request creating one after the check would
+type racingRepository struct {
+	*memoryRepository
+}
+
+func (r racingRepository) PendingByOperation(context.Context, int64, string, string) (approvalModel.Approval, error) {
+	return approvalModel.Approval{}, sql.ErrNoRows
+}
+
+// memoryAudit keeps the recorded entries
+type memoryAudit struct {
+	mu      sync.Mutex
+	entries []auditService.Entry
+}
+
+func (a *memoryAudit) Record(_ context.Context, entry auditService.Entry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entry)
+	return nil
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/modules/approvals/dto"
+)
+
+// max lengths of the APPROVAL columns
+const (
+	operationTypeMaxLength = 64
+	operationIDMaxLength   = 64
+)
+
+// validateCreate checks the fields of the normalized request
+func validateCreate(req dto.ApprovalCreateRequest) apiErrors.FieldErrors {
+	var fields apiErrors.FieldErrors
+
+	if req.OperationType == "" {
+		fields.Add("operationType", apiErrors.FieldRequired, "operationType is required")
+	} else if utf8.RuneCountInString(req.OperationType) > operationTypeMaxLength {
+		fields.Add("operationType", apiErrors.FieldTooLong, fmt.Sprintf("operationType must be at most %d characters", operationTypeMaxLength))
+	}
+
+	if req.OperationID == "" {
+		fields.Add("operationID", apiErrors.FieldRequired, "operationID is required")
+	} else if utf8.RuneCountInString(req.OperationID) > operationIDMaxLength {
+		fields.Add("operationID", apiErrors.FieldTooLong, fmt.Sprintf("operationID must be at most %d characters", operationIDMaxLength))
+	}
+
+	return fields
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+)
+
+type AuditRecordResponse struct {
+	ID        int64                 `json:"id"`
+	ActorID   *int64                `json:"actorID"`
+	IP        string                `json:"ip"`
+	DeviceID  string                `json:"deviceID"`
+	Entity    string                `json:"entity"`
+	EntityID  string                `json:"entityID"`
+	Action    string                `json:"action"`
+	Changes   auditModel.ChangeList `json:"changes"`
+	CreatedAt time.Time             `json:"createdAt"`
+	Hash      string                `json:"hash"`
+}
+
+func CreateAuditRecordResponse(record auditModel.Record) AuditRecordResponse {
+	resp := AuditRecordResponse{
+		ID:        record.ID,
+		IP:        record.IP,
+		DeviceID:  record.DeviceID,
+		Entity:    record.Entity,
+		EntityID:  record.EntityID,
+		Action:    record.Action,
+		CreatedAt: record.CreatedAt,
+		Hash:      record.Hash,
+	}
+
+	if record.ActorID.Valid {
+		resp.ActorID = &record.ActorID.Int64
+	}
+	// the changes are written by Record, a record not decoding is shown without them
+	resp.Changes, _ = record.ChangeList()
+
+	return resp
+}
+
+type AuditRecordListResponse []*AuditRecordResponse
+
+func CreateAuditRecordListResponse(recordList auditModel.RecordList) AuditRecordListResponse {
+	recordListResp := AuditRecordListResponse{}
+	for _, r := range recordList {
+		record := CreateAuditRecordResponse(*r)
+		recordListResp = append(recordListResp, &record)
+	}
+	return recordListResp
+}
+
+// VerifyResponse - the result of the check of the hash chain, BrokenID is the first record failing it
+type VerifyResponse struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	BrokenID *int64 `json:"brokenID"`
+	Reason   string `json:"reason,omitempty"`
+}
@@ -0,0 +1,179 @@
+package entities
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/internet-banking-ul/tools"
+)
+
+const (
+	ActionCreate = "CREATE"
+	ActionUpdate = "UPDATE"
+	ActionDelete = "DELETE"
+)
+
+// Actions - allowed values of Record.Action
+var Actions = []string{ActionCreate, ActionUpdate, ActionDelete}
+
+// the audited entities, values of Record.Entity
+const (
+	EntityCustomer      = "CUSTOMER"
+	EntityPayment       = "PAYMENT"
+	EntityCompanyPerson = "COMPANY_PERSON"
+	EntityApproval      = "APPROVAL"
+)
+
+var (
+	hashKeyMu sync.RWMutex
+	// hashKey is empty until SetHashKey is called, the trails of development are hashed with the empty key
+	hashKey []byte
+)
+
+// SetHashKey sets the HMAC key of the record hashes. Every instance needs the same key,
+// the records hashed with another key don't pass the verification.
+func SetHashKey(key []byte) {
+	hashKeyMu.Lock()
+	defer hashKeyMu.Unlock()
+	hashKey = key
+}
+
+// Record is a row of the audit trail: who (the user, the IP and the device of the request) did what
+// to the entity of the customer. Records are only appended, every record carries the hash of the previous
+// record of the customer, so a changed or deleted record breaks the chain of the customer, see Record.ComputeHash.
+type Record struct {
+	ID         int64         `db:"ID" json:"id"`
+	CustomerID sql.NullInt64 `db:"CUSTOMER_ID" json:"customer_id"`
+	ActorID    sql.NullInt64 `db:"ACTOR_ID" json:"actor_id"`
+	IP         string        `db:"IP" json:"ip"`
+	DeviceID   string        `db:"DEVICE_ID" json:"device_id"`
+	Entity     string        `db:"ENTITY" json:"entity"`
+	EntityID   string        `db:"ENTITY_ID" json:"entity_id"`
+	Action     string        `db:"ACTION" json:"action"`
+	// Changes is the JSON of the ChangeList
+	Changes   string    `db:"CHANGES" json:"changes"`
+	CreatedAt time.Time `db:"CREATED_AT" json:"created_at"`
+	PrevHash  string    `db:"PREV_HASH" json:"prev_hash"`
+	Hash      string    `db:"HASH" json:"hash"`
+}
+
+type RecordList []*Record
+
+// ComputeHash - HMAC-SHA256 (the key of SetHashKey) of the fields of the record but Hash, PrevHash included, in hex.
+// Without the key the whole chain could be recomputed after a change.
+func (r Record) ComputeHash() string {
+	content, _ := json.Marshal([]interface{}{
+		r.ID,
+		r.CustomerID.Int64, r.CustomerID.Valid,
+		r.ActorID.Int64, r.ActorID.Valid,
+		r.IP,
+		r.DeviceID,
+		r.Entity,
+		r.EntityID,
+		r.Action,
+		r.Changes,
+		r.CreatedAt.UTC().Format(time.RFC3339Nano),
+		r.PrevHash,
+	})
+	hashKeyMu.RLock()
+	mac := hmac.New(sha256.New, hashKey)
+	hashKeyMu.RUnlock()
+
+	mac.Write(content)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ChangeList decodes Changes
+func (r Record) ChangeList() (ChangeList, error) {
+	var changes ChangeList
+	if r.Changes == "" {
+		return changes, nil
+	}
+	err := json.Unmarshal([]byte(r.Changes), &changes)
+	return changes, err
+}
+
+// Change is the value of a field before and after the action, nil for the absent side
+type Change struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type ChangeList []Change
+
+// Diff returns the changed fields of two structs (or pointers to them) by their json names, built with
+// tools.StructToMap. A nil side is the create (before) or the delete (after), every field of the other side
+// is listed then. sql.Null* and the other driver.Valuer fields are compared by their values.
+func Diff(before, after interface{}) ChangeList {
+	beforeFields := fields(before)
+	afterFields := fields(after)
+
+	names := make([]string, 0, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := ChangeList{}
+	for _, name := range names {
+		b, a := beforeFields[name], afterFields[name]
+		if beforeFields != nil && afterFields != nil && reflect.DeepEqual(b, a) {
+			continue
+		}
+		changes = append(changes, Change{Field: name, Before: b, After: a})
+	}
+	return changes
+}
+
+func fields(obj interface{}) map[string]interface{} {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	result := map[string]interface{}{}
+	for tag, value := range tools.StructToMap(v.Interface(), "json") {
+		name := strings.Split(tag, ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		result[name] = plain(value)
+	}
+	return result
+}
+
+// plain is the value of a driver.Valuer, a UTC time or the value itself
+func plain(value interface{}) interface{} {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		value = v
+	}
+	if t, ok := value.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type auditedEntity struct {
+	ID        int64          `json:"id"`
+	Status    string         `json:"status"`
+	Reason    sql.NullString `json:"reason,omitempty"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Secret    string         `json:"-"`
+	internal  string
+}
+
+func TestDiff(t *testing.T) {
+	at := time.Date(2022, 6, 15, 10, 0, 0, 0, time.FixedZone("ALMT", 6*3600))
+	before := auditedEntity{ID: 1, Status: "SENT", UpdatedAt: at, Secret: "a", internal: "a"}
+	after := before
+	after.Status = "REJECTED"
+	after.Reason = sql.NullString{String: "Late", Valid: true}
+	after.Secret, after.internal = "b", "b"
+
+	assert.Equal(t, ChangeList{
+		{Field: "reason", Before: nil, After: "Late"},
+		{Field: "status", Before: "SENT", After: "REJECTED"},
+	}, Diff(before, &after))
+
+	assert.Equal(t, ChangeList{}, Diff(&before, before))
+
+	created := Diff(nil, &before)
+	assert.Len(t, created, 4)
+	assert.Equal(t, Change{Field: "updated_at", After: "2022-06-15T04:00:00Z"}, created[3])
+
+	deleted := Diff(&before, (*auditedEntity)(nil))
+	assert.Len(t, deleted, 4)
+	assert.Equal(t, Change{Field: "id", Before: int64(1)}, deleted[0])
+}
+
+func TestRecord_ComputeHash(t *testing.T) {
+	record := Record{
+		ID:        1,
+		ActorID:   sql.NullInt64{Int64: 100, Valid: true},
+		IP:        "10.0.0.1",
+		Entity:    EntityPayment,
+		EntityID:  "1",
+		Action:    ActionCreate,
+		Changes:   `[]`,
+		CreatedAt: time.Date(2022, 6, 15, 10, 0, 0, 0, time.UTC),
+	}
+	hash := record.ComputeHash()
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, record.ComputeHash())
+
+	record.Hash = "ignored"
+	assert.Equal(t, hash, record.ComputeHash())
+
+	for _, change := range []func(r *Record){
+		func(r *Record) { r.PrevHash = "x" },
+		func(r *Record) { r.ActorID.Valid = false },
+		func(r *Record) { r.Changes = `[{}]` },
+		func(r *Record) { r.CreatedAt = r.CreatedAt.Add(time.Microsecond) },
+	} {
+		changed := record
+		change(&changed)
+		assert.NotEqual(t, hash, changed.ComputeHash())
+	}
+
+	SetHashKey([]byte("secret"))
+	defer SetHashKey(nil)
+	assert.NotEqual(t, hash, record.ComputeHash(), "keyed")
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/modules/repository"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+type RepositoryAuditQuery interface {
+	List(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (auditModel.RecordList, int64, string, error)
+	Count(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (count int64, err error)
+	EachRecord(ctx context.Context, customerID int64, fn func(record auditModel.Record) error) error
+	Head(ctx context.Context, customerID int64) (lastID int64, hash string, err error)
+}
+
+// auditListColumns - the sort, search and filter whitelist of List
+var auditListColumns = entities.ListColumns{
+	Sortable: map[string]string{
+		"id":        "ID",
+		"entity":    "ENTITY",
+		"action":    "ACTION",
+		"createdAt": "CREATED_AT",
+	},
+	DefaultSort: "id",
+	Key:         "ID",
+	Searchable:  []string{"ENTITY", "ENTITY_ID", "IP", "DEVICE_ID"},
+	Filterable: map[string]entities.FilterField{
+		"entity":    {Column: "ENTITY", Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"entityID":  {Column: "ENTITY_ID", Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"action":    {Column: "ACTION", Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"actorID":   {Column: "ACTOR_ID", Type: entities.FilterInt, Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"ip":        {Column: "IP", Ops: []string{entities.FilterEq, entities.FilterPrefix}},
+		"deviceID":  {Column: "DEVICE_ID", Ops: []string{entities.FilterEq}},
+		"createdAt": {Column: "CREATED_AT", Type: entities.FilterTime},
+	},
+}
+
+// RepositoryAuditQueryImpl - the audit trails of the customers
+type RepositoryAuditQueryImpl struct {
+	*repository.Repository[auditModel.Record]
+}
+
+func newAuditQuery(db *storage.DB) *RepositoryAuditQueryImpl {
+	return &RepositoryAuditQueryImpl{
+		Repository: repository.New[auditModel.Record](db, "AUDIT_LOG", auditListColumns, nil),
+	}
+}
+
+// List returns the page of the customer records selected by baseFilter, the total of the filtered records
+// and the cursor of the next page ("" on the last page)
+func (repo *RepositoryAuditQueryImpl) List(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (auditModel.RecordList, int64, string, error) {
+	return repo.Repository.List(ctx, baseFilter, sq.Eq{"CUSTOMER_ID": customerID})
+}
+
+// Count returns the number of customer records matching the search and the filters of baseFilter
+func (repo *RepositoryAuditQueryImpl) Count(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (count int64, err error) {
+	return repo.Repository.Count(ctx, baseFilter, sq.Eq{"CUSTOMER_ID": customerID})
+}
+
+// EachRecord calls fn for every record of the customer in the order of its chain, an error of fn stops the iteration
+func (repo *RepositoryAuditQueryImpl) EachRecord(ctx context.Context, customerID int64, fn func(record auditModel.Record) error) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("EachRecord")
+
+	// the trail is read row by row, it isn't loaded into the memory as FindBy would
+	q := repo.Select().
+		Where(sq.Eq{"CUSTOMER_ID": customerID}).
+		OrderBy("ID")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		l.Error("ToSql", zap.Error(err))
+		return err
+	}
+
+	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := q.RunWith(repo.DB.Runner(ctx)).QueryContext(ctx)
+	if err != nil {
+		l.Error("QueryContext", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record auditModel.Record
+		if err := repo.Scan(rows, &record); err != nil {
+			l.Error("Scan", zap.Error(err))
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Head returns the ID and the hash of the last record of the customer, zero and "" while its trail is empty
+func (repo *RepositoryAuditQueryImpl) Head(ctx context.Context, customerID int64) (lastID int64, hash string, err error) {
+	if repo.DB == nil {
+		err = fmt.Errorf("db is nil")
+		return
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Head")
+
+	q := repo.DB.Builder().
+		Select("LAST_ID", "HASH").
+		From("AUDIT_LOG_HEAD").
+		Where(sq.Eq{"CUSTOMER_ID": customerID})
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		l.Error("ToSql", zap.Error(err))
+		return
+	}
+
+	l.Debug("Info", zap.String("sql", query), zap.Any("args", args))
+
+	err = q.RunWith(repo.DB.Runner(ctx)).QueryRowContext(ctx).Scan(&lastID, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", nil
+	}
+	return
+}
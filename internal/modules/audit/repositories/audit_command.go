@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+const auditSequence = "AUDIT_LOG_SEQ"
+
+// RepositoryAuditCommand - the trail is only appended, in the transaction of the audited change
+type RepositoryAuditCommand interface {
+	Append(ctx context.Context, record *auditModel.Record) error
+}
+
+type RepositoryAuditCommandImpl struct {
+	DB *storage.DB
+}
+
+// Append chains the record to the last record of its customer and inserts it, setting ID, PrevHash and Hash.
+// AUDIT_LOG_HEAD keeps the hash of the last record per customer (0 for the records without one): the row is
+// locked by an update first, so the chain doesn't fork. The lock is held until the audited change commits,
+// the audited writes of one customer are serialized by it while the customers append independently.
+// The head is created by the first append of the customer, the one of the customer create.
+func (repo *RepositoryAuditCommandImpl) Append(ctx context.Context, record *auditModel.Record) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Append")
+
+	prevHash, err := repo.lockHead(ctx, record.CustomerID.Int64)
+	if err != nil {
+		return err
+	}
+
+	id, err := repo.DB.NextID(ctx, auditSequence, "AUDIT_LOG")
+	if err != nil {
+		l.Error("NextID", zap.Error(err))
+		return err
+	}
+
+	record.ID = id
+	// the precision every dialect keeps, so the hash of the read record is the same
+	record.CreatedAt = record.CreatedAt.UTC().Truncate(time.Microsecond)
+	record.PrevHash = prevHash
+	record.Hash = record.ComputeHash()
+
+	q := repo.DB.Builder().
+		Insert("AUDIT_LOG").
+		Columns(
+			"ID",
+			"CUSTOMER_ID",
+			"ACTOR_ID",
+			"IP",
+			"DEVICE_ID",
+			"ENTITY",
+			"ENTITY_ID",
+			"ACTION",
+			"CHANGES",
+			"CREATED_AT",
+			"PREV_HASH",
+			"HASH",
+		).
+		Values(
+			record.ID,
+			record.CustomerID,
+			record.ActorID,
+			record.IP,
+			record.DeviceID,
+			record.Entity,
+			record.EntityID,
+			record.Action,
+			record.Changes,
+			record.CreatedAt,
+			record.PrevHash,
+			record.Hash,
+		)
+
+	if err = storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return err
+	}
+
+	head := repo.DB.Builder().
+		Update("AUDIT_LOG_HEAD").
+		Set("LAST_ID", record.ID).
+		Set("HASH", record.Hash).
+		Where(sq.Eq{"CUSTOMER_ID": record.CustomerID.Int64})
+
+	return storage.ExecAffected(ctx, l, head.RunWith(repo.DB.Runner(ctx)))
+}
+
+// lockHead locks the head row of the customer by a no-op update and returns the hash of its last record
+func (repo *RepositoryAuditCommandImpl) lockHead(ctx context.Context, customerID int64) (hash string, err error) {
+	l := logger.WorkLoggerWithContext(ctx).Named("lockHead")
+
+	lock := repo.DB.Builder().
+		Update("AUDIT_LOG_HEAD").
+		Set("LAST_ID", sq.Expr("LAST_ID")).
+		Where(sq.Eq{"CUSTOMER_ID": customerID})
+
+	err = storage.ExecAffected(ctx, l, lock.RunWith(repo.DB.Runner(ctx)))
+	if errors.Is(err, sql.ErrNoRows) {
+		create := repo.DB.Builder().
+			Insert("AUDIT_LOG_HEAD").
+			Columns("CUSTOMER_ID", "LAST_ID", "HASH").
+			Values(customerID, 0, "")
+		return "", storage.ExecAffected(ctx, l, create.RunWith(repo.DB.Runner(ctx)))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	q := repo.DB.Builder().
+		Select("HASH").
+		From("AUDIT_LOG_HEAD").
+		Where(sq.Eq{"CUSTOMER_ID": customerID})
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		l.Error("ToSql", zap.Error(err))
+		return "", err
+	}
+
+	l.Debug("Info", zap.String("sql", query), zap.Any("args", args))
+
+	err = q.RunWith(repo.DB.Runner(ctx)).QueryRowContext(ctx).Scan(&hash)
+	return hash, err
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"github.com/internet-banking-ul/internal/storage"
+)
+
+type Repositories interface {
+	RepositoryAuditQuery
+	RepositoryAuditCommand
+}
+
+type RepositoriesImpl struct {
+	// inject db impl to RepositoriesImpl event the db is being used by the child struct impl
+	db *storage.DB
+	*RepositoryAuditQueryImpl
+	*RepositoryAuditCommandImpl
+}
+
+func NewAuditRepository(
+	db *storage.DB,
+) *RepositoriesImpl {
+	return &RepositoriesImpl{
+		db:                       db,
+		RepositoryAuditQueryImpl: newAuditQuery(db),
+		RepositoryAuditCommandImpl: &RepositoryAuditCommandImpl{
+			DB: db,
+		},
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/modules/audit/dto"
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	auditRepo "github.com/internet-banking-ul/internal/modules/audit/repositories"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+	"go.uber.org/zap"
+)
+
+// Entry is the change of an entity to record. Before is nil for auditModel.ActionCreate, After for ActionDelete.
+type Entry struct {
+	// CustomerID - the customer owning the entity, the records are listed by it; 0 for none
+	CustomerID int64
+	Entity     string
+	EntityID   string
+	Action     string
+	Before     interface{}
+	After      interface{}
+}
+
+// Recorder writes the audit trail, the modules call it in the WithinTx of the change,
+// so the record is kept exactly when the change is
+type Recorder interface {
+	Record(ctx context.Context, entry Entry) error
+}
+
+type AuditService interface {
+	Recorder
+	List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.AuditRecordListResponse, int64, string, error)
+	Verify(ctx context.Context) (dto.VerifyResponse, error)
+}
+
+type AuditServiceImpl struct {
+	AuditRepository auditRepo.Repositories
+	Now             func() time.Time
+}
+
+func NewAuditService(
+	db *storage.DB,
+) *AuditServiceImpl {
+	return &AuditServiceImpl{
+		AuditRepository: auditRepo.NewAuditRepository(db),
+		Now:             time.Now,
+	}
+}
+
+// Record appends the entry with the actor, the IP and the device of the request in ctx (see utils.ContextHolderKey).
+// An update without changed fields isn't recorded.
+func (s AuditServiceImpl) Record(ctx context.Context, entry Entry) error {
+	ctx, span := tracing.Start(ctx, "AuditService.Record")
+	defer span.End()
+
+	changes := auditModel.Diff(entry.Before, entry.After)
+	if entry.Action == auditModel.ActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	content, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("audit changes of %s %s: %w", entry.Entity, entry.EntityID, err)
+	}
+
+	record := auditModel.Record{
+		IP:        utils.ContextGetLocalIP(ctx),
+		Entity:    entry.Entity,
+		EntityID:  entry.EntityID,
+		Action:    entry.Action,
+		Changes:   string(content),
+		CreatedAt: s.Now(),
+	}
+	if entry.CustomerID > 0 {
+		record.CustomerID = sql.NullInt64{Int64: entry.CustomerID, Valid: true}
+	}
+	if userID, ok := utils.ContextGetCurrentUserID(ctx); ok {
+		record.ActorID = sql.NullInt64{Int64: int64(userID), Valid: true}
+	}
+	if deviceID, ok := utils.ContextGetDeviceID(ctx); ok {
+		record.DeviceID = deviceID
+	}
+
+	if err := s.AuditRepository.Append(ctx, &record); err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error append audit Record", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// List returns the records of the current customer (utils.ContextGetCurrentCompanyID)
+func (s AuditServiceImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.AuditRecordListResponse, int64, string, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.List")
+	defer span.End()
+
+	customerID, ok := utils.ContextGetCurrentCompanyID(ctx)
+	if !ok {
+		return nil, 0, "", apiErrors.ThrowError(apiErrors.AccessDenied)
+	}
+
+	recordList, count, nextCursor, err := s.AuditRepository.List(ctx, customerID, baseFilter)
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch audit RecordList from DB", zap.Error(err))
+		return nil, 0, "", err
+	}
+
+	return dto.CreateAuditRecordListResponse(recordList), count, nextCursor, nil
+}
+
+// errChainBroken stops the iteration of Verify at the first broken record
+var errChainBroken = errors.New("audit chain is broken")
+
+// Verify walks the trail of the current customer (utils.ContextGetCurrentCompanyID): every record has to carry
+// the hash of the previous one and its own hash has to match its fields, the head has to point at the last
+// record, so a deleted tail is found too
+func (s AuditServiceImpl) Verify(ctx context.Context) (dto.VerifyResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.Verify")
+	defer span.End()
+
+	customerID, ok := utils.ContextGetCurrentCompanyID(ctx)
+	if !ok {
+		return dto.VerifyResponse{}, apiErrors.ThrowError(apiErrors.AccessDenied)
+	}
+
+	var (
+		resp     = dto.VerifyResponse{Valid: true}
+		lastID   int64
+		lastHash string
+	)
+
+	broken := func(id int64, reason string) error {
+		resp.Valid = false
+		resp.BrokenID = &id
+		resp.Reason = reason
+		return errChainBroken
+	}
+
+	err := s.AuditRepository.EachRecord(ctx, customerID, func(record auditModel.Record) error {
+		resp.Checked++
+		if record.PrevHash != lastHash {
+			return broken(record.ID, "the previous record is changed or deleted")
+		}
+		if record.ComputeHash() != record.Hash {
+			return broken(record.ID, "the record is changed")
+		}
+		lastID, lastHash = record.ID, record.Hash
+		return nil
+	})
+	if errors.Is(err, errChainBroken) {
+		logger.WorkLoggerWithContext(ctx).Warn("Audit chain is broken", zap.Int64("id", *resp.BrokenID), zap.String("reason", resp.Reason))
+		return resp, nil
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error verify audit chain", zap.Error(err))
+		return resp, err
+	}
+
+	headID, headHash, err := s.AuditRepository.Head(ctx, customerID)
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch audit chain head", zap.Error(err))
+		return resp, err
+	}
+	if headID != lastID || headHash != lastHash {
+		_ = broken(lastID, "the records after the last one are deleted")
+		logger.WorkLoggerWithContext(ctx).Warn("Audit chain is broken", zap.Int64("id", lastID), zap.String("reason", resp.Reason))
+	}
+
+	return resp, nil
+}
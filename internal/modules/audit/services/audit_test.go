@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type payment struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func TestAuditServiceImpl(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	s := NewAuditService(db)
+	s.Now = func() time.Time { return time.Date(2022, 6, 15, 10, 0, 0, 123456789, time.UTC) }
+
+	ctx := context.WithValue(context.Background(), utils.ContextHolderKey, &sync.Map{})
+	utils.SetAttribute(ctx, utils.AttributeCurrentUserID, int32(100))
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyID, int64(10))
+	utils.SetAttribute(ctx, utils.AttributeDeviceID, "device-1")
+	utils.SetAttribute(ctx, utils.AttributeXForwardedFor, "10.0.0.1, 192.168.0.1")
+
+	record := func(customerID int64, action string, before, after *payment) {
+		entry := Entry{CustomerID: customerID, Entity: auditModel.EntityPayment, EntityID: "1", Action: action}
+		if before != nil {
+			entry.Before = before
+		}
+		if after != nil {
+			entry.After = after
+		}
+		require.NoError(t, db.WithinTx(ctx, func(ctx context.Context) error {
+			return s.Record(ctx, entry)
+		}))
+	}
+
+	draft := &payment{ID: 1, Status: "DRAFT"}
+	signed := &payment{ID: 1, Status: "SIGNED"}
+	record(10, auditModel.ActionCreate, nil, draft)
+	record(10, auditModel.ActionUpdate, draft, draft) // nothing changed, not recorded
+	record(10, auditModel.ActionUpdate, draft, signed)
+	record(20, auditModel.ActionDelete, signed, nil)
+
+	// a rolled back change leaves no record
+	_ = db.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, s.Record(ctx, Entry{CustomerID: 10, Entity: auditModel.EntityPayment, EntityID: "2", Action: auditModel.ActionCreate, After: draft}))
+		return sql.ErrTxDone
+	})
+
+	list, count, _, err := s.List(ctx, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	require.Len(t, list, 2)
+	assert.Equal(t, auditModel.ActionCreate, list[0].Action)
+	require.NotNil(t, list[1].ActorID)
+	assert.Equal(t, int64(100), *list[1].ActorID)
+	assert.Equal(t, "10.0.0.1", list[1].IP)
+	assert.Equal(t, "device-1", list[1].DeviceID)
+	assert.Equal(t, auditModel.ChangeList{{Field: "status", Before: "DRAFT", After: "SIGNED"}}, list[1].Changes)
+
+	// every customer has its own chain
+	result, err := s.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, int64(2), result.Checked)
+
+	otherCtx := context.WithValue(context.Background(), utils.ContextHolderKey, &sync.Map{})
+	utils.SetAttribute(otherCtx, utils.AttributeCurrentCompanyID, int64(20))
+	result, err = s.Verify(otherCtx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, int64(1), result.Checked)
+
+	_, err = s.Verify(context.Background())
+	assert.Error(t, err, "no current company")
+
+	storagetest.Exec(t, db, `UPDATE AUDIT_LOG SET CHANGES = '[]' WHERE ID = 2`)
+	result, err = s.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.BrokenID)
+	assert.Equal(t, int64(2), *result.BrokenID)
+
+	result, err = s.Verify(otherCtx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, "the chain of another customer")
+}
+
+func TestAuditServiceImpl_VerifyDeleted(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	s := NewAuditService(db)
+	ctx := context.WithValue(context.Background(), utils.ContextHolderKey, &sync.Map{})
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyID, int64(10))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.WithinTx(ctx, func(ctx context.Context) error {
+			return s.Record(ctx, Entry{CustomerID: 10, Entity: auditModel.EntityPayment, EntityID: "1", Action: auditModel.ActionCreate, After: payment{ID: 1}})
+		}))
+	}
+
+	storagetest.Exec(t, db, `DELETE FROM AUDIT_LOG WHERE ID = 3`)
+	result, err := s.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid, "the deleted tail")
+
+	storagetest.Exec(t, db, `DELETE FROM AUDIT_LOG WHERE ID = 1`)
+	result, err = s.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.BrokenID)
+	assert.Equal(t, int64(2), *result.BrokenID)
+}
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/internet-banking-ul/internal/modules/company_person/entities"
@@ -45,3 +46,74 @@ func CreateCompanyPersonListResponse(companyPersonList entities.CompanyPersonLis
 	}
 	return companyPersonListResp
 }
+
+// CompanyPersonCreateRequest - attaches the user account to the company
+type CompanyPersonCreateRequest struct {
+	ExternalID       string     `json:"externalID"`
+	CompanyID        int64      `json:"companyID"`
+	UserAccountID    int64      `json:"userAccountID"`
+	ManagerID        *int64     `json:"managerID"`
+	ValidFrom        *time.Time `json:"validFrom"`
+	ValidTo          *time.Time `json:"validTo"`
+	SignLevel        string     `json:"signLevel"`
+	OrganizationRole string     `json:"organizationRole"`
+}
+
+func (r CompanyPersonCreateRequest) CompanyPerson() entities.CompanyPerson {
+	return entities.CompanyPerson{
+		ExternalID:       strings.TrimSpace(r.ExternalID),
+		CompanyID:        r.CompanyID,
+		UserAccountID:    r.UserAccountID,
+		ManagerID:        nullID(r.ManagerID),
+		ValidFrom:        nullTime(r.ValidFrom),
+		ValidTo:          nullTime(r.ValidTo),
+		SignLevel:        strings.TrimSpace(r.SignLevel),
+		OrganizationRole: strings.TrimSpace(r.OrganizationRole),
+	}
+}
+
+// CompanyPersonPatchRequest - changes the role, the sign level or the manager, managerID 0 removes the manager
+type CompanyPersonPatchRequest struct {
+	ManagerID        *int64  `json:"managerID"`
+	SignLevel        *string `json:"signLevel"`
+	OrganizationRole *string `json:"organizationRole"`
+}
+
+// Apply copies the present fields into companyPerson
+func (r CompanyPersonPatchRequest) Apply(companyPerson *entities.CompanyPerson) {
+	if r.ManagerID != nil {
+		companyPerson.ManagerID = nullID(r.ManagerID)
+	}
+	if r.SignLevel != nil {
+		companyPerson.SignLevel = strings.TrimSpace(*r.SignLevel)
+	}
+	if r.OrganizationRole != nil {
+		companyPerson.OrganizationRole = strings.TrimSpace(*r.OrganizationRole)
+	}
+}
+
+// CompanyPersonValidityRequest - replaces the validity period, an omitted bound is open
+type CompanyPersonValidityRequest struct {
+	ValidFrom *time.Time `json:"validFrom"`
+	ValidTo   *time.Time `json:"validTo"`
+}
+
+func (r CompanyPersonValidityRequest) Apply(companyPerson *entities.CompanyPerson) {
+	companyPerson.ValidFrom = nullTime(r.ValidFrom)
+	companyPerson.ValidTo = nullTime(r.ValidTo)
+}
+
+func nullID(id *int64) sql.NullInt64 {
+	if id == nil || *id == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *id, Valid: true}
+}
+
+// nullTime stores every bound in UTC, so the periods compare the same way in every dialect
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil || t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
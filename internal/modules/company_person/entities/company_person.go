@@ -2,8 +2,27 @@ package entities
 
 import (
 	"database/sql"
+	"time"
+
+	"github.com/internet-banking-ul/tools"
+)
+
+const (
+	SignLevelA    = "A"
+	SignLevelB    = "B"
+	SignLevelNone = "NONE"
 )
 
+// SignLevels - allowed values of CompanyPerson.SignLevel
+var SignLevels = []string{SignLevelA, SignLevelB, SignLevelNone}
+
+// RoleAdmin - the OrganizationRole managing the company persons of its company
+const RoleAdmin = "ADMIN"
+
+// RoleBackOffice - the OrganizationRole of the bank operators managing the customers. It is reserved:
+// the API doesn't assign it, the bank provisions these persons itself.
+const RoleBackOffice = "BACK_OFFICE"
+
 type CompanyPerson struct {
 	ID               int64         `db:"ID" json:"id"`
 	IsDeleted        int           `db:"IS_DELETED" json:"is_deleted"`
@@ -18,3 +37,49 @@ type CompanyPerson struct {
 }
 
 type CompanyPersonList []*CompanyPerson
+
+// CompanyIDs returns the distinct companies of the list in the order of the list
+func (l CompanyPersonList) CompanyIDs() []int64 {
+	ids := make([]int64, 0, len(l))
+	for _, p := range l {
+		ids = append(ids, p.CompanyID)
+	}
+	return tools.RemoveDuplicatesInt64(ids)
+}
+
+// ByCompanyID returns the persons of the company
+func (l CompanyPersonList) ByCompanyID(companyID int64) CompanyPersonList {
+	result := CompanyPersonList{}
+	for _, p := range l {
+		if p.CompanyID == companyID {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// ActiveAt reports whether the assignment is not revoked and t is within [ValidFrom, ValidTo),
+// an empty bound is open
+func (p CompanyPerson) ActiveAt(t time.Time) bool {
+	if p.IsDeleted != 0 {
+		return false
+	}
+	if p.ValidFrom.Valid && t.Before(p.ValidFrom.Time) {
+		return false
+	}
+	return !p.ValidTo.Valid || t.Before(p.ValidTo.Time)
+}
+
+// Covers reports whether the validity period of the assignment contains the whole period of other
+func (p CompanyPerson) Covers(other CompanyPerson) bool {
+	startsBefore := !p.ValidFrom.Valid || other.ValidFrom.Valid && !other.ValidFrom.Time.Before(p.ValidFrom.Time)
+	endsAfter := !p.ValidTo.Valid || other.ValidTo.Valid && !other.ValidTo.Time.After(p.ValidTo.Time)
+	return startsBefore && endsAfter
+}
+
+// Overlaps reports whether the validity periods [ValidFrom, ValidTo) of both assignments intersect
+func (p CompanyPerson) Overlaps(other CompanyPerson) bool {
+	startsBeforeOtherEnds := !p.ValidFrom.Valid || !other.ValidTo.Valid || p.ValidFrom.Time.Before(other.ValidTo.Time)
+	otherStartsBeforeEnds := !other.ValidFrom.Valid || !p.ValidTo.Valid || other.ValidFrom.Time.Before(p.ValidTo.Time)
+	return startsBeforeOtherEnds && otherStartsBeforeEnds
+}
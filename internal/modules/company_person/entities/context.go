@@ -0,0 +1,16 @@
+package entities
+
+import (
+	"context"
+
+	"github.com/internet-banking-ul/internal/utils"
+)
+
+// ContextGetCurrentCompanyPersons returns the active assignments of the caller in the current company,
+// set by the authorization middlewares (see middles.Require)
+func ContextGetCurrentCompanyPersons(ctx context.Context) (CompanyPersonList, bool) {
+	if result, ok := utils.GetAttribute(ctx, utils.AttributeCurrentCompanyPersons).(CompanyPersonList); ok {
+		return result, true
+	}
+	return nil, false
+}
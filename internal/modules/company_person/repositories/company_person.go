@@ -2,182 +2,121 @@ package repositories
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
+	"time"
 
+	"github.com/internet-banking-ul/internal/consts"
 	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
 	"github.com/internet-banking-ul/internal/modules/entities"
-	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/internal/modules/repository"
+	"github.com/internet-banking-ul/internal/storage"
 	sq "github.com/internet-banking-ul/modules/squirrel"
-	"go.uber.org/zap"
+	"github.com/internet-banking-ul/tools"
 )
 
 type RepositoryCompanyPersonQuery interface {
-	Count(ctx context.Context) (count int64, err error)
-	List(context.Context, entities.BasePaginationFilters) (companyPersonModel.CompanyPersonList, int64, error)
-	ByCustomerID(ctx context.Context, customerID int64) (result companyPersonModel.CompanyPerson, err error)
+	Count(ctx context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (count int64, err error)
+	List(ctx context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (companyPersonModel.CompanyPersonList, int64, string, error)
+	ListByCustomerID(ctx context.Context, customerID int64) (results companyPersonModel.CompanyPersonList, err error)
+	ByID(ctx context.Context, id int64) (result companyPersonModel.CompanyPerson, err error)
+	ByCustomerIDs(ctx context.Context, customerIDs []int64) (results map[int64]companyPersonModel.CompanyPersonList, err error)
+	ActiveByUserAccountID(ctx context.Context, userAccountID int64, at time.Time) (results companyPersonModel.CompanyPersonList, err error)
 }
 
-type RepositoryCompanyPersonQueryImpl struct {
-	DB *sql.DB
+// companyPersonListColumns - the sort and search whitelist of List
+var companyPersonListColumns = entities.ListColumns{
+	Sortable: map[string]string{
+		"id":               "ID",
+		"externalID":       "EXTERNAL_ID",
+		"companyID":        "COMPANY_ID",
+		"userAccountID":    "USER_ACCOUNT_ID",
+		"validFrom":        "VALID_FROM",
+		"validTo":          "VALID_TO",
+		"signLevel":        "SIGN_LEVEL",
+		"organizationRole": "ORGANIZATION_ROLE",
+	},
+	DefaultSort: "id",
+	Key:         "ID",
+	Searchable:  []string{"EXTERNAL_ID", "SIGN_LEVEL", "ORGANIZATION_ROLE"},
+	Nullable:    []string{"VALID_FROM", "VALID_TO"},
+	Filterable: map[string]entities.FilterField{
+		"companyID":        {Column: "COMPANY_ID", Type: entities.FilterInt, Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"userAccountID":    {Column: "USER_ACCOUNT_ID", Type: entities.FilterInt, Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"signLevel":        {Column: "SIGN_LEVEL", Ops: []string{entities.FilterEq, entities.FilterNe, entities.FilterIn}},
+		"organizationRole": {Column: "ORGANIZATION_ROLE"},
+		"validFrom":        {Column: "VALID_FROM", Type: entities.FilterTime},
+		"validTo":          {Column: "VALID_TO", Type: entities.FilterTime},
+		"validAt": {
+			Type:  entities.FilterTime,
+			Ops:   []string{entities.FilterEq},
+			Build: validAtPredicate,
+		},
+	},
 }
 
-func (repo *RepositoryCompanyPersonQueryImpl) ByCustomerID(ctx context.Context, customerID int64) (result companyPersonModel.CompanyPerson, err error) {
-	if repo.DB == nil {
-		err = fmt.Errorf("db is nil")
-		return result, err
-	}
-
-	l := logger.WorkLoggerWithContext(ctx).Named("List")
-
-	q := sq.
-		Select([]string{
-			"ID",
-			"IS_DELETED",
-			"EXTERNAL_ID",
-			"COMPANY_ID",
-			"USER_ACCOUNT_ID",
-			"MANAGER_ID",
-			"VALID_FROM",
-			"VALID_TO",
-			"SIGN_LEVEL",
-			"ORGANIZATION_ROLE",
-		}...).
-		From("COMPANY_PERSON").
-		Where(sq.Eq{"COMPANY_ID": customerID}).
-		PlaceholderFormat(sq.Colon)
-
-	sql, args, e := q.ToSql()
-	if e != nil {
-		l.Error("ToSql", zap.Error(e))
-		return result, err
+// validAtPredicate selects the assignments active at the time (see CompanyPerson.ActiveAt), an empty bound is open
+func validAtPredicate(_ string, values []interface{}) sq.Sqlizer {
+	at := values[0]
+	return sq.And{
+		sq.Eq{"IS_DELETED": 0},
+		sq.Or{sq.Eq{"VALID_FROM": nil}, sq.LtOrEq{"VALID_FROM": at}},
+		sq.Or{sq.Eq{"VALID_TO": nil}, sq.Gt{"VALID_TO": at}},
 	}
-
-	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
-
-	err = q.RunWith(repo.DB).QueryRowContext(ctx).Scan(
-		&result.ID,
-		&result.IsDeleted,
-		&result.ExternalID,
-		&result.CompanyID,
-		&result.UserAccountID,
-		&result.ManagerID,
-		&result.ValidFrom,
-		&result.ValidTo,
-		&result.SignLevel,
-		&result.OrganizationRole,
-	)
-	if err != nil {
-		l.Error("QueryRowContext", zap.Error(err))
-		return
-	}
-
-	return
 }
 
-func (repo *RepositoryCompanyPersonQueryImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (results companyPersonModel.CompanyPersonList, count int64, err error) {
-	if repo.DB == nil {
-		err = fmt.Errorf("db is nil")
-		return results, count, err
-	}
-
-	l := logger.WorkLoggerWithContext(ctx).Named("List")
-
-	count, err = repo.Count(ctx)
-	if err != nil {
-		l.Error("Count", zap.Error(err))
-		return results, count, err
-	}
-
-	q := sq.
-		Select([]string{
-			"ID",
-			"IS_DELETED",
-			"EXTERNAL_ID",
-			"COMPANY_ID",
-			"USER_ACCOUNT_ID",
-			"MANAGER_ID",
-			"VALID_FROM",
-			"VALID_TO",
-			"SIGN_LEVEL",
-			"ORGANIZATION_ROLE",
-		}...).
-		From("COMPANY_PERSON").
-		Offset(baseFilter.GetOffset()).
-		Limit(baseFilter.GetSize()).
-		PlaceholderFormat(sq.Colon)
+type RepositoryCompanyPersonQueryImpl struct {
+	*repository.Repository[companyPersonModel.CompanyPerson]
+}
 
-	sql, args, e := q.ToSql()
-	if e != nil {
-		l.Error("ToSql", zap.Error(e))
-		return results, count, err
+func newCompanyPersonQuery(db *storage.DB) *RepositoryCompanyPersonQueryImpl {
+	return &RepositoryCompanyPersonQueryImpl{
+		Repository: repository.New[companyPersonModel.CompanyPerson](db, "COMPANY_PERSON", companyPersonListColumns, nil),
 	}
+}
 
-	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
-
-	rows, err := q.RunWith(repo.DB).QueryContext(ctx)
-	if err != nil {
-		l.Error("QueryContext", zap.Error(err))
-		return results, count, err
-	}
-	defer rows.Close()
+// ByID returns sql.ErrNoRows when the company person doesn't exist or is revoked
+func (repo *RepositoryCompanyPersonQueryImpl) ByID(ctx context.Context, id int64) (result companyPersonModel.CompanyPerson, err error) {
+	return repo.GetBy(ctx, sq.Eq{"ID": id, "IS_DELETED": 0})
+}
 
-	results = companyPersonModel.CompanyPersonList{}
-	for rows.Next() {
-		row := new(companyPersonModel.CompanyPerson)
-		if err := rows.Scan(
-			&row.ID,
-			&row.IsDeleted,
-			&row.ExternalID,
-			&row.CompanyID,
-			&row.UserAccountID,
-			&row.ManagerID,
-			&row.ValidFrom,
-			&row.ValidTo,
-			&row.SignLevel,
-			&row.OrganizationRole,
-		); err != nil {
-			l.Error("Scan", zap.Error(err))
-			return results, count, err
+// ByCustomerIDs returns the active company persons of the customers grouped by customer ID and ordered by ID.
+// A customer without company persons has no key in results, an error is only returned when the query fails.
+// The IDs are queried consts.MaxInList at a time.
+func (repo *RepositoryCompanyPersonQueryImpl) ByCustomerIDs(ctx context.Context, customerIDs []int64) (results map[int64]companyPersonModel.CompanyPersonList, err error) {
+	results = make(map[int64]companyPersonModel.CompanyPersonList, len(customerIDs))
+	for _, chunk := range tools.Chunk(tools.RemoveDuplicatesInt64(customerIDs), consts.MaxInList) {
+		list, err := repo.FindBy(ctx, sq.Eq{"COMPANY_ID": chunk, "IS_DELETED": 0}, "COMPANY_ID", "ID")
+		if err != nil {
+			return results, err
 		}
 
-		results = append(results, row)
-	}
-
-	if err := rows.Close(); err != nil {
-		return results, count, err
-	}
-
-	if err := rows.Err(); err != nil {
-		return results, count, err
+		for _, row := range list {
+			results[row.CompanyID] = append(results[row.CompanyID], row)
+		}
 	}
 
-	return results, count, err
+	return results, nil
 }
 
-func (repo *RepositoryCompanyPersonQueryImpl) Count(ctx context.Context) (count int64, err error) {
-	if repo.DB == nil {
-		err = fmt.Errorf("db is nil")
-		return
-	}
-
-	l := logger.WorkLoggerWithContext(ctx).Named("Count")
-
-	q := sq.Select("COUNT(1)").From("COMPANY_PERSON").PlaceholderFormat(sq.Colon)
-
-	sql, args, e := q.ToSql()
-	if e != nil {
-		l.Error("ToSql", zap.Error(e))
-		return
-	}
+// ActiveByUserAccountID returns the assignments of the user active at the time in every company, ordered by COMPANY_ID, ID
+func (repo *RepositoryCompanyPersonQueryImpl) ActiveByUserAccountID(ctx context.Context, userAccountID int64, at time.Time) (companyPersonModel.CompanyPersonList, error) {
+	return repo.FindBy(ctx, sq.And{
+		sq.Eq{"USER_ACCOUNT_ID": userAccountID},
+		validAtPredicate("", []interface{}{at.UTC()}),
+	}, "COMPANY_ID", "ID")
+}
 
-	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
+// ListByCustomerID returns the company persons of the customer (company) but the revoked ones, ordered by ID
+func (repo *RepositoryCompanyPersonQueryImpl) ListByCustomerID(ctx context.Context, customerID int64) (companyPersonModel.CompanyPersonList, error) {
+	return repo.FindBy(ctx, sq.Eq{"COMPANY_ID": customerID, "IS_DELETED": 0}, "ID")
+}
 
-	err = q.RunWith(repo.DB).QueryRowContext(ctx).Scan(&count)
-	if err != nil {
-		l.Error("QueryRowContext", zap.Error(err))
-		return
-	}
+// List returns the page of the company persons of the company selected by baseFilter, the total of the filtered
+// company persons and the cursor of the next page ("" on the last page)
+func (repo *RepositoryCompanyPersonQueryImpl) List(ctx context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (companyPersonModel.CompanyPersonList, int64, string, error) {
+	return repo.Repository.List(ctx, baseFilter, sq.Eq{"COMPANY_ID": companyID})
+}
 
-	return
+// Count returns the number of the company persons of the company matching the search and the filters of baseFilter
+func (repo *RepositoryCompanyPersonQueryImpl) Count(ctx context.Context, companyID int64, baseFilter entities.BasePaginationFilters) (count int64, err error) {
+	return repo.Repository.Count(ctx, baseFilter, sq.Eq{"COMPANY_ID": companyID})
 }
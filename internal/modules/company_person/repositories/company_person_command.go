@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+const companyPersonSequence = "COMPANY_PERSON_SEQ"
+
+// RepositoryCompanyPersonCommand - writes, every method runs in the transaction of ctx started by the service (storage.DB.Runner)
+type RepositoryCompanyPersonCommand interface {
+	Create(ctx context.Context, companyPerson *companyPersonModel.CompanyPerson) error
+	Update(ctx context.Context, companyPerson companyPersonModel.CompanyPerson) error
+	SoftDelete(ctx context.Context, id int64) error
+}
+
+// companyPersonColumns - the columns of Create in the order of its values
+var companyPersonColumns = []string{
+	"ID",
+	"IS_DELETED",
+	"EXTERNAL_ID",
+	"COMPANY_ID",
+	"USER_ACCOUNT_ID",
+	"MANAGER_ID",
+	"VALID_FROM",
+	"VALID_TO",
+	"SIGN_LEVEL",
+	"ORGANIZATION_ROLE",
+}
+
+type RepositoryCompanyPersonCommandImpl struct {
+	DB *storage.DB
+}
+
+// Create takes the next ID from COMPANY_PERSON_SEQ, inserts the company person and sets companyPerson.ID
+func (repo *RepositoryCompanyPersonCommandImpl) Create(ctx context.Context, companyPerson *companyPersonModel.CompanyPerson) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Create")
+
+	id, err := repo.DB.NextID(ctx, companyPersonSequence, "COMPANY_PERSON")
+	if err != nil {
+		l.Error("NextID", zap.Error(err))
+		return err
+	}
+
+	q := repo.DB.Builder().
+		Insert("COMPANY_PERSON").
+		Columns(companyPersonColumns...).
+		Values(
+			id,
+			0,
+			companyPerson.ExternalID,
+			companyPerson.CompanyID,
+			companyPerson.UserAccountID,
+			companyPerson.ManagerID,
+			companyPerson.ValidFrom,
+			companyPerson.ValidTo,
+			companyPerson.SignLevel,
+			companyPerson.OrganizationRole,
+		)
+
+	if err = storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return err
+	}
+
+	companyPerson.ID = id
+	return nil
+}
+
+// Update overwrites the mutable columns of a not revoked company person,
+// returns sql.ErrNoRows when there is nothing to update. Company and user account never change.
+func (repo *RepositoryCompanyPersonCommandImpl) Update(ctx context.Context, companyPerson companyPersonModel.CompanyPerson) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Update")
+
+	q := repo.DB.Builder().
+		Update("COMPANY_PERSON").
+		SetMap(map[string]interface{}{
+			"EXTERNAL_ID":       companyPerson.ExternalID,
+			"MANAGER_ID":        companyPerson.ManagerID,
+			"VALID_FROM":        companyPerson.ValidFrom,
+			"VALID_TO":          companyPerson.ValidTo,
+			"SIGN_LEVEL":        companyPerson.SignLevel,
+			"ORGANIZATION_ROLE": companyPerson.OrganizationRole,
+		}).
+		Where(sq.Eq{"ID": companyPerson.ID, "IS_DELETED": 0})
+
+	return storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx)))
+}
+
+// SoftDelete revokes the company person, returns sql.ErrNoRows when it is already revoked
+func (repo *RepositoryCompanyPersonCommandImpl) SoftDelete(ctx context.Context, id int64) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("SoftDelete")
+
+	q := repo.DB.Builder().
+		Update("COMPANY_PERSON").
+		Set("IS_DELETED", 1).
+		Where(sq.Eq{"ID": id, "IS_DELETED": 0})
+
+	return storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx)))
+}
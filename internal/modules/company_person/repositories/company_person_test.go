@@ -0,0 +1,225 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/internet-banking-ul/internal/consts"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryCompanyPersonQueryImpl(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	repo := NewCompanyPersonRepository(db)
+	validFrom := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+		VALUES (10, 'LEGAL', 'c-10', 'Company', 'Company LLP', 'TOO', '17', '123456789012')`)
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, MANAGER_ID, VALID_FROM, VALID_TO, SIGN_LEVEL, ORGANIZATION_ROLE)
+		VALUES (1, 'cp-1', 10, 100, NULL, ?, NULL, 'A', 'DIRECTOR')`, validFrom)
+
+	ctx := context.Background()
+
+	list, count, _, err := repo.List(ctx, 10, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, list, 1)
+
+	list, count, _, err = repo.List(ctx, 11, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count, "the persons of another company")
+	assert.Empty(t, list)
+
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, IS_DELETED, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, SIGN_LEVEL, ORGANIZATION_ROLE)
+		VALUES (2, 1, 'cp-2', 10, 101, 'B', 'ACCOUNTANT')`)
+
+	persons, err := repo.ListByCustomerID(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, persons, 1, "the revoked person is left out")
+	person := persons[0]
+	assert.Equal(t, int64(100), person.UserAccountID)
+	assert.True(t, person.ValidFrom.Valid)
+	assert.True(t, validFrom.Equal(person.ValidFrom.Time))
+	assert.False(t, person.ValidTo.Valid)
+	assert.False(t, person.ManagerID.Valid)
+	assert.Equal(t, "A", person.SignLevel)
+
+	persons, err = repo.ListByCustomerID(ctx, 11)
+	require.NoError(t, err)
+	assert.Empty(t, persons)
+}
+
+func TestRepositoryCompanyPersonQueryImpl_ByCustomerIDs(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	repo := NewCompanyPersonRepository(db)
+
+	for _, id := range []int{10, 11, 12} {
+		storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+			VALUES (?, 'LEGAL', ?, 'Company', 'Company LLP', 'TOO', '17', '123456789012')`, id, id)
+	}
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, IS_DELETED, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, SIGN_LEVEL, ORGANIZATION_ROLE) VALUES
+		(1, 0, 'cp-1', 10, 100, 'A', 'DIRECTOR'),
+		(2, 0, 'cp-2', 11, 101, 'A', 'DIRECTOR'),
+		(3, 0, 'cp-3', 10, 102, 'B', 'ACCOUNTANT'),
+		(4, 1, 'cp-4', 12, 103, 'B', 'ACCOUNTANT')`)
+
+	// more IDs than fit into one IN list
+	ids := make([]int64, 0, consts.MaxInList+2)
+	for id := int64(1000); len(ids) < consts.MaxInList; id++ {
+		ids = append(ids, id)
+	}
+	ids = append(ids, 10, 11, 12, 13)
+
+	persons, err := repo.ByCustomerIDs(context.Background(), ids)
+	require.NoError(t, err)
+
+	require.Len(t, persons, 2)
+	require.Len(t, persons[10], 2)
+	assert.Equal(t, int64(1), persons[10][0].ID)
+	assert.Equal(t, int64(3), persons[10][1].ID)
+	require.Len(t, persons[11], 1)
+	assert.NotContains(t, persons, int64(12))
+	assert.NotContains(t, persons, int64(13))
+
+	persons, err = repo.ByCustomerIDs(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, persons)
+}
+
+func TestRepositoryCompanyPersonQueryImpl_ListCursor(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	repo := NewCompanyPersonRepository(db)
+	ctx := context.Background()
+
+	storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+		VALUES (10, 'LEGAL', 'c-10', 'Company', 'Company LLP', 'TOO', '17', '123456789012')`)
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, SIGN_LEVEL, ORGANIZATION_ROLE) VALUES
+		(1, 'cp-1', 10, 102, 'A', 'DIRECTOR'),
+		(2, 'cp-2', 10, 101, 'B', 'ACCOUNTANT'),
+		(3, 'cp-3', 10, 101, 'B', 'ACCOUNTANT')`)
+
+	filter := entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 2, Sort: "userAccountID"}}
+	list, _, next, err := repo.List(ctx, 10, filter)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(2), list[0].ID)
+	assert.Equal(t, int64(3), list[1].ID)
+	require.NotEmpty(t, next)
+
+	filter.Cursor = next
+	list, _, next, err = repo.List(ctx, 10, filter)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(1), list[0].ID)
+	assert.Empty(t, next)
+
+	// VALID_FROM is nullable, the NULLs come after the dates and span the pages
+	storagetest.Exec(t, db, `UPDATE COMPANY_PERSON SET VALID_FROM = ? WHERE ID = 2`, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, SIGN_LEVEL, ORGANIZATION_ROLE)
+		VALUES (4, 'cp-4', 10, 103, 'B', 'ACCOUNTANT')`)
+
+	walk := func(order string, size uint64) (ids []int64) {
+		filter := entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: size, Sort: "validFrom", Order: order}}
+		for {
+			list, _, next, err := repo.List(ctx, 10, filter)
+			require.NoError(t, err)
+			for _, person := range list {
+				ids = append(ids, person.ID)
+			}
+			if next == "" {
+				return ids
+			}
+			filter.Cursor = next
+		}
+	}
+
+	for _, size := range []uint64{1, 2, 3} {
+		assert.Equal(t, []int64{2, 1, 3, 4}, walk(entities.OrderAsc, size), size)
+		assert.Equal(t, []int64{4, 3, 1, 2}, walk(entities.OrderDesc, size), size)
+	}
+}
+
+func TestRepositoryCompanyPersonQueryImpl_ListFilters(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	repo := NewCompanyPersonRepository(db)
+	ctx := context.Background()
+	jan := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+		VALUES (10, 'LEGAL', 'c-10', 'Company', 'Company LLP', 'TOO', '17', '123456789012')`)
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, VALID_FROM, VALID_TO, SIGN_LEVEL, ORGANIZATION_ROLE) VALUES
+		(1, 'cp-1', 10, 100, ?, ?, 'A', 'DIRECTOR'),
+		(2, 'cp-2', 10, 101, ?, NULL, 'B', 'ACCOUNTANT'),
+		(3, 'cp-3', 10, 102, NULL, NULL, 'NONE', 'EMPLOYEE')`, jan, jul, jul)
+
+	list := func(filters ...[3]string) []int64 {
+		var filter entities.BasePaginationFilters
+		filter.Size = 10
+		for _, f := range filters {
+			filter.AddFilter(f[0], f[1], f[2])
+		}
+
+		persons, count, _, err := repo.List(ctx, 10, filter)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(persons)), count)
+
+		var ids []int64
+		for _, p := range persons {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int64{1, 2}, list([3]string{"signLevel", "in", "A,B"}))
+	assert.Equal(t, []int64{1, 3}, list([3]string{"validAt", "eq", "2022-03-01"}))
+	assert.Equal(t, []int64{2, 3}, list([3]string{"validAt", "eq", "2022-07-01"}))
+	assert.Equal(t, []int64{2}, list([3]string{"validFrom", "gte", "2022-02-01"}))
+	assert.Equal(t, []int64{1}, list([3]string{"organizationRole", "like", "direct"}, [3]string{"companyId", "eq", "10"}))
+
+	var filter entities.BasePaginationFilters
+	filter.AddFilter("managerID", "eq", "1")
+	_, _, _, err := repo.List(ctx, 10, filter)
+	assert.Error(t, err)
+}
+
+func TestRepositoryCompanyPersonQueryImpl_ActiveByUserAccountID(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	repo := NewCompanyPersonRepository(db)
+	jan := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, id := range []int{10, 11} {
+		storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+			VALUES (?, 'LEGAL', ?, 'Company', 'Company LLP', 'TOO', '17', '123456789012')`, id, id)
+	}
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, IS_DELETED, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, VALID_FROM, VALID_TO, SIGN_LEVEL, ORGANIZATION_ROLE) VALUES
+		(1, 0, 'cp-1', 11, 100, NULL, NULL, 'A', 'ADMIN'),
+		(2, 0, 'cp-2', 10, 100, ?, ?, 'B', 'ACCOUNTANT'),
+		(3, 1, 'cp-3', 10, 100, NULL, NULL, 'A', 'DIRECTOR'),
+		(4, 0, 'cp-4', 10, 100, ?, NULL, 'A', 'DIRECTOR'),
+		(5, 0, 'cp-5', 10, 101, NULL, NULL, 'A', 'DIRECTOR')`, jan, jul, jul)
+
+	ctx := context.Background()
+
+	persons, err := repo.ActiveByUserAccountID(ctx, 100, jan.AddDate(0, 2, 0))
+	require.NoError(t, err)
+	require.Len(t, persons, 2)
+	assert.Equal(t, int64(2), persons[0].ID)
+	assert.Equal(t, int64(1), persons[1].ID)
+	assert.Equal(t, []int64{10, 11}, persons.CompanyIDs())
+
+	// VALID_TO is exclusive and VALID_FROM inclusive
+	persons, err = repo.ActiveByUserAccountID(ctx, 100, jul)
+	require.NoError(t, err)
+	require.Len(t, persons, 2)
+	assert.Equal(t, int64(4), persons[0].ID)
+
+	persons, err = repo.ActiveByUserAccountID(ctx, 102, jul)
+	require.NoError(t, err)
+	assert.Empty(t, persons)
+}
@@ -1,25 +1,28 @@
 package repositories
 
 import (
-	"database/sql"
+	"github.com/internet-banking-ul/internal/storage"
 )
 
 type Repositories interface {
 	RepositoryCompanyPersonQuery
+	RepositoryCompanyPersonCommand
 }
 
 type RepositoriesImpl struct {
 	// inject db impl to RepositoriesImpl event the db is being used by the child struct impl
-	db *sql.DB
+	db *storage.DB
 	*RepositoryCompanyPersonQueryImpl
+	*RepositoryCompanyPersonCommandImpl
 }
 
 func NewCompanyPersonRepository(
-	db *sql.DB,
+	db *storage.DB,
 ) *RepositoriesImpl {
 	return &RepositoriesImpl{
-		db: db,
-		RepositoryCompanyPersonQueryImpl: &RepositoryCompanyPersonQueryImpl{
+		db:                               db,
+		RepositoryCompanyPersonQueryImpl: newCompanyPersonQuery(db),
+		RepositoryCompanyPersonCommandImpl: &RepositoryCompanyPersonCommandImpl{
 			DB: db,
 		},
 	}
@@ -3,42 +3,289 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strconv"
 
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	auditService "github.com/internet-banking-ul/internal/modules/audit/services"
 	"github.com/internet-banking-ul/internal/modules/company_person/dto"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
 	companyPersonRepo "github.com/internet-banking-ul/internal/modules/company_person/repositories"
 	customerRepo "github.com/internet-banking-ul/internal/modules/customer/repositories"
 	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/internal/utils"
 	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+	"go.uber.org/zap"
 )
 
 type CompanyPersonService interface {
-	List(context.Context, entities.BasePaginationFilters) (dto.CompanyPersonListResponse, int64, error)
+	List(context.Context, entities.BasePaginationFilters) (dto.CompanyPersonListResponse, int64, string, error)
+	ByID(ctx context.Context, id int64) (*dto.CompanyPersonResponse, error)
+	Attach(ctx context.Context, req dto.CompanyPersonCreateRequest) (*dto.CompanyPersonResponse, error)
+	Patch(ctx context.Context, id int64, req dto.CompanyPersonPatchRequest) (*dto.CompanyPersonResponse, error)
+	SetValidity(ctx context.Context, id int64, req dto.CompanyPersonValidityRequest) (*dto.CompanyPersonResponse, error)
+	Revoke(ctx context.Context, id int64) error
 }
 
 type CompanyPersonServiceImpl struct {
+	DB                      *storage.DB
 	CompanyPersonRepository companyPersonRepo.Repositories
 	CustomerRepository      customerRepo.Repositories
+	// Audit records every change of an assignment in its transaction
+	Audit auditService.Recorder
 }
 
 func NewCompanyPersonService(
-	db *sql.DB,
+	db *storage.DB,
 ) *CompanyPersonServiceImpl {
 	return &CompanyPersonServiceImpl{
+		DB:                      db,
 		CustomerRepository:      customerRepo.NewCustomerRepository(db),
 		CompanyPersonRepository: companyPersonRepo.NewCompanyPersonRepository(db),
+		Audit:                   auditService.NewAuditService(db),
 	}
 }
 
-func (s CompanyPersonServiceImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.CompanyPersonListResponse, int64, error) {
-	companyPersonList, count, err := s.CompanyPersonRepository.List(ctx, baseFilter)
+func (s CompanyPersonServiceImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.CompanyPersonListResponse, int64, string, error) {
+	ctx, span := tracing.Start(ctx, "CompanyPersonService.List")
+	defer span.End()
+
+	companyID, err := currentCompanyID(ctx)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	companyPersonList, count, nextCursor, err := s.CompanyPersonRepository.List(ctx, companyID, baseFilter)
 	if err != nil {
 		logger.WorkLoggerWithContext(ctx).Error("Error fetch CompanyPersonList from DB")
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 
 	if companyPersonList == nil {
-		return nil, 0, err
+		return nil, 0, "", err
+	}
+
+	return dto.CreateCompanyPersonListResponse(companyPersonList), count, nextCursor, nil
+}
+
+// ByID returns apiErrors.CompanyPersonNotFound when there is no such active company person in the current company
+func (s CompanyPersonServiceImpl) ByID(ctx context.Context, id int64) (*dto.CompanyPersonResponse, error) {
+	ctx, span := tracing.Start(ctx, "CompanyPersonService.ByID")
+	defer span.End()
+
+	companyID, err := currentCompanyID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	companyPerson, err := s.byID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if companyPerson.CompanyID != companyID {
+		return nil, apiErrors.ThrowError(apiErrors.CompanyPersonNotFound)
+	}
+
+	resp := dto.CreateCompanyPersonResponse(companyPerson)
+	return &resp, nil
+}
+
+// Attach validates and inserts the new assignment of the user to the company
+func (s CompanyPersonServiceImpl) Attach(ctx context.Context, req dto.CompanyPersonCreateRequest) (*dto.CompanyPersonResponse, error) {
+	ctx, span := tracing.Start(ctx, "CompanyPersonService.Attach")
+	defer span.End()
+
+	companyPerson := req.CompanyPerson()
+
+	if err := checkCurrentCompany(ctx, companyPerson.CompanyID); err != nil {
+		return nil, err
+	}
+
+	err := s.DB.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.validate(ctx, companyPerson); err != nil {
+			return err
+		}
+
+		if err := s.CompanyPersonRepository.Create(ctx, &companyPerson); err != nil {
+			return err
+		}
+		return s.Audit.Record(ctx, auditEntry(auditModel.ActionCreate, nil, &companyPerson))
+	})
+	if apiErrors.ParseError(err) != nil {
+		return nil, err
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error create CompanyPerson", zap.Error(err))
+		return nil, err
+	}
+
+	return s.ByID(ctx, companyPerson.ID)
+}
+
+// Patch changes the role, the sign level or the manager
+func (s CompanyPersonServiceImpl) Patch(ctx context.Context, id int64, req dto.CompanyPersonPatchRequest) (*dto.CompanyPersonResponse, error) {
+	ctx, span := tracing.Start(ctx, "CompanyPersonService.Patch")
+	defer span.End()
+
+	return s.update(ctx, id, req.Apply)
+}
+
+// SetValidity replaces the validity period
+func (s CompanyPersonServiceImpl) SetValidity(ctx context.Context, id int64, req dto.CompanyPersonValidityRequest) (*dto.CompanyPersonResponse, error) {
+	ctx, span := tracing.Start(ctx, "CompanyPersonService.SetValidity")
+	defer span.End()
+
+	return s.update(ctx, id, req.Apply)
+}
+
+func (s CompanyPersonServiceImpl) update(ctx context.Context, id int64, apply func(*companyPersonModel.CompanyPerson)) (*dto.CompanyPersonResponse, error) {
+	companyPerson, err := s.byID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkCurrentCompany(ctx, companyPerson.CompanyID); err != nil {
+		return nil, err
+	}
+
+	err = s.DB.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.CustomerRepository.LockByID(ctx, companyPerson.CompanyID); err != nil {
+			return err
+		}
+		// read again under the lock of the company, a concurrent change may have been committed meanwhile
+		before, err := s.byID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		companyPerson := before
+		apply(&companyPerson)
+		if err := s.validate(ctx, companyPerson); err != nil {
+			return err
+		}
+
+		if err := s.CompanyPersonRepository.Update(ctx, companyPerson); err != nil {
+			return err
+		}
+		return s.Audit.Record(ctx, auditEntry(auditModel.ActionUpdate, &before, &companyPerson))
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apiErrors.ThrowError(apiErrors.CompanyPersonNotFound)
+	}
+	if apiErrors.ParseError(err) != nil {
+		return nil, err
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error update CompanyPerson", zap.Error(err))
+		return nil, err
+	}
+
+	return s.ByID(ctx, id)
+}
+
+// Revoke soft deletes the company person, apiErrors.CompanyPersonManages while other active persons
+// have it as their manager
+func (s CompanyPersonServiceImpl) Revoke(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "CompanyPersonService.Revoke")
+	defer span.End()
+
+	err := s.DB.WithinTx(ctx, func(ctx context.Context) error {
+		companyPerson, err := s.byID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkCurrentCompany(ctx, companyPerson.CompanyID); err != nil {
+			return err
+		}
+
+		// the validation of the concurrent changes of the company doesn't see the revoked manager otherwise
+		if err := s.CustomerRepository.LockByID(ctx, companyPerson.CompanyID); err != nil {
+			return err
+		}
+		// read again under the lock of the company, a concurrent change may have been committed meanwhile
+		if companyPerson, err = s.byID(ctx, id); err != nil {
+			return err
+		}
+
+		persons, err := s.CompanyPersonRepository.ListByCustomerID(ctx, companyPerson.CompanyID)
+		if err != nil {
+			return err
+		}
+		for _, other := range persons {
+			if other.ManagerID.Valid && other.ManagerID.Int64 == id {
+				return apiErrors.ThrowError(apiErrors.CompanyPersonManages)
+			}
+		}
+
+		if err := s.CompanyPersonRepository.SoftDelete(ctx, id); err != nil {
+			return err
+		}
+		return s.Audit.Record(ctx, auditEntry(auditModel.ActionDelete, &companyPerson, nil))
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return apiErrors.ThrowError(apiErrors.CompanyPersonNotFound)
+	}
+	if apiErrors.ParseError(err) != nil {
+		return err
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error revoke CompanyPerson", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// auditEntry - the change of the assignment, before is nil for the attached one, after for the revoked one
+func auditEntry(action string, before, after *companyPersonModel.CompanyPerson) auditService.Entry {
+	entry := auditService.Entry{
+		Entity: auditModel.EntityCompanyPerson,
+		Action: action,
+	}
+	if before != nil {
+		entry.Before = before
+		entry.CustomerID, entry.EntityID = before.CompanyID, strconv.FormatInt(before.ID, 10)
+	}
+	if after != nil {
+		entry.After = after
+		entry.CustomerID, entry.EntityID = after.CompanyID, strconv.FormatInt(after.ID, 10)
+	}
+	return entry
+}
+
+// checkCurrentCompany returns apiErrors.AccessDenied unless the caller acts for companyID
+func checkCurrentCompany(ctx context.Context, companyID int64) error {
+	currentID, err := currentCompanyID(ctx)
+	if err != nil {
+		return err
+	}
+	if currentID != companyID {
+		return apiErrors.ThrowError(apiErrors.AccessDenied)
+	}
+	return nil
+}
+
+// currentCompanyID is the company the caller acts for, set by the authorization middlewares (see middles.Require).
+// A request without it is denied.
+func currentCompanyID(ctx context.Context) (int64, error) {
+	companyID, ok := utils.ContextGetCurrentCompanyID(ctx)
+	if !ok {
+		return 0, apiErrors.ThrowError(apiErrors.AccessDenied)
+	}
+	return companyID, nil
+}
+
+func (s CompanyPersonServiceImpl) byID(ctx context.Context, id int64) (companyPersonModel.CompanyPerson, error) {
+	companyPerson, err := s.CompanyPersonRepository.ByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return companyPerson, apiErrors.ThrowError(apiErrors.CompanyPersonNotFound)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch CompanyPerson from DB", zap.Error(err))
 	}
 
-	return dto.CreateCompanyPersonListResponse(companyPersonList), count, nil
+	return companyPerson, err
 }
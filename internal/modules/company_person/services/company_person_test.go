@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/modules/company_person/dto"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireFieldError(t *testing.T, err error, field, code string) {
+	t.Helper()
+
+	apiErr := apiErrors.ParseError(err)
+	require.NotNil(t, apiErr, "%v", err)
+	require.Len(t, apiErr.Fields, 1, "%v", apiErr.Fields)
+	assert.Equal(t, field, apiErr.Fields[0].Field)
+	assert.Equal(t, code, apiErr.Fields[0].Code)
+}
+
+// companyCtx is the context of a caller acting for the company, see middles.Require
+func companyCtx(companyID int64) context.Context {
+	ctx := context.WithValue(context.Background(), utils.ContextHolderKey, &sync.Map{})
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyID, companyID)
+	return ctx
+}
+
+func TestCompanyPersonServiceImpl_Lifecycle(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	for _, id := range []int{10, 20} {
+		storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+			VALUES (?, 'LEGAL', ?, 'Company', 'Company LLP', 'TOO', '17', '050140000120')`, id, id)
+	}
+
+	s := NewCompanyPersonService(db)
+	ctx, foreignCtx := companyCtx(10), companyCtx(20)
+	jan := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	director, err := s.Attach(ctx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-1", CompanyID: 10, UserAccountID: 100, ValidFrom: &jan, ValidTo: &jul,
+		SignLevel: "A", OrganizationRole: "DIRECTOR",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), director.ID)
+
+	_, err = s.Attach(ctx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-2", CompanyID: 10, UserAccountID: 100, ValidFrom: &jan,
+		SignLevel: "B", OrganizationRole: "ACCOUNTANT",
+	})
+	requireFieldError(t, err, "validFrom", apiErrors.FieldOverlaps)
+
+	// the next period starts when the previous one ends
+	_, err = s.Attach(ctx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-2", CompanyID: 10, UserAccountID: 100, ValidFrom: &jul,
+		SignLevel: "B", OrganizationRole: "ACCOUNTANT",
+	})
+	require.NoError(t, err)
+
+	// the back-office role is provisioned by the bank only
+	_, err = s.Attach(ctx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-8", CompanyID: 10, UserAccountID: 800, SignLevel: "NONE", OrganizationRole: "back_office",
+	})
+	requireFieldError(t, err, "organizationRole", apiErrors.FieldInvalidEnum)
+
+	_, err = s.SetValidity(ctx, director.ID, dto.CompanyPersonValidityRequest{ValidFrom: &jul, ValidTo: &jan})
+	requireFieldError(t, err, "validTo", apiErrors.FieldInvalidRange)
+
+	foreign, err := s.Attach(foreignCtx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-3", CompanyID: 20, UserAccountID: 300, SignLevel: "A", OrganizationRole: "DIRECTOR",
+	})
+	require.NoError(t, err)
+
+	_, err = s.Patch(ctx, director.ID, dto.CompanyPersonPatchRequest{ManagerID: &foreign.ID})
+	requireFieldError(t, err, "managerID", apiErrors.FieldForeignCompany)
+
+	// the manager has to be valid for the whole period of the person
+	manager, err := s.Attach(ctx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-6", CompanyID: 10, UserAccountID: 600, ValidTo: &jul, SignLevel: "NONE", OrganizationRole: "MANAGER",
+	})
+	require.NoError(t, err)
+	_, err = s.Attach(ctx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-7", CompanyID: 10, UserAccountID: 700, ManagerID: &manager.ID, ValidFrom: &jan,
+		SignLevel: "NONE", OrganizationRole: "EMPLOYEE",
+	})
+	requireFieldError(t, err, "managerID", apiErrors.FieldInactive)
+	employee, err := s.Attach(ctx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-7", CompanyID: 10, UserAccountID: 700, ManagerID: &manager.ID, ValidFrom: &jan, ValidTo: &jul,
+		SignLevel: "NONE", OrganizationRole: "EMPLOYEE",
+	})
+	require.NoError(t, err)
+
+	// the manager stays until the employee has another one
+	assert.Equal(t, apiErrors.CompanyPersonManages, apiErrors.ParseError(s.Revoke(ctx, manager.ID)).Id)
+	require.NoError(t, s.Revoke(ctx, employee.ID))
+	require.NoError(t, s.Revoke(ctx, manager.ID))
+
+	// the caller acting for company 20 can't see or change the persons of company 10
+	_, err = s.ByID(foreignCtx, director.ID)
+	assert.Equal(t, apiErrors.CompanyPersonNotFound, apiErrors.ParseError(err).Id)
+	list, count, _, err := s.List(foreignCtx, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, foreign.ID, list[0].ID)
+	_, err = s.Attach(foreignCtx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-5", CompanyID: 10, UserAccountID: 500, SignLevel: "A", OrganizationRole: "DIRECTOR",
+	})
+	assert.Equal(t, apiErrors.AccessDenied, apiErrors.ParseError(err).Id)
+	_, err = s.Patch(foreignCtx, director.ID, dto.CompanyPersonPatchRequest{})
+	assert.Equal(t, apiErrors.AccessDenied, apiErrors.ParseError(err).Id)
+	assert.Equal(t, apiErrors.AccessDenied, apiErrors.ParseError(s.Revoke(foreignCtx, director.ID)).Id)
+
+	// no current company, no access
+	_, err = s.Attach(context.Background(), dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-5", CompanyID: 10, UserAccountID: 500, SignLevel: "A", OrganizationRole: "DIRECTOR",
+	})
+	assert.Equal(t, apiErrors.AccessDenied, apiErrors.ParseError(err).Id)
+	_, err = s.ByID(context.Background(), director.ID)
+	assert.Equal(t, apiErrors.AccessDenied, apiErrors.ParseError(err).Id)
+
+	signLevel := "B"
+	updated, err := s.Patch(ctx, director.ID, dto.CompanyPersonPatchRequest{SignLevel: &signLevel})
+	require.NoError(t, err)
+	assert.Equal(t, "B", updated.SignLevel)
+	assert.Equal(t, "DIRECTOR", updated.OrganizationRole)
+
+	require.NoError(t, s.Revoke(ctx, director.ID))
+	_, err = s.ByID(ctx, director.ID)
+	assert.Equal(t, apiErrors.CompanyPersonNotFound, apiErrors.ParseError(err).Id)
+
+	// a revoked assignment no longer blocks the period
+	_, err = s.Attach(ctx, dto.CompanyPersonCreateRequest{
+		ExternalID: "cp-4", CompanyID: 10, UserAccountID: 100, ValidFrom: &jan, ValidTo: &jul,
+		SignLevel: "A", OrganizationRole: "DIRECTOR",
+	})
+	require.NoError(t, err)
+}
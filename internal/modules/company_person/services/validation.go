@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+	"github.com/internet-banking-ul/tools"
+)
+
+// max lengths of the COMPANY_PERSON columns
+const (
+	externalIDMaxLength       = 64
+	organizationRoleMaxLength = 64
+)
+
+// validateCompanyPerson checks the fields of the assignment about to be written
+func validateCompanyPerson(companyPerson companyPersonModel.CompanyPerson) apiErrors.FieldErrors {
+	var fields apiErrors.FieldErrors
+
+	if companyPerson.ExternalID == "" {
+		fields.Add("externalID", apiErrors.FieldRequired, "externalID is required")
+	} else if utf8.RuneCountInString(companyPerson.ExternalID) > externalIDMaxLength {
+		fields.Add("externalID", apiErrors.FieldTooLong, fmt.Sprintf("externalID must be at most %d characters", externalIDMaxLength))
+	}
+
+	if companyPerson.CompanyID <= 0 {
+		fields.Add("companyID", apiErrors.FieldRequired, "companyID is required")
+	}
+	if companyPerson.UserAccountID <= 0 {
+		fields.Add("userAccountID", apiErrors.FieldRequired, "userAccountID is required")
+	}
+
+	if companyPerson.SignLevel == "" {
+		fields.Add("signLevel", apiErrors.FieldRequired, "signLevel is required")
+	} else if !tools.StringInSlice(companyPersonModel.SignLevels, companyPerson.SignLevel) {
+		fields.Add("signLevel", apiErrors.FieldInvalidEnum,
+			fmt.Sprintf("signLevel must be one of %s", strings.Join(companyPersonModel.SignLevels, ", ")))
+	}
+
+	if companyPerson.OrganizationRole == "" {
+		fields.Add("organizationRole", apiErrors.FieldRequired, "organizationRole is required")
+	} else if utf8.RuneCountInString(companyPerson.OrganizationRole) > organizationRoleMaxLength {
+		fields.Add("organizationRole", apiErrors.FieldTooLong, fmt.Sprintf("organizationRole must be at most %d characters", organizationRoleMaxLength))
+	} else if strings.EqualFold(companyPerson.OrganizationRole, companyPersonModel.RoleBackOffice) {
+		// the policies compare the roles case insensitive
+		fields.Add("organizationRole", apiErrors.FieldInvalidEnum, fmt.Sprintf("organizationRole %s is reserved for the bank", companyPersonModel.RoleBackOffice))
+	}
+
+	if companyPerson.ValidFrom.Valid && companyPerson.ValidTo.Valid && !companyPerson.ValidTo.Time.After(companyPerson.ValidFrom.Time) {
+		fields.Add("validTo", apiErrors.FieldInvalidRange, "validTo must be after validFrom")
+	}
+
+	if companyPerson.ManagerID.Valid && companyPerson.ManagerID.Int64 == companyPerson.ID {
+		fields.Add("managerID", apiErrors.FieldInvalidFormat, "company person can't be its own manager")
+	}
+
+	return fields
+}
+
+// validate checks the fields and the rules that need the database: the company exists, the manager is
+// a person of the same company valid for the whole validity period and the user has no other assignment
+// in the company overlapping it. It runs in the transaction writing the assignment and locks the company
+// row, so the checks of the concurrent changes of the company's persons run one after another.
+func (s CompanyPersonServiceImpl) validate(ctx context.Context, companyPerson companyPersonModel.CompanyPerson) error {
+	fields := validateCompanyPerson(companyPerson)
+	if len(fields) > 0 {
+		return fields.Err()
+	}
+
+	if err := s.CustomerRepository.LockByID(ctx, companyPerson.CompanyID); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		fields.Add("companyID", apiErrors.FieldNotFound, "company not found")
+		return fields.Err()
+	}
+
+	if companyPerson.ManagerID.Valid {
+		manager, err := s.CompanyPersonRepository.ByID(ctx, companyPerson.ManagerID.Int64)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			fields.Add("managerID", apiErrors.FieldNotFound, "manager not found")
+		case err != nil:
+			return err
+		case manager.CompanyID != companyPerson.CompanyID:
+			fields.Add("managerID", apiErrors.FieldForeignCompany, "manager must belong to the same company")
+		case !manager.Covers(companyPerson):
+			fields.Add("managerID", apiErrors.FieldInactive, "manager must be valid for the whole validity period")
+		}
+	}
+
+	persons, err := s.CompanyPersonRepository.ListByCustomerID(ctx, companyPerson.CompanyID)
+	if err != nil {
+		return err
+	}
+	for _, other := range persons {
+		if other.ID == companyPerson.ID || other.UserAccountID != companyPerson.UserAccountID {
+			continue
+		}
+		if companyPerson.Overlaps(*other) {
+			fields.Add("validFrom", apiErrors.FieldOverlaps,
+				fmt.Sprintf("user already has an assignment in the company for this period (company person %d)", other.ID))
+			break
+		}
+	}
+
+	return fields.Err()
+}
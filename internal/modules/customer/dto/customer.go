@@ -1,7 +1,11 @@
 package dto
 
 import (
+	"strings"
+
+	"github.com/guregu/null"
 	companyPersonDTO "github.com/internet-banking-ul/internal/modules/company_person/dto"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
 	customerModel "github.com/internet-banking-ul/internal/modules/customer/entities"
 )
 
@@ -16,12 +20,14 @@ type CustomerResponse struct {
 	ResidencyAndEconomicCode string `json:"residencyAndEconomicCode"`
 	TaxCode                  string `json:"taxCode"`
 
-	CompanyPerson companyPersonDTO.CompanyPersonResponse `json:"companyPerson,omitempty"`
+	CompanyPerson  *companyPersonDTO.CompanyPersonResponse    `json:"companyPerson,omitempty"`
+	CompanyPersons companyPersonDTO.CompanyPersonListResponse `json:"companyPersons,omitempty"`
 }
 
+// CreateCustomerResponse - companyPerson is nil for the customer without company persons
 func CreateCustomerResponse(
 	customer customerModel.Customer,
-	companyPersonDTO companyPersonDTO.CompanyPersonResponse,
+	companyPerson *companyPersonDTO.CompanyPersonResponse,
 ) CustomerResponse {
 	return CustomerResponse{
 		ID:                       customer.ID,
@@ -33,8 +39,23 @@ func CreateCustomerResponse(
 		Ownership:                customer.Ownership,
 		ResidencyAndEconomicCode: customer.ResidencyAndEconomicCode,
 		TaxCode:                  customer.TaxCode,
-		CompanyPerson:            companyPersonDTO,
+		CompanyPerson:            companyPerson,
+	}
+}
+
+func createCompanyPersonResponse(companyPerson *companyPersonModel.CompanyPerson) *companyPersonDTO.CompanyPersonResponse {
+	if companyPerson == nil {
+		return nil
 	}
+	resp := companyPersonDTO.CreateCompanyPersonResponse(*companyPerson)
+	return &resp
+}
+
+// CreateCustomerDetailResponse - the customer with all its company persons
+func CreateCustomerDetailResponse(customer customerModel.Customer) CustomerResponse {
+	resp := CreateCustomerResponse(customer, createCompanyPersonResponse(customer.CompanyPerson))
+	resp.CompanyPersons = companyPersonDTO.CreateCompanyPersonListResponse(customer.CompanyPersons)
+	return resp
 }
 
 type CustomerListResponse []*CustomerResponse
@@ -42,8 +63,76 @@ type CustomerListResponse []*CustomerResponse
 func CreateCustomerListResponse(customers customerModel.CustomerList) CustomerListResponse {
 	customersResp := CustomerListResponse{}
 	for _, p := range customers {
-		customer := CreateCustomerResponse(*p, companyPersonDTO.CreateCompanyPersonResponse(p.CompanyPerson))
+		customer := CreateCustomerResponse(*p, createCompanyPersonResponse(p.CompanyPerson))
 		customersResp = append(customersResp, &customer)
 	}
 	return customersResp
 }
+
+// CustomerRequest - body of POST and PUT, PUT replaces every field
+type CustomerRequest struct {
+	PersonType               string  `json:"personType"`
+	ExternalID               string  `json:"externalID"`
+	Name                     string  `json:"name"`
+	FullName                 string  `json:"fullName"`
+	IntlName                 *string `json:"intlName"`
+	Ownership                string  `json:"ownership"`
+	ResidencyAndEconomicCode string  `json:"residencyAndEconomicCode"`
+	TaxCode                  string  `json:"taxCode"`
+}
+
+// Apply copies the request into customer, ID and IsDeleted stay untouched
+func (r CustomerRequest) Apply(customer *customerModel.Customer) {
+	customer.PersonType = strings.TrimSpace(r.PersonType)
+	customer.ExternalID = strings.TrimSpace(r.ExternalID)
+	customer.Name = strings.TrimSpace(r.Name)
+	customer.FullName = strings.TrimSpace(r.FullName)
+	customer.IntlName = null.StringFromPtr(r.IntlName)
+	customer.Ownership = strings.TrimSpace(r.Ownership)
+	customer.ResidencyAndEconomicCode = strings.TrimSpace(r.ResidencyAndEconomicCode)
+	customer.TaxCode = strings.TrimSpace(r.TaxCode)
+}
+
+// CustomerPatchRequest - body of PATCH, only the fields present in the body are changed, "intlName": null clears it
+type CustomerPatchRequest struct {
+	PersonType               *string        `json:"personType"`
+	ExternalID               *string        `json:"externalID"`
+	Name                     *string        `json:"name"`
+	FullName                 *string        `json:"fullName"`
+	IntlName                 NullableString `json:"intlName"`
+	Ownership                *string        `json:"ownership"`
+	ResidencyAndEconomicCode *string        `json:"residencyAndEconomicCode"`
+	TaxCode                  *string        `json:"taxCode"`
+}
+
+// NullableString is a PATCH field of a nullable column: absent from the body it's not Set,
+// null clears the column
+type NullableString struct {
+	Set   bool
+	Value null.String
+}
+
+func (s *NullableString) UnmarshalJSON(data []byte) error {
+	s.Set = true
+	return s.Value.UnmarshalJSON(data)
+}
+
+// Apply copies the present fields into customer
+func (r CustomerPatchRequest) Apply(customer *customerModel.Customer) {
+	patch := func(dst *string, src *string) {
+		if src != nil {
+			*dst = strings.TrimSpace(*src)
+		}
+	}
+
+	patch(&customer.PersonType, r.PersonType)
+	patch(&customer.ExternalID, r.ExternalID)
+	patch(&customer.Name, r.Name)
+	patch(&customer.FullName, r.FullName)
+	if r.IntlName.Set {
+		customer.IntlName = r.IntlName.Value
+	}
+	patch(&customer.Ownership, r.Ownership)
+	patch(&customer.ResidencyAndEconomicCode, r.ResidencyAndEconomicCode)
+	patch(&customer.TaxCode, r.TaxCode)
+}
@@ -5,8 +5,18 @@ import (
 	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
 )
 
+const (
+	PersonTypeIndividual   = "INDIVIDUAL"
+	PersonTypeEntrepreneur = "ENTREPRENEUR"
+	PersonTypeLegal        = "LEGAL"
+)
+
+// PersonTypes - allowed values of Customer.PersonType
+var PersonTypes = []string{PersonTypeIndividual, PersonTypeEntrepreneur, PersonTypeLegal}
+
 type Customer struct {
 	ID                       int64       `db:"ID" json:"id"`
+	IsDeleted                int         `db:"IS_DELETED" json:"is_deleted"`
 	PersonType               string      `db:"PERSON_TYPE" json:"person_type"`
 	ExternalID               string      `db:"EXTERNAL_ID" json:"external_id"`
 	Name                     string      `db:"NAME" json:"name"`
@@ -16,7 +26,14 @@ type Customer struct {
 	ResidencyAndEconomicCode string      `db:"RESIDENCY_AND_ECONOMIC_CODE" json:"residency_and_economic_code"`
 	TaxCode                  string      `db:"TAX_CODE" json:"tax_code"`
 
-	CompanyPerson companyPersonModel.CompanyPerson `db:"-" json:"company_person"`
+	// the company persons are audited by their own records, so they are left out of the customer ones
+	CompanyPerson  *companyPersonModel.CompanyPerson    `db:"-" json:"-"`
+	CompanyPersons companyPersonModel.CompanyPersonList `db:"-" json:"-"`
 }
 
 type CustomerList []*Customer
+
+// IsIndividual reports whether the tax code of the customer is an IIN (individuals and entrepreneurs), not a BIN
+func (c Customer) IsIndividual() bool {
+	return c.PersonType == PersonTypeIndividual || c.PersonType == PersonTypeEntrepreneur
+}
@@ -2,125 +2,64 @@ package repositories
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
 
 	customerModel "github.com/internet-banking-ul/internal/modules/customer/entities"
 	"github.com/internet-banking-ul/internal/modules/entities"
-	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/internal/modules/repository"
+	"github.com/internet-banking-ul/internal/storage"
 	sq "github.com/internet-banking-ul/modules/squirrel"
-	"go.uber.org/zap"
 )
 
 type RepositoryCustomerQuery interface {
-	List(context.Context, entities.BasePaginationFilters) (customerModel.CustomerList, int64, error)
+	List(context.Context, entities.BasePaginationFilters) (customerModel.CustomerList, int64, string, error)
+	ByID(ctx context.Context, id int64) (result customerModel.Customer, err error)
+	ByExternalID(ctx context.Context, externalID string) (result customerModel.Customer, err error)
+	LockByID(ctx context.Context, id int64) error
 }
 
-type RepositoryCustomerQueryImpl struct {
-	DB *sql.DB
+// customerListColumns - the sort and search whitelist of List
+var customerListColumns = entities.ListColumns{
+	Sortable: map[string]string{
+		"id":         "ID",
+		"personType": "PERSON_TYPE",
+		"externalID": "EXTERNAL_ID",
+		"name":       "NAME",
+		"fullName":   "FULL_NAME",
+		"taxCode":    "TAX_CODE",
+	},
+	DefaultSort: "id",
+	Key:         "ID",
+	Searchable:  []string{"EXTERNAL_ID", "NAME", "FULL_NAME", "INTL_NAME", "TAX_CODE"},
+	Filterable: map[string]entities.FilterField{
+		"personType": {Column: "PERSON_TYPE", Ops: []string{entities.FilterEq, entities.FilterNe, entities.FilterIn}},
+		"ownership":  {Column: "OWNERSHIP", Ops: []string{entities.FilterEq, entities.FilterNe, entities.FilterIn}},
+		"taxCode":    {Column: "TAX_CODE", Ops: []string{entities.FilterEq, entities.FilterIn, entities.FilterPrefix}},
+	},
 }
 
-func (repo *RepositoryCustomerQueryImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (results customerModel.CustomerList, count int64, err error) {
-	if repo.DB == nil {
-		err = fmt.Errorf("db is nil")
-		return results, count, err
-	}
-
-	l := logger.WorkLoggerWithContext(ctx).Named("List")
-
-	count, err = repo.Count(ctx)
-	if err != nil {
-		l.Error("Count", zap.Error(err))
-		return results, count, err
-	}
-
-	q := sq.
-		Select([]string{
-			"ID",
-			"PERSON_TYPE",
-			"EXTERNAL_ID",
-			"NAME",
-			"FULL_NAME",
-			"INTL_NAME",
-			"OWNERSHIP",
-			"RESIDENCY_AND_ECONOMIC_CODE",
-			"TAX_CODE",
-		}...).
-		From("CUSTOMER").
-		Offset(baseFilter.GetOffset()).
-		Limit(baseFilter.GetSize()).
-		PlaceholderFormat(sq.Colon)
-
-	sql, args, e := q.ToSql()
-	if e != nil {
-		l.Error("ToSql", zap.Error(e))
-		return results, count, err
-	}
-
-	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
-
-	rows, err := q.RunWith(repo.DB).QueryContext(ctx)
-	if err != nil {
-		l.Error("QueryContext", zap.Error(err))
-		return results, count, err
-	}
-	defer rows.Close()
-
-	results = customerModel.CustomerList{}
-	for rows.Next() {
-		row := new(customerModel.Customer)
-		if err := rows.Scan(
-			&row.ID,
-			&row.PersonType,
-			&row.ExternalID,
-			&row.Name,
-			&row.FullName,
-			&row.IntlName,
-			&row.Ownership,
-			&row.ResidencyAndEconomicCode,
-			&row.TaxCode,
-		); err != nil {
-			l.Error("Scan", zap.Error(err))
-			return results, count, err
-		}
-
-		results = append(results, row)
-	}
-
-	if err := rows.Close(); err != nil {
-		return results, count, err
-	}
-
-	if err := rows.Err(); err != nil {
-		return results, count, err
-	}
-
-	return results, count, err
+// RepositoryCustomerQueryImpl - the deleted customers are hidden
+type RepositoryCustomerQueryImpl struct {
+	*repository.Repository[customerModel.Customer]
 }
 
-func (repo *RepositoryCustomerQueryImpl) Count(ctx context.Context) (count int64, err error) {
-	if repo.DB == nil {
-		err = fmt.Errorf("db is nil")
-		return
-	}
-
-	l := logger.WorkLoggerWithContext(ctx).Named("Count")
-
-	q := sq.Select("COUNT(1)").From("CUSTOMER").PlaceholderFormat(sq.Colon)
-
-	sql, args, e := q.ToSql()
-	if e != nil {
-		l.Error("ToSql", zap.Error(e))
-		return
+func newCustomerQuery(db *storage.DB) *RepositoryCustomerQueryImpl {
+	return &RepositoryCustomerQueryImpl{
+		Repository: repository.New[customerModel.Customer](db, "CUSTOMER", customerListColumns, sq.Eq{"IS_DELETED": 0}),
 	}
+}
 
-	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
+// List returns the page of customers selected by baseFilter, the total of the filtered customers
+// and the cursor of the next page ("" on the last page)
+func (repo *RepositoryCustomerQueryImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (customerModel.CustomerList, int64, string, error) {
+	return repo.Repository.List(ctx, baseFilter)
+}
 
-	err = q.RunWith(repo.DB).QueryRowContext(ctx).Scan(&count)
-	if err != nil {
-		l.Error("QueryContext", zap.Error(err))
-		return
-	}
+// ByID returns sql.ErrNoRows when the customer doesn't exist or is deleted
+func (repo *RepositoryCustomerQueryImpl) ByID(ctx context.Context, id int64) (result customerModel.Customer, err error) {
+	return repo.GetByID(ctx, id)
+}
 
-	return
+// ByExternalID returns sql.ErrNoRows when the customer doesn't exist or is deleted
+func (repo *RepositoryCustomerQueryImpl) ByExternalID(ctx context.Context, externalID string) (result customerModel.Customer, err error) {
+	return repo.GetBy(ctx, sq.Eq{"EXTERNAL_ID": externalID})
 }
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	customerModel "github.com/internet-banking-ul/internal/modules/customer/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+const customerSequence = "CUSTOMER_SEQ"
+
+// RepositoryCustomerCommand - writes, every method runs in the transaction of ctx started by the service (storage.DB.Runner)
+type RepositoryCustomerCommand interface {
+	Create(ctx context.Context, customer *customerModel.Customer) error
+	Update(ctx context.Context, customer customerModel.Customer) error
+	SoftDelete(ctx context.Context, id int64) error
+}
+
+type RepositoryCustomerCommandImpl struct {
+	DB *storage.DB
+}
+
+// Create takes the next ID from CUSTOMER_SEQ, inserts the customer and sets customer.ID,
+// returns storage.ErrUniqueViolation when another customer has the external id
+func (repo *RepositoryCustomerCommandImpl) Create(ctx context.Context, customer *customerModel.Customer) (err error) {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Create")
+
+	id, err := repo.DB.NextID(ctx, customerSequence, "CUSTOMER")
+	if err != nil {
+		l.Error("NextID", zap.Error(err))
+		return err
+	}
+
+	q := repo.DB.Builder().
+		Insert("CUSTOMER").
+		Columns(
+			"ID",
+			"IS_DELETED",
+			"PERSON_TYPE",
+			"EXTERNAL_ID",
+			"NAME",
+			"FULL_NAME",
+			"INTL_NAME",
+			"OWNERSHIP",
+			"RESIDENCY_AND_ECONOMIC_CODE",
+			"TAX_CODE",
+		).
+		Values(
+			id,
+			0,
+			customer.PersonType,
+			customer.ExternalID,
+			customer.Name,
+			customer.FullName,
+			customer.IntlName,
+			customer.Ownership,
+			customer.ResidencyAndEconomicCode,
+			customer.TaxCode,
+		)
+
+	// a customer with the same external id breaks CUSTOMER_EXTERNAL_ID_UK
+	if err = storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return repo.DB.Dialect.UniqueViolation(err)
+	}
+
+	customer.ID = id
+	return nil
+}
+
+// Update overwrites every column of a not deleted customer, returns sql.ErrNoRows when there is nothing to update
+// and storage.ErrUniqueViolation when another customer has the external id
+func (repo *RepositoryCustomerCommandImpl) Update(ctx context.Context, customer customerModel.Customer) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Update")
+
+	q := repo.DB.Builder().
+		Update("CUSTOMER").
+		SetMap(map[string]interface{}{
+			"PERSON_TYPE":                 customer.PersonType,
+			"EXTERNAL_ID":                 customer.ExternalID,
+			"NAME":                        customer.Name,
+			"FULL_NAME":                   customer.FullName,
+			"INTL_NAME":                   customer.IntlName,
+			"OWNERSHIP":                   customer.Ownership,
+			"RESIDENCY_AND_ECONOMIC_CODE": customer.ResidencyAndEconomicCode,
+			"TAX_CODE":                    customer.TaxCode,
+		}).
+		Where(sq.Eq{"ID": customer.ID, "IS_DELETED": 0})
+
+	return repo.DB.Dialect.UniqueViolation(storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))))
+}
+
+// SoftDelete marks the customer and its company persons deleted, returns sql.ErrNoRows when there is nothing to delete
+func (repo *RepositoryCustomerCommandImpl) SoftDelete(ctx context.Context, id int64) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("SoftDelete")
+
+	q := repo.DB.Builder().
+		Update("CUSTOMER").
+		Set("IS_DELETED", 1).
+		Where(sq.Eq{"ID": id, "IS_DELETED": 0})
+	if err := storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return err
+	}
+
+	persons := repo.DB.Builder().
+		Update("COMPANY_PERSON").
+		Set("IS_DELETED", 1).
+		Where(sq.Eq{"COMPANY_ID": id, "IS_DELETED": 0})
+
+	query, args, e := persons.ToSql()
+	if e != nil {
+		l.Error("ToSql", zap.Error(e))
+		return e
+	}
+
+	l.Debug("Info", zap.String("sql", query), zap.Any("args", args))
+
+	if _, err := persons.RunWith(repo.DB.Runner(ctx)).ExecContext(ctx); err != nil {
+		l.Error("ExecContext", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
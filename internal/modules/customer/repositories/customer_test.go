@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	customerModel "github.com/internet-banking-ul/internal/modules/customer/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedCustomers(t *testing.T, repo *RepositoriesImpl, n int) {
+	for i := 1; i <= n; i++ {
+		storagetest.Exec(t, repo.DB,
+			`INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, INTL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+			 VALUES (?, 'LEGAL', ?, ?, ?, NULL, 'TOO', '17', '123456789012')`,
+			i, fmt.Sprintf("ext-%d", i), "Company", "Company LLP",
+		)
+	}
+}
+
+func TestRepositoryCustomerQueryImpl_List(t *testing.T) {
+	repo := NewCustomerRepository(storagetest.NewSQLite(t))
+	seedCustomers(t, repo, 3)
+
+	filter := entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Page: 1, Size: 2}}
+	list, count, _, err := repo.List(context.Background(), filter)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(3), list[0].ID)
+	assert.Equal(t, "LEGAL", list[0].PersonType)
+	assert.False(t, list[0].IntlName.Valid)
+}
+
+func TestRepositoryCustomerQueryImpl_ByID(t *testing.T) {
+	repo := NewCustomerRepository(storagetest.NewSQLite(t))
+	seedCustomers(t, repo, 2)
+	ctx := context.Background()
+
+	customer, err := repo.ByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "ext-2", customer.ExternalID)
+
+	customer, err = repo.ByExternalID(ctx, "ext-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), customer.ID)
+
+	_, err = repo.ByID(ctx, 42)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRepositoryCustomerCommandImpl(t *testing.T) {
+	repo := NewCustomerRepository(storagetest.NewSQLite(t))
+	seedCustomers(t, repo, 1)
+	ctx := context.Background()
+
+	customer := customerModel.Customer{
+		PersonType:               customerModel.PersonTypeLegal,
+		ExternalID:               "ext-new",
+		Name:                     "New",
+		FullName:                 "New LLP",
+		Ownership:                "TOO",
+		ResidencyAndEconomicCode: "17",
+		TaxCode:                  "050140000120",
+	}
+	err := repo.DB.WithinTx(ctx, func(ctx context.Context) error {
+		return repo.Create(ctx, &customer)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), customer.ID)
+	storagetest.Exec(t, repo.DB, `INSERT INTO COMPANY_PERSON (ID, IS_DELETED, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, SIGN_LEVEL, ORGANIZATION_ROLE)
+		VALUES (1, 0, 'cp-1', 2, 10, 'A', 'DIRECTOR')`)
+
+	customer.Name = "Renamed"
+	err = repo.DB.WithinTx(ctx, func(ctx context.Context) error {
+		return repo.Update(ctx, customer)
+	})
+	require.NoError(t, err)
+
+	stored, err := repo.ByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", stored.Name)
+
+	err = repo.DB.WithinTx(ctx, func(ctx context.Context) error {
+		return repo.SoftDelete(ctx, 2)
+	})
+	require.NoError(t, err)
+
+	_, err = repo.ByID(ctx, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	var deletedPersons int
+	require.NoError(t, repo.DB.QueryRow(`SELECT COUNT(1) FROM COMPANY_PERSON WHERE IS_DELETED = 1`).Scan(&deletedPersons))
+	assert.Equal(t, 1, deletedPersons)
+
+	err = repo.DB.WithinTx(ctx, func(ctx context.Context) error {
+		return repo.SoftDelete(ctx, 2)
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRepositoryCustomerQueryImpl_ListSearch(t *testing.T) {
+	repo := NewCustomerRepository(storagetest.NewSQLite(t))
+	seedCustomers(t, repo, 3)
+	storagetest.Exec(t, repo.DB, `UPDATE CUSTOMER SET NAME = 'Alpha 50% Off' WHERE ID = 2`)
+	storagetest.Exec(t, repo.DB, `UPDATE CUSTOMER SET NAME = 'Alpha 500' WHERE ID = 3`)
+
+	filter := entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10, SearchText: "alpha", Sort: "name", Order: "desc"}}
+	list, count, _, err := repo.List(context.Background(), filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(3), list[0].ID)
+	assert.Equal(t, int64(2), list[1].ID)
+
+	filter.SearchText = "50%"
+	list, count, _, err = repo.List(context.Background(), filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(2), list[0].ID)
+
+	filter.Sort = "password"
+	_, _, _, err = repo.List(context.Background(), filter)
+	assert.Error(t, err)
+}
+
+func TestRepositoryCustomerQueryImpl_ListCursor(t *testing.T) {
+	repo := NewCustomerRepository(storagetest.NewSQLite(t))
+	seedCustomers(t, repo, 5)
+	// two customers share the name, the ID breaks the tie
+	storagetest.Exec(t, repo.DB, `UPDATE CUSTOMER SET NAME = 'Same' WHERE ID IN (2, 4)`)
+	ctx := context.Background()
+
+	filter := entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 2, Sort: "name", Order: "desc"}}
+	var ids []int64
+	for page := 0; ; page++ {
+		require.Less(t, page, 5)
+
+		list, count, next, err := repo.List(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+		for _, c := range list {
+			ids = append(ids, c.ID)
+		}
+		if next == "" {
+			break
+		}
+		filter.Cursor = next
+	}
+	assert.Equal(t, []int64{4, 2, 5, 3, 1}, ids)
+
+	filter.Order = "asc"
+	_, _, _, err := repo.List(ctx, filter)
+	require.Error(t, err)
+
+	filter.Order = "desc"
+	filter.Cursor = filter.Cursor[:len(filter.Cursor)-2] + "xx"
+	_, _, _, err = repo.List(ctx, filter)
+	require.Error(t, err)
+}
+
+func TestRepositoryCustomerQueryImpl_ListFilters(t *testing.T) {
+	repo := NewCustomerRepository(storagetest.NewSQLite(t))
+	seedCustomers(t, repo, 3)
+	storagetest.Exec(t, repo.DB, `UPDATE CUSTOMER SET PERSON_TYPE = 'INDIVIDUAL', TAX_CODE = '900101300126' WHERE ID = 3`)
+
+	var filter entities.BasePaginationFilters
+	filter.Size = 10
+	filter.AddFilter("taxCode", "prefix", "9001")
+	filter.AddFilter("personType", "in", "INDIVIDUAL,ENTREPRENEUR")
+
+	list, count, _, err := repo.List(context.Background(), filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(3), list[0].ID)
+
+	filter.AddFilter("ownership", "like", "TO")
+	_, _, _, err = repo.List(context.Background(), filter)
+	assert.Error(t, err, "like is not allowed for ownership")
+}
@@ -1,25 +1,28 @@
 package repositories
 
 import (
-	"database/sql"
+	"github.com/internet-banking-ul/internal/storage"
 )
 
 type Repositories interface {
 	RepositoryCustomerQuery
+	RepositoryCustomerCommand
 }
 
 type RepositoriesImpl struct {
 	// inject db impl to RepositoriesImpl event the db is being used by the child struct impl
-	DB *sql.DB
+	DB *storage.DB
 	*RepositoryCustomerQueryImpl
+	*RepositoryCustomerCommandImpl
 }
 
 func NewCustomerRepository(
-	db *sql.DB,
+	db *storage.DB,
 ) *RepositoriesImpl {
 	return &RepositoriesImpl{
-		DB: db,
-		RepositoryCustomerQueryImpl: &RepositoryCustomerQueryImpl{
+		DB:                          db,
+		RepositoryCustomerQueryImpl: newCustomerQuery(db),
+		RepositoryCustomerCommandImpl: &RepositoryCustomerCommandImpl{
 			DB: db,
 		},
 	}
@@ -3,52 +3,257 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strconv"
 
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	auditService "github.com/internet-banking-ul/internal/modules/audit/services"
 	companyPersonRepo "github.com/internet-banking-ul/internal/modules/company_person/repositories"
 	"github.com/internet-banking-ul/internal/modules/customer/dto"
+	customerModel "github.com/internet-banking-ul/internal/modules/customer/entities"
 	customerRepo "github.com/internet-banking-ul/internal/modules/customer/repositories"
 	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage"
 	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+	"go.uber.org/zap"
 )
 
 type CustomerService interface {
-	List(context.Context, entities.BasePaginationFilters) (dto.CustomerListResponse, int64, error)
+	List(context.Context, entities.BasePaginationFilters) (dto.CustomerListResponse, int64, string, error)
+	ByID(ctx context.Context, id int64) (*dto.CustomerResponse, error)
+	ByExternalID(ctx context.Context, externalID string) (*dto.CustomerResponse, error)
+	Create(ctx context.Context, req dto.CustomerRequest) (*dto.CustomerResponse, error)
+	Update(ctx context.Context, id int64, req dto.CustomerRequest) (*dto.CustomerResponse, error)
+	Patch(ctx context.Context, id int64, req dto.CustomerPatchRequest) (*dto.CustomerResponse, error)
+	Delete(ctx context.Context, id int64) error
 }
 
 type CustomerServiceImpl struct {
+	DB                      *storage.DB
 	CustomerRepository      customerRepo.Repositories
 	CompanyPersonRepository companyPersonRepo.Repositories
+	// Audit records every change in its transaction
+	Audit auditService.Recorder
 }
 
 func NewCustomerService(
-	db *sql.DB,
+	db *storage.DB,
 ) *CustomerServiceImpl {
 	return &CustomerServiceImpl{
+		DB:                      db,
 		CustomerRepository:      customerRepo.NewCustomerRepository(db),
 		CompanyPersonRepository: companyPersonRepo.NewCompanyPersonRepository(db),
+		Audit:                   auditService.NewAuditService(db),
 	}
 }
 
-func (s CustomerServiceImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.CustomerListResponse, int64, error) {
-	customerList, count, err := s.CustomerRepository.List(ctx, baseFilter)
+func (s CustomerServiceImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.CustomerListResponse, int64, string, error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.List")
+	defer span.End()
+
+	customerList, count, nextCursor, err := s.CustomerRepository.List(ctx, baseFilter)
 	if err != nil {
 		logger.WorkLoggerWithContext(ctx).Error("Error fetch CustomerList from DB")
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 
 	if customerList == nil {
-		return nil, 0, err
+		return nil, 0, "", err
+	}
+
+	customerIDs := make([]int64, 0, len(customerList))
+	for _, customer := range customerList {
+		customerIDs = append(customerIDs, customer.ID)
+	}
+
+	companyPersons, err := s.CompanyPersonRepository.ByCustomerIDs(ctx, customerIDs)
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch CompanyPerson from DB", zap.Error(err))
+		return nil, 0, "", err
+	}
+
+	// a customer without company persons has no CompanyPerson
+	for _, customer := range customerList {
+		if persons := companyPersons[customer.ID]; len(persons) > 0 {
+			customer.CompanyPerson = persons[0]
+		}
+	}
+
+	return dto.CreateCustomerListResponse(customerList), count, nextCursor, nil
+}
+
+// ByID returns apiErrors.CustomerNotFound when there is no such customer
+func (s CustomerServiceImpl) ByID(ctx context.Context, id int64) (*dto.CustomerResponse, error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.ByID")
+	defer span.End()
+
+	customer, err := s.CustomerRepository.ByID(ctx, id)
+	return s.detail(ctx, customer, err)
+}
+
+// ByExternalID returns apiErrors.CustomerNotFound when there is no such customer
+func (s CustomerServiceImpl) ByExternalID(ctx context.Context, externalID string) (*dto.CustomerResponse, error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.ByExternalID")
+	defer span.End()
+
+	customer, err := s.CustomerRepository.ByExternalID(ctx, externalID)
+	return s.detail(ctx, customer, err)
+}
+
+func (s CustomerServiceImpl) detail(ctx context.Context, customer customerModel.Customer, err error) (*dto.CustomerResponse, error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apiErrors.ThrowError(apiErrors.CustomerNotFound)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch Customer from DB", zap.Error(err))
+		return nil, err
+	}
+
+	customer.CompanyPersons, err = s.CompanyPersonRepository.ListByCustomerID(ctx, customer.ID)
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch CompanyPersonList from DB", zap.Error(err))
+		return nil, err
+	}
+
+	resp := dto.CreateCustomerDetailResponse(customer)
+	return &resp, nil
+}
+
+// Create validates and inserts the customer, returns apiErrors.ValidationFailed with the bad fields
+// and apiErrors.CustomerAlreadyExists when another customer has the external id
+func (s CustomerServiceImpl) Create(ctx context.Context, req dto.CustomerRequest) (*dto.CustomerResponse, error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.Create")
+	defer span.End()
+
+	var customer customerModel.Customer
+	req.Apply(&customer)
+
+	if err := validateCustomer(customer); err != nil {
+		return nil, err
+	}
+
+	err := s.DB.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.CustomerRepository.Create(ctx, &customer); err != nil {
+			return err
+		}
+		return s.Audit.Record(ctx, auditEntry(auditModel.ActionCreate, nil, &customer))
+	})
+	if errors.Is(err, storage.ErrUniqueViolation) {
+		return nil, apiErrors.ThrowError(apiErrors.CustomerAlreadyExists)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error create Customer", zap.Error(err))
+		return nil, err
 	}
 
-	for i := 0; i < len(customerList); i++ {
-		companyPerson, err := s.CompanyPersonRepository.ByCustomerID(ctx, customerList[i].ID)
+	return s.ByID(ctx, customer.ID)
+}
+
+// Update replaces every field of the customer
+func (s CustomerServiceImpl) Update(ctx context.Context, id int64, req dto.CustomerRequest) (*dto.CustomerResponse, error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.Update")
+	defer span.End()
+
+	return s.update(ctx, id, req.Apply)
+}
+
+// Patch changes the fields present in req, the merged customer is validated as a whole
+func (s CustomerServiceImpl) Patch(ctx context.Context, id int64, req dto.CustomerPatchRequest) (*dto.CustomerResponse, error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.Patch")
+	defer span.End()
+
+	return s.update(ctx, id, req.Apply)
+}
+
+// update applies the change to the customer locked and read in the transaction writing it,
+// so the concurrent changes of the customer run one after another and audit the state they change
+func (s CustomerServiceImpl) update(ctx context.Context, id int64, apply func(*customerModel.Customer)) (*dto.CustomerResponse, error) {
+	err := s.DB.WithinTx(ctx, func(ctx context.Context) error {
+		customer, err := s.locked(ctx, id)
 		if err != nil {
-			logger.WorkLoggerWithContext(ctx).Error("Error fetch CompanyPerson from DB")
-			continue
+			return err
+		}
+
+		before := customer
+		apply(&customer)
+
+		if err := validateCustomer(customer); err != nil {
+			return err
 		}
 
-		customerList[i].CompanyPerson = companyPerson
+		if err := s.CustomerRepository.Update(ctx, customer); err != nil {
+			return err
+		}
+		return s.Audit.Record(ctx, auditEntry(auditModel.ActionUpdate, &before, &customer))
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apiErrors.ThrowError(apiErrors.CustomerNotFound)
+	}
+	if errors.Is(err, storage.ErrUniqueViolation) {
+		return nil, apiErrors.ThrowError(apiErrors.CustomerAlreadyExists)
+	}
+	if apiErrors.ParseError(err) != nil {
+		return nil, err
 	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error update Customer", zap.Error(err))
+		return nil, err
+	}
+
+	return s.ByID(ctx, id)
+}
 
-	return dto.CreateCustomerListResponse(customerList), count, nil
+// Delete marks the customer and its company persons deleted
+func (s CustomerServiceImpl) Delete(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "CustomerService.Delete")
+	defer span.End()
+
+	err := s.DB.WithinTx(ctx, func(ctx context.Context) error {
+		customer, err := s.locked(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.CustomerRepository.SoftDelete(ctx, id); err != nil {
+			return err
+		}
+		return s.Audit.Record(ctx, auditEntry(auditModel.ActionDelete, &customer, nil))
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return apiErrors.ThrowError(apiErrors.CustomerNotFound)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error delete Customer", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// locked locks the customer until the end of the transaction of ctx and reads it,
+// sql.ErrNoRows when there is none
+func (s CustomerServiceImpl) locked(ctx context.Context, id int64) (customerModel.Customer, error) {
+	if err := s.CustomerRepository.LockByID(ctx, id); err != nil {
+		return customerModel.Customer{}, err
+	}
+	return s.CustomerRepository.ByID(ctx, id)
+}
+
+// auditEntry - the change of the customer, before is nil for the created one, after for the deleted one
+func auditEntry(action string, before, after *customerModel.Customer) auditService.Entry {
+	entry := auditService.Entry{
+		Entity: auditModel.EntityCustomer,
+		Action: action,
+	}
+	if before != nil {
+		entry.Before = before
+		entry.CustomerID, entry.EntityID = before.ID, strconv.FormatInt(before.ID, 10)
+	}
+	if after != nil {
+		entry.After = after
+		entry.CustomerID, entry.EntityID = after.ID, strconv.FormatInt(after.ID, 10)
+	}
+	return entry
 }
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	auditService "github.com/internet-banking-ul/internal/modules/audit/services"
+	"github.com/internet-banking-ul/internal/modules/customer/dto"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// companyCtx is the context of a caller acting for the company, see middles.Require
+func companyCtx(companyID int64) context.Context {
+	ctx := context.WithValue(context.Background(), utils.ContextHolderKey, &sync.Map{})
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyID, companyID)
+	return ctx
+}
+
+func TestCustomerServiceImpl_Audit(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	s := NewCustomerService(db)
+	ctx := context.Background()
+
+	customer, err := s.Create(ctx, dto.CustomerRequest{
+		PersonType: "LEGAL", ExternalID: "c-1", Name: "Company", FullName: "Company LLP",
+		Ownership: "TOO", ResidencyAndEconomicCode: "17", TaxCode: "050140000120",
+	})
+	require.NoError(t, err)
+
+	name := "Renamed"
+	_, err = s.Patch(ctx, customer.ID, dto.CustomerPatchRequest{Name: &name})
+	require.NoError(t, err)
+	// nothing changed, not recorded
+	_, err = s.Patch(ctx, customer.ID, dto.CustomerPatchRequest{Name: &name})
+	require.NoError(t, err)
+	require.NoError(t, s.Delete(ctx, customer.ID))
+
+	err = s.Delete(ctx, 99)
+	require.NotNil(t, apiErrors.ParseError(err))
+	assert.Equal(t, apiErrors.CustomerNotFound, apiErrors.ParseError(err).Id)
+
+	records, count, _, err := auditService.NewAuditService(db).List(companyCtx(customer.ID),
+		entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	require.Len(t, records, 3)
+	for i, action := range []string{auditModel.ActionCreate, auditModel.ActionUpdate, auditModel.ActionDelete} {
+		assert.Equal(t, auditModel.EntityCustomer, records[i].Entity)
+		assert.Equal(t, action, records[i].Action)
+	}
+	assert.Equal(t, auditModel.ChangeList{{Field: "name", Before: "Company", After: "Renamed"}}, records[1].Changes)
+}
+
+func newCustomerRequest(externalID string) dto.CustomerRequest {
+	return dto.CustomerRequest{
+		PersonType: "LEGAL", ExternalID: externalID, Name: "Company", FullName: "Company LLP",
+		Ownership: "TOO", ResidencyAndEconomicCode: "17", TaxCode: "050140000120",
+	}
+}
+
+func TestCustomerServiceImpl_ExternalIDUnique(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	s := NewCustomerService(db)
+	ctx := context.Background()
+
+	first, err := s.Create(ctx, newCustomerRequest("c-1"))
+	require.NoError(t, err)
+	second, err := s.Create(ctx, newCustomerRequest("c-2"))
+	require.NoError(t, err)
+
+	_, err = s.Create(ctx, newCustomerRequest("c-1"))
+	require.NotNil(t, apiErrors.ParseError(err), err)
+	assert.Equal(t, apiErrors.CustomerAlreadyExists, apiErrors.ParseError(err).Id)
+
+	externalID := "c-1"
+	_, err = s.Patch(ctx, second.ID, dto.CustomerPatchRequest{ExternalID: &externalID})
+	require.NotNil(t, apiErrors.ParseError(err), err)
+	assert.Equal(t, apiErrors.CustomerAlreadyExists, apiErrors.ParseError(err).Id)
+
+	// the external id of a deleted customer is free
+	require.NoError(t, s.Delete(ctx, first.ID))
+	_, err = s.Create(ctx, newCustomerRequest("c-1"))
+	assert.NoError(t, err)
+}
+
+func TestCustomerServiceImpl_PatchIntlName(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	s := NewCustomerService(db)
+	ctx := context.Background()
+
+	req := newCustomerRequest("c-1")
+	intlName := "Company Ltd"
+	req.IntlName = &intlName
+	customer, err := s.Create(ctx, req)
+	require.NoError(t, err)
+
+	patch := func(body string) *dto.CustomerResponse {
+		var req dto.CustomerPatchRequest
+		require.NoError(t, json.Unmarshal([]byte(body), &req))
+		customer, err := s.Patch(ctx, customer.ID, req)
+		require.NoError(t, err)
+		return customer
+	}
+
+	assert.Equal(t, "Company Ltd", patch(`{"name": "Renamed"}`).IntlName, "absent, kept")
+	assert.Equal(t, "", patch(`{"intlName": null}`).IntlName, "null, cleared")
+	stored, err := s.CustomerRepository.ByID(ctx, customer.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.IntlName.Valid)
+	assert.Equal(t, "Company Inc", patch(`{"intlName": "Company Inc"}`).IntlName)
+}
+
+func TestCustomerServiceImpl_WithoutCompanyPerson(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	s := NewCustomerService(db)
+	ctx := context.Background()
+
+	customer, err := s.Create(ctx, newCustomerRequest("c-1"))
+	require.NoError(t, err)
+	assert.Nil(t, customer.CompanyPerson)
+
+	body, err := json.Marshal(customer)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), `"companyPerson"`)
+
+	customers, _, _, err := s.List(ctx, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	require.Len(t, customers, 1)
+	assert.Nil(t, customers[0].CompanyPerson)
+}
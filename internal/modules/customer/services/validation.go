@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	customerModel "github.com/internet-banking-ul/internal/modules/customer/entities"
+	"github.com/internet-banking-ul/tools"
+)
+
+// max lengths of the CUSTOMER columns
+var customerFieldLength = map[string]int{
+	"externalID": 64,
+	"name":       255,
+	"fullName":   512,
+	"intlName":   255,
+	"ownership":  64,
+}
+
+// validateCustomer checks the customer about to be written and returns apiErrors.ValidationFailed listing every bad field
+func validateCustomer(customer customerModel.Customer) error {
+	var fields apiErrors.FieldErrors
+
+	required := map[string]string{
+		"personType":               customer.PersonType,
+		"externalID":               customer.ExternalID,
+		"name":                     customer.Name,
+		"fullName":                 customer.FullName,
+		"ownership":                customer.Ownership,
+		"residencyAndEconomicCode": customer.ResidencyAndEconomicCode,
+		"taxCode":                  customer.TaxCode,
+	}
+	for _, field := range []string{"personType", "externalID", "name", "fullName", "ownership", "residencyAndEconomicCode", "taxCode"} {
+		if required[field] == "" {
+			fields.Add(field, apiErrors.FieldRequired, fmt.Sprintf("%s is required", field))
+		}
+	}
+
+	values := map[string]string{
+		"externalID": customer.ExternalID,
+		"name":       customer.Name,
+		"fullName":   customer.FullName,
+		"intlName":   customer.IntlName.ValueOrZero(),
+		"ownership":  customer.Ownership,
+	}
+	for _, field := range []string{"externalID", "name", "fullName", "intlName", "ownership"} {
+		if max := customerFieldLength[field]; utf8.RuneCountInString(values[field]) > max {
+			fields.Add(field, apiErrors.FieldTooLong, fmt.Sprintf("%s must be at most %d characters", field, max))
+		}
+	}
+
+	if customer.PersonType != "" && !tools.StringInSlice(customerModel.PersonTypes, customer.PersonType) {
+		fields.Add("personType", apiErrors.FieldInvalidEnum,
+			fmt.Sprintf("personType must be one of %s", strings.Join(customerModel.PersonTypes, ", ")))
+	}
+
+	if customer.ResidencyAndEconomicCode != "" && !tools.ValidateResidencyAndEconomicCode(customer.ResidencyAndEconomicCode) {
+		fields.Add("residencyAndEconomicCode", apiErrors.FieldInvalidFormat,
+			"residencyAndEconomicCode must be 2 digits, the first one is 1 (resident) or 2 (non-resident)")
+	}
+
+	if customer.TaxCode != "" {
+		validateTaxCode(&fields, customer)
+	}
+
+	return fields.Err()
+}
+
+// validateTaxCode - IIN for individuals and entrepreneurs, BIN for legal entities
+func validateTaxCode(fields *apiErrors.FieldErrors, customer customerModel.Customer) {
+	if !tools.ValidateTaxCode(customer.TaxCode) {
+		fields.Add("taxCode", apiErrors.FieldInvalidFormat, "taxCode must be 12 digits")
+		return
+	}
+
+	switch {
+	case customer.IsIndividual() && !isIIN(customer.TaxCode):
+		fields.Add("taxCode", apiErrors.FieldInvalidFormat, "taxCode must be an IIN for individuals and entrepreneurs")
+		return
+	case customer.PersonType == customerModel.PersonTypeLegal && !isBIN(customer.TaxCode):
+		fields.Add("taxCode", apiErrors.FieldInvalidFormat, "taxCode must be a BIN for legal entities")
+		return
+	}
+
+	if !tools.ValidateTaxCodeChecksum(customer.TaxCode) {
+		fields.Add("taxCode", apiErrors.FieldInvalidChecksum, "taxCode control digit is invalid")
+	}
+}
+
+// isIIN - YYMMDD birth date followed by the century and gender digit 0..6
+func isIIN(code string) bool {
+	month := (code[2]-'0')*10 + code[3] - '0'
+	day := (code[4]-'0')*10 + code[5] - '0'
+	return month >= 1 && month <= 12 && day >= 1 && day <= 31 && code[6] <= '6'
+}
+
+// isBIN - YYMM registration date followed by the entity type digit 4..6
+func isBIN(code string) bool {
+	month := (code[2]-'0')*10 + code[3] - '0'
+	return month >= 1 && month <= 12 && code[4] >= '4' && code[4] <= '6'
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	customerModel "github.com/internet-banking-ul/internal/modules/customer/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCustomer(t *testing.T) {
+	valid := customerModel.Customer{
+		PersonType:               customerModel.PersonTypeIndividual,
+		ExternalID:               "ext-1",
+		Name:                     "Ivanov",
+		FullName:                 "Ivanov Ivan",
+		Ownership:                "IP",
+		ResidencyAndEconomicCode: "19",
+		TaxCode:                  "900101300126",
+	}
+	require.NoError(t, validateCustomer(valid))
+
+	legal := valid
+	legal.PersonType = customerModel.PersonTypeLegal
+	legal.TaxCode = "050140000120"
+	require.NoError(t, validateCustomer(legal))
+
+	tests := []struct {
+		name   string
+		modify func(c *customerModel.Customer)
+		field  string
+		code   string
+	}{
+		{"required", func(c *customerModel.Customer) { c.Name = "" }, "name", apiErrors.FieldRequired},
+		{"enum", func(c *customerModel.Customer) { c.PersonType = "ROBOT" }, "personType", apiErrors.FieldInvalidEnum},
+		{"residency", func(c *customerModel.Customer) { c.ResidencyAndEconomicCode = "31" }, "residencyAndEconomicCode", apiErrors.FieldInvalidFormat},
+		{"tax code digits", func(c *customerModel.Customer) { c.TaxCode = "90010130012" }, "taxCode", apiErrors.FieldInvalidFormat},
+		{"checksum", func(c *customerModel.Customer) { c.TaxCode = "900101300127" }, "taxCode", apiErrors.FieldInvalidChecksum},
+		{"bin for individual", func(c *customerModel.Customer) { c.TaxCode = "050140000120" }, "taxCode", apiErrors.FieldInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customer := valid
+			tt.modify(&customer)
+
+			err := validateCustomer(customer)
+			require.Error(t, err)
+
+			apiErr := apiErrors.ParseError(err)
+			require.NotNil(t, apiErr, "%T", err)
+			assert.Equal(t, apiErrors.ValidationFailed, apiErr.Id)
+			require.Len(t, apiErr.Fields, 1)
+			assert.Equal(t, tt.field, apiErr.Fields[0].Field)
+			assert.Equal(t, tt.code, apiErr.Fields[0].Code)
+		})
+	}
+}
@@ -2,12 +2,15 @@ package entities
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/helpers/apiErrors"
 	"github.com/internet-banking-ul/modules/logger"
 	"go.uber.org/zap"
 )
 
 type BasePaginationFilters struct {
 	BaseFilter
+	// Filters are the filter[field][op]=value query params, see FilterPredicate
+	Filters []FilterCondition `query:"-"`
 }
 
 type BaseFilter struct {
@@ -16,6 +19,8 @@ type BaseFilter struct {
 	SearchText string `json:"searchText"`
 	Size       uint64 `json:"size"`
 	Page       uint64 `json:"page"`
+	// Cursor selects the page after the cursor returned as nextCursor, Page is ignored then
+	Cursor string `json:"cursor"`
 }
 
 func (f *BaseFilter) GetSort() string {
@@ -31,6 +36,9 @@ func (f *BaseFilter) GetSearchText() string {
 }
 
 func (f *BaseFilter) GetOffset() uint64 {
+	if f.IsCursorMode() {
+		return 0
+	}
 	return f.Page * f.Size
 }
 
@@ -47,6 +55,20 @@ func NewBaseFilterFromQuery(ctx *fiber.Ctx) (*BasePaginationFilters, error) {
 		return nil, err
 	}
 
+	var filterErr error
+	ctx.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		field, op, ok, err := ParseFilterKey(string(key))
+		if err != nil && filterErr == nil {
+			filterErr = err
+		}
+		if ok && err == nil {
+			baseFilter.AddFilter(field, op, string(value))
+		}
+	})
+	if filterErr != nil {
+		return nil, apiErrors.ThrowError(apiErrors.FilterInvalid).WithNewMessage(filterErr.Error())
+	}
+
 	if baseFilter.Page <= 0 {
 		baseFilter.Page = 0
 	}
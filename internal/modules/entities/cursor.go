@@ -0,0 +1,225 @@
+package entities
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+)
+
+var (
+	cursorSecretMu sync.RWMutex
+	// cursorSecret is random until SetCursorSecret is called, so cursors don't survive a restart
+	cursorSecret = func() []byte {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+		return secret
+	}()
+)
+
+// SetCursorSecret sets the HMAC key signing the cursors, every instance behind the balancer needs the same key
+func SetCursorSecret(secret []byte) {
+	cursorSecretMu.Lock()
+	defer cursorSecretMu.Unlock()
+	cursorSecret = secret
+}
+
+// Cursor is the position after the last row of the previous page.
+// It is bound to the sort field and order it was issued for.
+type Cursor struct {
+	Sort  string      `json:"s"`
+	Order string      `json:"o"`
+	Value interface{} `json:"v"`
+	ID    int64       `json:"id"`
+	// Type marks values JSON can't restore by itself, cursorTypeTime for time.Time, cursorTypeNull for
+	// the NULL of a nullable column
+	Type string `json:"t,omitempty"`
+}
+
+const (
+	cursorTypeTime = "time"
+	cursorTypeNull = "null"
+)
+
+// Encode returns the opaque signed form: base64(payload).base64(hmac)
+func (c Cursor) Encode() (string, error) {
+	switch value := c.Value.(type) {
+	case time.Time:
+		c.Value, c.Type = value.UTC().Format(time.RFC3339Nano), cursorTypeTime
+	case nil:
+		c.Type = cursorTypeNull
+	}
+
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sign(payload)), nil
+}
+
+// DecodeCursor verifies the signature and decodes the cursor, any problem gives apiErrors.CursorInvalid
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+
+	payloadPart, signPart, ok := strings.Cut(s, ".")
+	if !ok {
+		return c, apiErrors.ThrowError(apiErrors.CursorInvalid)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return c, apiErrors.ThrowError(apiErrors.CursorInvalid)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(signPart)
+	if err != nil || !hmac.Equal(signature, sign(payload)) {
+		return c, apiErrors.ThrowError(apiErrors.CursorInvalid)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err = decoder.Decode(&c); err != nil {
+		return c, apiErrors.ThrowError(apiErrors.CursorInvalid)
+	}
+
+	// numbers come back as json.Number, the sortable numeric columns are integers
+	if n, ok := c.Value.(json.Number); ok {
+		if c.Value, err = n.Int64(); err != nil {
+			return c, apiErrors.ThrowError(apiErrors.CursorInvalid)
+		}
+	}
+
+	switch c.Type {
+	case cursorTypeNull:
+		if c.Value != nil {
+			return c, apiErrors.ThrowError(apiErrors.CursorInvalid)
+		}
+		c.Type = ""
+	case cursorTypeTime:
+		value, ok := c.Value.(string)
+		if !ok {
+			return c, apiErrors.ThrowError(apiErrors.CursorInvalid)
+		}
+		if c.Value, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			return c, apiErrors.ThrowError(apiErrors.CursorInvalid)
+		}
+		c.Type = ""
+	}
+
+	return c, nil
+}
+
+func sign(payload []byte) []byte {
+	cursorSecretMu.RLock()
+	defer cursorSecretMu.RUnlock()
+
+	mac := hmac.New(sha256.New, cursorSecret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// IsCursorMode reports whether the page is selected by the cursor rather than by page/size
+func (f *BaseFilter) IsCursorMode() bool {
+	return f.Cursor != ""
+}
+
+// SortColumn returns the column of the requested (or default) sort field, "" when the field is unknown
+func (f *BaseFilter) SortColumn(columns ListColumns) string {
+	return columns.Sortable[f.sortField(columns)]
+}
+
+// KeysetPredicate selects the rows after the cursor in the order of the filter:
+// (column, key) > (value, id), written as column > value OR (column = value AND key > id)
+// because Oracle doesn't compare row values. NULL of a nullable column is after every value, see OrderBy.
+// Returns nil when the filter has no cursor.
+func (f *BaseFilter) KeysetPredicate(columns ListColumns) (sq.Sqlizer, error) {
+	if !f.IsCursorMode() {
+		return nil, nil
+	}
+
+	c, err := DecodeCursor(f.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Sort != f.sortField(columns) || c.Order != f.order() {
+		return nil, apiErrors.ThrowError(apiErrors.CursorInvalid).
+			WithNewMessage("Cursor was issued for another sort or order")
+	}
+
+	column := columns.Sortable[c.Sort]
+	after := func(column string, value interface{}) sq.Sqlizer {
+		if c.Order == OrderDesc {
+			return sq.Lt{column: value}
+		}
+		return sq.Gt{column: value}
+	}
+
+	if column == columns.Key {
+		return after(columns.Key, c.ID), nil
+	}
+
+	if !columns.nullable(column) {
+		return sq.Or{
+			after(column, c.Value),
+			sq.And{sq.Eq{column: c.Value}, after(columns.Key, c.ID)},
+		}, nil
+	}
+
+	switch {
+	case c.Value == nil && c.Order == OrderDesc:
+		// the NULLs come first, then every value
+		return sq.Or{
+			sq.And{sq.Eq{column: nil}, after(columns.Key, c.ID)},
+			sq.NotEq{column: nil},
+		}, nil
+	case c.Value == nil:
+		return sq.And{sq.Eq{column: nil}, after(columns.Key, c.ID)}, nil
+	case c.Order == OrderDesc:
+		return sq.Or{
+			after(column, c.Value),
+			sq.And{sq.Eq{column: c.Value}, after(columns.Key, c.ID)},
+		}, nil
+	default:
+		// the NULLs come after every value
+		return sq.Or{
+			after(column, c.Value),
+			sq.And{sq.Eq{column: c.Value}, after(columns.Key, c.ID)},
+			sq.Eq{column: nil},
+		}, nil
+	}
+}
+
+// NextCursor returns the cursor of the page following the row with the sort value and key id,
+// value is nil for the NULL of a nullable column
+func (f *BaseFilter) NextCursor(columns ListColumns, value interface{}, id int64) (string, error) {
+	field := f.sortField(columns)
+	return Cursor{
+		Sort:  field,
+		Order: f.order(),
+		Value: value,
+		ID:    id,
+	}.Encode()
+}
+
+func (f *BaseFilter) sortField(columns ListColumns) string {
+	if field := f.GetSort(); field != "" {
+		return field
+	}
+	return columns.DefaultSort
+}
+
+func (f *BaseFilter) order() string {
+	if order := strings.ToLower(f.GetOrder()); order != "" {
+		return order
+	}
+	return OrderAsc
+}
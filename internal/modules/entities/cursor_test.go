@@ -0,0 +1,92 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	encoded, err := Cursor{Sort: "name", Order: OrderDesc, Value: int64(1) << 60, ID: 7}.Encode()
+	require.NoError(t, err)
+
+	c, err := DecodeCursor(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, Cursor{Sort: "name", Order: OrderDesc, Value: int64(1) << 60, ID: 7}, c)
+
+	for _, bad := range []string{"", "abc", encoded + "x", "e30." + encoded[len(encoded)-43:]} {
+		_, err = DecodeCursor(bad)
+		require.Error(t, err, bad)
+		assert.Equal(t, apiErrors.CursorInvalid, apiErrors.ParseError(err).Id)
+	}
+}
+
+func TestCursor_EncodeDecodeTime(t *testing.T) {
+	at := time.Date(2022, 3, 1, 12, 30, 0, 500, time.FixedZone("ALMT", 6*3600))
+	encoded, err := Cursor{Sort: "createdAt", Order: OrderAsc, Value: at, ID: 7}.Encode()
+	require.NoError(t, err)
+
+	c, err := DecodeCursor(encoded)
+	require.NoError(t, err)
+	require.IsType(t, time.Time{}, c.Value)
+	assert.True(t, at.Equal(c.Value.(time.Time)))
+	assert.Empty(t, c.Type)
+}
+
+func TestBaseFilter_KeysetPredicate(t *testing.T) {
+	f := &BaseFilter{Sort: "name"}
+	next, err := f.NextCursor(testColumns, "Acme", 42)
+	require.NoError(t, err)
+
+	f.Cursor = next
+	pred, err := f.KeysetPredicate(testColumns)
+	require.NoError(t, err)
+
+	sql, args, err := pred.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "(NAME > ? OR (NAME = ? AND ID > ?))", sql)
+	assert.Equal(t, []interface{}{"Acme", "Acme", int64(42)}, args)
+	assert.Equal(t, uint64(0), (&BaseFilter{Page: 3, Size: 10, Cursor: next}).GetOffset())
+
+	f.Order = OrderDesc
+	_, err = f.KeysetPredicate(testColumns)
+	assert.Equal(t, apiErrors.CursorInvalid, apiErrors.ParseError(err).Id)
+}
+
+func TestBaseFilter_KeysetPredicateNullable(t *testing.T) {
+	columns := ListColumns{Sortable: map[string]string{"validTo": "VALID_TO"}, Key: "ID", Nullable: []string{"VALID_TO"}}
+
+	tests := []struct {
+		order string
+		value interface{}
+		sql   string
+		args  []interface{}
+	}{
+		{OrderAsc, "2022", "(VALID_TO > ? OR (VALID_TO = ? AND ID > ?) OR VALID_TO IS NULL)", []interface{}{"2022", "2022", int64(7)}},
+		{OrderAsc, nil, "(VALID_TO IS NULL AND ID > ?)", []interface{}{int64(7)}},
+		{OrderDesc, "2022", "(VALID_TO < ? OR (VALID_TO = ? AND ID < ?))", []interface{}{"2022", "2022", int64(7)}},
+		{OrderDesc, nil, "((VALID_TO IS NULL AND ID < ?) OR VALID_TO IS NOT NULL)", []interface{}{int64(7)}},
+	}
+
+	for _, tt := range tests {
+		f := &BaseFilter{Sort: "validTo", Order: tt.order}
+		next, err := f.NextCursor(columns, tt.value, 7)
+		require.NoError(t, err)
+		require.NotEmpty(t, next)
+
+		f.Cursor = next
+		pred, err := f.KeysetPredicate(columns)
+		require.NoError(t, err)
+		sql, args, err := pred.ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, tt.sql, sql)
+		assert.Equal(t, tt.args, args)
+	}
+
+	orderBy, err := (&BaseFilter{Sort: "validTo"}).OrderBy(columns)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CASE WHEN VALID_TO IS NULL THEN 1 ELSE 0 END ASC", "VALID_TO ASC", "ID ASC"}, orderBy)
+}
@@ -0,0 +1,226 @@
+package entities
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/consts"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"github.com/internet-banking-ul/tools"
+)
+
+// filter operators of filter[field][op]=value, filter[field]=value is eq
+const (
+	FilterEq     = "eq"
+	FilterNe     = "ne"
+	FilterIn     = "in"
+	FilterGt     = "gt"
+	FilterGte    = "gte"
+	FilterLt     = "lt"
+	FilterLte    = "lte"
+	FilterLike   = "like"
+	FilterPrefix = "prefix"
+)
+
+// FilterType is the type the values of a field are coerced to
+type FilterType int
+
+const (
+	FilterString FilterType = iota
+	FilterInt
+	// FilterTime accepts RFC 3339 or a 2006-01-02 date (UTC midnight)
+	FilterTime
+)
+
+// default operators of the types, FilterField.Ops overrides them
+var filterTypeOps = map[FilterType][]string{
+	FilterString: {FilterEq, FilterNe, FilterIn, FilterLike, FilterPrefix},
+	FilterInt:    {FilterEq, FilterNe, FilterIn, FilterGt, FilterGte, FilterLt, FilterLte},
+	FilterTime:   {FilterGt, FilterGte, FilterLt, FilterLte},
+}
+
+// FilterField is a whitelisted filter field of a list
+type FilterField struct {
+	Column string
+	Type   FilterType
+	// Ops are the allowed operators, the defaults of Type when empty
+	Ops []string
+	// Build replaces the predicate on Column, used by the fields not stored as a column (e.g. validAt)
+	Build func(op string, values []interface{}) sq.Sqlizer
+}
+
+func (f FilterField) ops() []string {
+	if len(f.Ops) > 0 {
+		return f.Ops
+	}
+	return filterTypeOps[f.Type]
+}
+
+// FilterCondition is one filter[field][op]=value of the query, not validated yet
+type FilterCondition struct {
+	Field  string
+	Op     string
+	Values []string
+}
+
+// ParseFilterKey splits filter[field][op] or filter[field], ok is false for the other query keys
+func ParseFilterKey(key string) (field, op string, ok bool, err error) {
+	if !strings.HasPrefix(key, "filter[") {
+		return "", "", false, nil
+	}
+
+	field, rest, found := strings.Cut(strings.TrimPrefix(key, "filter["), "]")
+	if !found || field == "" {
+		return "", "", true, fmt.Errorf("malformed filter %q", key)
+	}
+
+	switch {
+	case rest == "":
+		return field, FilterEq, true, nil
+	case strings.HasPrefix(rest, "[") && strings.HasSuffix(rest, "]") && len(rest) > 2:
+		return field, strings.ToLower(rest[1 : len(rest)-1]), true, nil
+	default:
+		return "", "", true, fmt.Errorf("malformed filter %q", key)
+	}
+}
+
+// AddFilter adds the value of the query key filter[field][op], the values of in are comma separated
+func (f *BasePaginationFilters) AddFilter(field, op, value string) {
+	values := []string{value}
+	if op == FilterIn {
+		values = strings.Split(value, ",")
+	}
+
+	for i := range f.Filters {
+		if f.Filters[i].Field == field && f.Filters[i].Op == op {
+			f.Filters[i].Values = append(f.Filters[i].Values, values...)
+			return
+		}
+	}
+
+	f.Filters = append(f.Filters, FilterCondition{Field: field, Op: op, Values: values})
+}
+
+// FilterPredicate returns the AND of the filters checked against the whitelist of columns, nil when there are none.
+// Unknown fields, operators the field doesn't allow and values of a wrong type give 400 apiErrors.
+func (f *BasePaginationFilters) FilterPredicate(columns ListColumns) (sq.Sqlizer, error) {
+	if len(f.Filters) == 0 {
+		return nil, nil
+	}
+
+	and := make(sq.And, 0, len(f.Filters))
+	for _, cond := range f.Filters {
+		field, ok := columns.filterField(cond.Field)
+		if !ok {
+			return nil, apiErrors.ThrowError(apiErrors.FilterFieldUnknown).
+				WithNewMessage(fmt.Sprintf("Unknown filter field %q, allowed: %s", cond.Field, strings.Join(columns.filterFields(), ", ")))
+		}
+
+		if !tools.StringInSlice(field.ops(), cond.Op) {
+			return nil, apiErrors.ThrowError(apiErrors.FilterOperatorInvalid).
+				WithNewMessage(fmt.Sprintf("Filter %q supports %s", cond.Field, strings.Join(field.ops(), ", ")))
+		}
+
+		if cond.Op != FilterIn && len(cond.Values) > 1 {
+			return nil, apiErrors.ThrowError(apiErrors.FilterValueInvalid).
+				WithNewMessage(fmt.Sprintf("Filter %q %s takes a single value", cond.Field, cond.Op))
+		}
+
+		// the list goes to a single IN (...), longer ones fail on Oracle
+		if len(cond.Values) > consts.MaxInList {
+			return nil, apiErrors.ThrowError(apiErrors.FilterValueInvalid).
+				WithNewMessage(fmt.Sprintf("Filter %q %s takes at most %d values", cond.Field, cond.Op, consts.MaxInList))
+		}
+
+		values := make([]interface{}, 0, len(cond.Values))
+		for _, raw := range cond.Values {
+			value, err := field.coerce(cond.Op, strings.TrimSpace(raw))
+			if err != nil {
+				return nil, apiErrors.ThrowError(apiErrors.FilterValueInvalid).
+					WithNewMessage(fmt.Sprintf("Filter %q: %s", cond.Field, err))
+			}
+			values = append(values, value)
+		}
+
+		if field.Build != nil {
+			and = append(and, field.Build(cond.Op, values))
+			continue
+		}
+		and = append(and, field.predicate(cond.Op, values))
+	}
+
+	return and, nil
+}
+
+func (c ListColumns) filterField(name string) (FilterField, bool) {
+	for field, filter := range c.Filterable {
+		if strings.EqualFold(field, name) {
+			return filter, true
+		}
+	}
+	return FilterField{}, false
+}
+
+func (c ListColumns) filterFields() []string {
+	fields := make([]string, 0, len(c.Filterable))
+	for field := range c.Filterable {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (f FilterField) coerce(op, raw string) (interface{}, error) {
+	switch f.Type {
+	case FilterInt:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return v, nil
+	case FilterTime:
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t.UTC(), nil
+		}
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a date (2006-01-02) or RFC 3339 time", raw)
+		}
+		return t, nil
+	default:
+		if raw == "" {
+			return nil, fmt.Errorf("value is empty")
+		}
+		if op == FilterLike || op == FilterPrefix {
+			return likeEscaper.Replace(strings.ToLower(raw)), nil
+		}
+		return raw, nil
+	}
+}
+
+func (f FilterField) predicate(op string, values []interface{}) sq.Sqlizer {
+	switch op {
+	case FilterNe:
+		return sq.NotEq{f.Column: values[0]}
+	case FilterIn:
+		return sq.Eq{f.Column: values}
+	case FilterGt:
+		return sq.Gt{f.Column: values[0]}
+	case FilterGte:
+		return sq.GtOrEq{f.Column: values[0]}
+	case FilterLt:
+		return sq.Lt{f.Column: values[0]}
+	case FilterLte:
+		return sq.LtOrEq{f.Column: values[0]}
+	case FilterLike:
+		return lowerLike(f.Column, "%"+values[0].(string)+"%")
+	case FilterPrefix:
+		return lowerLike(f.Column, values[0].(string)+"%")
+	default:
+		return sq.Eq{f.Column: values[0]}
+	}
+}
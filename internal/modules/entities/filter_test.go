@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var filterColumns = ListColumns{
+	Filterable: map[string]FilterField{
+		"signLevel": {Column: "SIGN_LEVEL"},
+		"companyID": {Column: "COMPANY_ID", Type: FilterInt},
+		"validFrom": {Column: "VALID_FROM", Type: FilterTime},
+	},
+}
+
+func TestParseFilterKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		field, op string
+		ok, err   bool
+	}{
+		{key: "size"},
+		{key: "filter[signLevel][in]", field: "signLevel", op: FilterIn, ok: true},
+		{key: "filter[signLevel][IN]", field: "signLevel", op: FilterIn, ok: true},
+		{key: "filter[signLevel]", field: "signLevel", op: FilterEq, ok: true},
+		{key: "filter[signLevel", ok: true, err: true},
+		{key: "filter[][eq]", ok: true, err: true},
+		{key: "filter[signLevel][]", ok: true, err: true},
+	}
+
+	for _, tt := range tests {
+		field, op, ok, err := ParseFilterKey(tt.key)
+		assert.Equal(t, tt.ok, ok, tt.key)
+		assert.Equal(t, tt.err, err != nil, tt.key)
+		assert.Equal(t, tt.field, field, tt.key)
+		assert.Equal(t, tt.op, op, tt.key)
+	}
+}
+
+func TestNewBaseFilterFromQuery_Filters(t *testing.T) {
+	var filter *BasePaginationFilters
+	app := fiber.New()
+	app.Get("/", func(ctx *fiber.Ctx) (err error) {
+		filter, err = NewBaseFilterFromQuery(ctx)
+		return err
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/?size=5&filter[signLevel][in]=A,B&filter[companyId]=10&filter[validFrom][gte]=2022-01-01", nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, uint64(5), filter.Size)
+
+	pred, err := filter.FilterPredicate(filterColumns)
+	require.NoError(t, err)
+	sql, args, err := pred.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "(SIGN_LEVEL IN (?,?) AND COMPANY_ID = ? AND VALID_FROM >= ?)", sql)
+	assert.Equal(t, []interface{}{"A", "B", int64(10), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}, args)
+}
+
+func TestBasePaginationFilters_FilterPredicate_Errors(t *testing.T) {
+	tests := []struct {
+		field, op, value string
+		errID            string
+	}{
+		{"password", FilterEq, "x", apiErrors.FilterFieldUnknown},
+		{"validFrom", FilterEq, "2022-01-01", apiErrors.FilterOperatorInvalid},
+		{"validFrom", FilterGt, "yesterday", apiErrors.FilterValueInvalid},
+		{"companyID", FilterIn, "1,x", apiErrors.FilterValueInvalid},
+		{"companyID", FilterIn, strings.Repeat("1,", consts.MaxInList) + "1", apiErrors.FilterValueInvalid},
+		{"signLevel", FilterEq, " ", apiErrors.FilterValueInvalid},
+	}
+
+	for _, tt := range tests {
+		var filter BasePaginationFilters
+		filter.AddFilter(tt.field, tt.op, tt.value)
+
+		_, err := filter.FilterPredicate(filterColumns)
+		require.Error(t, err, tt.field)
+		assert.Equal(t, tt.errID, apiErrors.ParseError(err).Id, tt.field)
+		assert.Equal(t, 400, apiErrors.ParseError(err).Status)
+	}
+}
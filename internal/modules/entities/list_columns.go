@@ -0,0 +1,103 @@
+package entities
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+)
+
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// ListColumns is the whitelist of columns a repository allows BaseFilter to sort and search by
+type ListColumns struct {
+	// Sortable maps the sort field of the request to the column
+	Sortable map[string]string
+	// DefaultSort is the sort field used when the request has none
+	DefaultSort string
+	// Key is the unique column appended to every ORDER BY, so the pages don't overlap
+	Key string
+	// Searchable columns are matched case-insensitively against SearchText
+	Searchable []string
+	// Nullable sortable columns, NULL is sorted after every value (last asc, first desc) by every dialect
+	Nullable []string
+	// Filterable maps the field of filter[field][op] to the column, the name is matched ignoring case
+	Filterable map[string]FilterField
+}
+
+func (c ListColumns) sortFields() []string {
+	fields := make([]string, 0, len(c.Sortable))
+	for field := range c.Sortable {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (c ListColumns) nullable(column string) bool {
+	for _, nullable := range c.Nullable {
+		if nullable == column {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderBy returns the ORDER BY clauses for the Sort and Order of the filter.
+// An unknown sort field gives apiErrors.SortFieldUnknown, an order other than asc/desc apiErrors.SortOrderInvalid.
+func (f *BaseFilter) OrderBy(columns ListColumns) ([]string, error) {
+	field := f.sortField(columns)
+	column, ok := columns.Sortable[field]
+	if !ok {
+		return nil, apiErrors.ThrowError(apiErrors.SortFieldUnknown).
+			WithNewMessage(fmt.Sprintf("Unknown sort field %q, allowed: %s", field, strings.Join(columns.sortFields(), ", ")))
+	}
+
+	order := f.order()
+	switch order {
+	case OrderAsc, OrderDesc:
+	default:
+		return nil, apiErrors.ThrowError(apiErrors.SortOrderInvalid)
+	}
+
+	clauses := []string{fmt.Sprintf("%s %s", column, strings.ToUpper(order))}
+	if columns.nullable(column) {
+		// SQLite sorts NULL first and Oracle last, the CASE gives both the same order as the keyset
+		clauses = append([]string{fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END %s", column, strings.ToUpper(order))}, clauses...)
+	}
+	if columns.Key != "" && column != columns.Key {
+		clauses = append(clauses, fmt.Sprintf("%s %s", columns.Key, strings.ToUpper(order)))
+	}
+
+	return clauses, nil
+}
+
+// SearchPredicate matches SearchText as a substring of any searchable column ignoring case,
+// returns nil when there is nothing to search. LIKE wildcards in the text are escaped.
+func (f *BaseFilter) SearchPredicate(columns ListColumns) sq.Sqlizer {
+	text := strings.TrimSpace(f.GetSearchText())
+	if text == "" || len(columns.Searchable) == 0 {
+		return nil
+	}
+
+	pattern := "%" + likeEscaper.Replace(strings.ToLower(text)) + "%"
+
+	or := make(sq.Or, 0, len(columns.Searchable))
+	for _, column := range columns.Searchable {
+		or = append(or, lowerLike(column, pattern))
+	}
+
+	return or
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// lowerLike is the case-insensitive LIKE, the pattern is lower case with the wildcards escaped by likeEscaper
+func lowerLike(column, pattern string) sq.Sqlizer {
+	return sq.Expr(fmt.Sprintf(`LOWER(%s) LIKE ? ESCAPE '\'`, column), pattern)
+}
@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testColumns = ListColumns{
+	Sortable:    map[string]string{"id": "ID", "name": "NAME"},
+	DefaultSort: "id",
+	Key:         "ID",
+	Searchable:  []string{"NAME", "FULL_NAME"},
+}
+
+func TestBaseFilter_OrderBy(t *testing.T) {
+	orderBy, err := (&BaseFilter{}).OrderBy(testColumns)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ID ASC"}, orderBy)
+
+	orderBy, err = (&BaseFilter{Sort: "name", Order: "DESC"}).OrderBy(testColumns)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NAME DESC", "ID DESC"}, orderBy)
+
+	_, err = (&BaseFilter{Sort: "NAME; DROP TABLE CUSTOMER"}).OrderBy(testColumns)
+	require.Error(t, err)
+	assert.Equal(t, apiErrors.SortFieldUnknown, apiErrors.ParseError(err).Id)
+	assert.Equal(t, 400, apiErrors.ParseError(err).Status)
+
+	_, err = (&BaseFilter{Sort: "name", Order: "sideways"}).OrderBy(testColumns)
+	require.Error(t, err)
+	assert.Equal(t, apiErrors.SortOrderInvalid, apiErrors.ParseError(err).Id)
+}
+
+func TestBaseFilter_SearchPredicate(t *testing.T) {
+	assert.Nil(t, (&BaseFilter{SearchText: "  "}).SearchPredicate(testColumns))
+
+	sql, args, err := (&BaseFilter{SearchText: " 100%_Sure "}).SearchPredicate(testColumns).ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, `(LOWER(NAME) LIKE ? ESCAPE '\' OR LOWER(FULL_NAME) LIKE ? ESCAPE '\')`, sql)
+	assert.Equal(t, []interface{}{`%100\%\_sure%`, `%100\%\_sure%`}, args)
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+// IdempotencyKey is the Idempotency-Key of a request of the caller with the hash of the request.
+// The response is empty while the first request is in progress, LockedUntil is the lease of the request
+// holding the key then.
+type IdempotencyKey struct {
+	CallerID       int64          `db:"CALLER_ID" json:"caller_id"`
+	Key            string         `db:"IDEMPOTENCY_KEY" json:"idempotency_key"`
+	RequestHash    string         `db:"REQUEST_HASH" json:"request_hash"`
+	ResponseStatus sql.NullInt64  `db:"RESPONSE_STATUS" json:"response_status"`
+	ContentType    sql.NullString `db:"CONTENT_TYPE" json:"content_type"`
+	ResponseBody   []byte         `db:"RESPONSE_BODY" json:"response_body"`
+	CreatedAt      time.Time      `db:"CREATED_AT" json:"created_at"`
+	LockedUntil    sql.NullTime   `db:"LOCKED_UNTIL" json:"locked_until"`
+}
+
+// Completed reports whether the response of the first request is stored
+func (k IdempotencyKey) Completed() bool {
+	return k.ResponseStatus.Valid
+}
+
+// Expired reports whether the key is older than ttl at the time, a zero ttl never expires
+func (k IdempotencyKey) Expired(now time.Time, ttl time.Duration) bool {
+	return ttl > 0 && !k.CreatedAt.Add(ttl).After(now)
+}
+
+// Stale reports whether the request holding the key has stopped extending its lease at the time,
+// e.g. its instance has crashed, so a retry may take the key over
+func (k IdempotencyKey) Stale(now time.Time) bool {
+	return !k.Completed() && k.LockedUntil.Valid && !k.LockedUntil.Time.After(now)
+}
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	idempotencyModel "github.com/internet-banking-ul/internal/modules/idempotency/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+// RepositoryIdempotency keeps the Idempotency-Key of the requests, see middles.Idempotency.
+// IdempotencyKey returns sql.ErrNoRows for an unknown key, ReserveIdempotencyKey fails for a key already
+// reserved, so of the concurrent requests with the key only one reserves it. In the same way only one
+// of the retries takes over a stale key with TakeOverIdempotencyKey, the others get sql.ErrNoRows.
+type RepositoryIdempotency interface {
+	IdempotencyKey(ctx context.Context, callerID int64, key string) (result idempotencyModel.IdempotencyKey, err error)
+	ReserveIdempotencyKey(ctx context.Context, key idempotencyModel.IdempotencyKey) error
+	ExtendIdempotencyKey(ctx context.Context, callerID int64, key string, lockedUntil time.Time) error
+	TakeOverIdempotencyKey(ctx context.Context, key idempotencyModel.IdempotencyKey, now time.Time) error
+	CompleteIdempotencyKey(ctx context.Context, key idempotencyModel.IdempotencyKey) error
+	ReleaseIdempotencyKey(ctx context.Context, callerID int64, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+var idempotencyKeyColumns = []string{
+	"CALLER_ID",
+	"IDEMPOTENCY_KEY",
+	"REQUEST_HASH",
+	"RESPONSE_STATUS",
+	"CONTENT_TYPE",
+	"RESPONSE_BODY",
+	"CREATED_AT",
+	"LOCKED_UNTIL",
+}
+
+// RepositoryIdempotencyImpl keeps the keys in the IDEMPOTENCY_KEY table shared by every instance
+type RepositoryIdempotencyImpl struct {
+	DB *storage.DB
+}
+
+func (repo *RepositoryIdempotencyImpl) IdempotencyKey(ctx context.Context, callerID int64, key string) (result idempotencyModel.IdempotencyKey, err error) {
+	if repo.DB == nil {
+		err = fmt.Errorf("db is nil")
+		return result, err
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("IdempotencyKey")
+
+	q := repo.DB.Builder().
+		Select(idempotencyKeyColumns...).
+		From("IDEMPOTENCY_KEY").
+		Where(sq.Eq{"CALLER_ID": callerID, "IDEMPOTENCY_KEY": key})
+
+	sql, args, e := q.ToSql()
+	if e != nil {
+		l.Error("ToSql", zap.Error(e))
+		return result, e
+	}
+
+	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
+
+	err = q.RunWith(repo.DB.Runner(ctx)).QueryRowContext(ctx).Scan(
+		&result.CallerID,
+		&result.Key,
+		&result.RequestHash,
+		&result.ResponseStatus,
+		&result.ContentType,
+		&result.ResponseBody,
+		&result.CreatedAt,
+		&result.LockedUntil,
+	)
+	return result, err
+}
+
+// ReserveIdempotencyKey inserts the key without a response, the primary key makes the insert of a key
+// reserved by a concurrent request fail
+func (repo *RepositoryIdempotencyImpl) ReserveIdempotencyKey(ctx context.Context, key idempotencyModel.IdempotencyKey) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("ReserveIdempotencyKey")
+
+	q := repo.DB.Builder().
+		Insert("IDEMPOTENCY_KEY").
+		Columns("CALLER_ID", "IDEMPOTENCY_KEY", "REQUEST_HASH", "CREATED_AT", "LOCKED_UNTIL").
+		Values(key.CallerID, key.Key, key.RequestHash, key.CreatedAt, key.LockedUntil)
+
+	return storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx)))
+}
+
+// ExtendIdempotencyKey moves the lease of the key in progress, sql.ErrNoRows when it is completed or released
+func (repo *RepositoryIdempotencyImpl) ExtendIdempotencyKey(ctx context.Context, callerID int64, key string, lockedUntil time.Time) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("ExtendIdempotencyKey")
+
+	q := repo.DB.Builder().
+		Update("IDEMPOTENCY_KEY").
+		Set("LOCKED_UNTIL", lockedUntil).
+		Where(sq.Eq{"CALLER_ID": callerID, "IDEMPOTENCY_KEY": key, "RESPONSE_STATUS": nil})
+
+	return storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx)))
+}
+
+// TakeOverIdempotencyKey sets the lease of key.LockedUntil to the key in progress whose lease has passed at now.
+// The lease of the first retry taking it over is after now, so the update of the others affects no row.
+func (repo *RepositoryIdempotencyImpl) TakeOverIdempotencyKey(ctx context.Context, key idempotencyModel.IdempotencyKey, now time.Time) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("TakeOverIdempotencyKey")
+
+	q := repo.DB.Builder().
+		Update("IDEMPOTENCY_KEY").
+		Set("LOCKED_UNTIL", key.LockedUntil).
+		Where(sq.Eq{"CALLER_ID": key.CallerID, "IDEMPOTENCY_KEY": key.Key, "RESPONSE_STATUS": nil}).
+		Where(sq.LtOrEq{"LOCKED_UNTIL": now})
+
+	return storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx)))
+}
+
+// CompleteIdempotencyKey stores the response of the reserved key and ends its lease
+func (repo *RepositoryIdempotencyImpl) CompleteIdempotencyKey(ctx context.Context, key idempotencyModel.IdempotencyKey) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("CompleteIdempotencyKey")
+
+	q := repo.DB.Builder().
+		Update("IDEMPOTENCY_KEY").
+		SetMap(map[string]interface{}{
+			"RESPONSE_STATUS": key.ResponseStatus,
+			"CONTENT_TYPE":    key.ContentType,
+			"RESPONSE_BODY":   key.ResponseBody,
+			"LOCKED_UNTIL":    nil,
+		}).
+		Where(sq.Eq{"CALLER_ID": key.CallerID, "IDEMPOTENCY_KEY": key.Key})
+
+	return storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx)))
+}
+
+// ReleaseIdempotencyKey deletes the key, so the request may be retried with it
+func (repo *RepositoryIdempotencyImpl) ReleaseIdempotencyKey(ctx context.Context, callerID int64, key string) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("ReleaseIdempotencyKey")
+
+	q := repo.DB.Builder().
+		Delete("IDEMPOTENCY_KEY").
+		Where(sq.Eq{"CALLER_ID": callerID, "IDEMPOTENCY_KEY": key})
+
+	return storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx)))
+}
+
+// DeleteExpiredIdempotencyKeys deletes the keys created before the time and returns their number
+func (repo *RepositoryIdempotencyImpl) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	if repo.DB == nil {
+		return 0, fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("DeleteExpiredIdempotencyKeys")
+
+	q := repo.DB.Builder().
+		Delete("IDEMPOTENCY_KEY").
+		Where(sq.Lt{"CREATED_AT": before})
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		l.Error("ToSql", zap.Error(err))
+		return 0, err
+	}
+
+	l.Debug("Info", zap.String("sql", query), zap.Any("args", args))
+
+	res, err := q.RunWith(repo.DB.Runner(ctx)).ExecContext(ctx)
+	if err != nil {
+		l.Error("ExecContext", zap.Error(err))
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	idempotencyModel "github.com/internet-banking-ul/internal/modules/idempotency/entities"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRepositoryIdempotency(t *testing.T, repo RepositoryIdempotency) {
+	ctx := context.Background()
+	created := time.Date(2022, 6, 15, 10, 0, 0, 0, time.UTC)
+
+	_, err := repo.IdempotencyKey(ctx, 100, "key-1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	key := idempotencyModel.IdempotencyKey{CallerID: 100, Key: "key-1", RequestHash: "hash", CreatedAt: created,
+		LockedUntil: sql.NullTime{Time: created.Add(time.Minute), Valid: true}}
+	require.NoError(t, repo.ReserveIdempotencyKey(ctx, key))
+	assert.Error(t, repo.ReserveIdempotencyKey(ctx, key), "the key is reserved already")
+
+	// the same key of another caller
+	other := key
+	other.CallerID = 200
+	other.CreatedAt = created.Add(time.Hour)
+	require.NoError(t, repo.ReserveIdempotencyKey(ctx, other))
+
+	stored, err := repo.IdempotencyKey(ctx, 100, "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", stored.RequestHash)
+	assert.False(t, stored.Completed())
+	assert.True(t, created.Add(time.Minute).Equal(stored.LockedUntil.Time))
+
+	// the lease is extended by the running request, then it stops
+	takeOver := key
+	takeOver.LockedUntil.Time = created.Add(3 * time.Minute)
+	require.ErrorIs(t, repo.TakeOverIdempotencyKey(ctx, takeOver, created.Add(time.Minute/2)), sql.ErrNoRows, "the lease is valid")
+	require.NoError(t, repo.ExtendIdempotencyKey(ctx, 100, "key-1", created.Add(2*time.Minute)))
+	require.ErrorIs(t, repo.TakeOverIdempotencyKey(ctx, takeOver, created.Add(time.Minute)), sql.ErrNoRows, "the lease is extended")
+	require.NoError(t, repo.TakeOverIdempotencyKey(ctx, takeOver, created.Add(2*time.Minute)))
+	require.ErrorIs(t, repo.TakeOverIdempotencyKey(ctx, takeOver, created.Add(2*time.Minute)), sql.ErrNoRows, "taken over already")
+
+	stored, err = repo.IdempotencyKey(ctx, 100, "key-1")
+	require.NoError(t, err)
+	assert.True(t, created.Add(3*time.Minute).Equal(stored.LockedUntil.Time))
+
+	key.ResponseStatus = sql.NullInt64{Int64: 201, Valid: true}
+	key.ContentType = sql.NullString{String: "application/json", Valid: true}
+	key.ResponseBody = []byte(`{"id":1}`)
+	require.NoError(t, repo.CompleteIdempotencyKey(ctx, key))
+
+	stored, err = repo.IdempotencyKey(ctx, 100, "key-1")
+	require.NoError(t, err)
+	assert.True(t, stored.Completed())
+	assert.Equal(t, int64(201), stored.ResponseStatus.Int64)
+	assert.Equal(t, "application/json", stored.ContentType.String)
+	assert.Equal(t, `{"id":1}`, string(stored.ResponseBody))
+	assert.False(t, stored.LockedUntil.Valid)
+	require.ErrorIs(t, repo.ExtendIdempotencyKey(ctx, 100, "key-1", created.Add(time.Hour)), sql.ErrNoRows, "completed")
+
+	deleted, err := repo.DeleteExpiredIdempotencyKeys(ctx, created.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	_, err = repo.IdempotencyKey(ctx, 100, "key-1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, repo.ReleaseIdempotencyKey(ctx, 200, "key-1"))
+	_, err = repo.IdempotencyKey(ctx, 200, "key-1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.ErrorIs(t, repo.ReleaseIdempotencyKey(ctx, 200, "key-1"), sql.ErrNoRows)
+}
+
+func TestRepositoryIdempotencyImpl(t *testing.T) {
+	testRepositoryIdempotency(t, NewIdempotencyRepository(storagetest.NewSQLite(t)))
+}
+
+func TestMemoryRepository(t *testing.T) {
+	testRepositoryIdempotency(t, NewMemoryRepository(10))
+}
+
+func TestMemoryRepository_Evict(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository(2)
+
+	reserve := func(key string, completed bool) {
+		k := idempotencyModel.IdempotencyKey{CallerID: 1, Key: key, CreatedAt: time.Now()}
+		require.NoError(t, repo.ReserveIdempotencyKey(ctx, k))
+		if completed {
+			k.ResponseStatus = sql.NullInt64{Int64: 200, Valid: true}
+			require.NoError(t, repo.CompleteIdempotencyKey(ctx, k))
+		}
+	}
+	kept := func(key string) bool {
+		_, err := repo.IdempotencyKey(ctx, 1, key)
+		return err == nil
+	}
+
+	reserve("a", true)
+	reserve("b", true)
+	// a is used recently, b is evicted
+	require.True(t, kept("a"))
+	reserve("c", false)
+	assert.False(t, kept("b"))
+
+	// a is the only completed key, c in progress is kept
+	reserve("d", false)
+	assert.False(t, kept("a"))
+	assert.True(t, kept("c"))
+	assert.True(t, kept("d"))
+}
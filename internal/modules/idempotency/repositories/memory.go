@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	idempotencyModel "github.com/internet-banking-ul/internal/modules/idempotency/entities"
+)
+
+// ErrIdempotencyKeyReserved - MemoryRepository.ReserveIdempotencyKey of a key kept already
+var ErrIdempotencyKeyReserved = errors.New("idempotency key is reserved")
+
+type memoryKey struct {
+	callerID int64
+	key      string
+}
+
+// MemoryRepository keeps up to capacity keys of one instance in memory. When it is full the least recently
+// used completed key is evicted, the keys in progress are evicted only when no completed key is left.
+type MemoryRepository struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // of idempotencyModel.IdempotencyKey, the most recently used first
+	keys     map[memoryKey]*list.Element
+}
+
+func NewMemoryRepository(capacity int) *MemoryRepository {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryRepository{
+		capacity: capacity,
+		order:    list.New(),
+		keys:     map[memoryKey]*list.Element{},
+	}
+}
+
+func (repo *MemoryRepository) IdempotencyKey(_ context.Context, callerID int64, key string) (idempotencyModel.IdempotencyKey, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	e, ok := repo.keys[memoryKey{callerID, key}]
+	if !ok {
+		return idempotencyModel.IdempotencyKey{}, sql.ErrNoRows
+	}
+	repo.order.MoveToFront(e)
+	return copyIdempotencyKey(e.Value.(idempotencyModel.IdempotencyKey)), nil
+}
+
+func (repo *MemoryRepository) ReserveIdempotencyKey(_ context.Context, key idempotencyModel.IdempotencyKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	k := memoryKey{key.CallerID, key.Key}
+	if _, ok := repo.keys[k]; ok {
+		return ErrIdempotencyKeyReserved
+	}
+	for repo.order.Len() >= repo.capacity {
+		repo.evict()
+	}
+
+	key.ResponseStatus = sql.NullInt64{}
+	key.ContentType = sql.NullString{}
+	key.ResponseBody = nil
+	repo.keys[k] = repo.order.PushFront(key)
+	return nil
+}
+
+func (repo *MemoryRepository) ExtendIdempotencyKey(_ context.Context, callerID int64, key string, lockedUntil time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	e, ok := repo.keys[memoryKey{callerID, key}]
+	if !ok || e.Value.(idempotencyModel.IdempotencyKey).Completed() {
+		return sql.ErrNoRows
+	}
+
+	stored := e.Value.(idempotencyModel.IdempotencyKey)
+	stored.LockedUntil = sql.NullTime{Time: lockedUntil, Valid: true}
+	e.Value = stored
+	return nil
+}
+
+func (repo *MemoryRepository) TakeOverIdempotencyKey(_ context.Context, key idempotencyModel.IdempotencyKey, now time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	e, ok := repo.keys[memoryKey{key.CallerID, key.Key}]
+	if !ok || !e.Value.(idempotencyModel.IdempotencyKey).Stale(now) {
+		return sql.ErrNoRows
+	}
+
+	stored := e.Value.(idempotencyModel.IdempotencyKey)
+	stored.LockedUntil = key.LockedUntil
+	e.Value = stored
+	return nil
+}
+
+func (repo *MemoryRepository) CompleteIdempotencyKey(_ context.Context, key idempotencyModel.IdempotencyKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	e, ok := repo.keys[memoryKey{key.CallerID, key.Key}]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	stored := e.Value.(idempotencyModel.IdempotencyKey)
+	stored.ResponseStatus = key.ResponseStatus
+	stored.ContentType = key.ContentType
+	stored.ResponseBody = append([]byte(nil), key.ResponseBody...)
+	stored.LockedUntil = sql.NullTime{}
+	e.Value = stored
+	repo.order.MoveToFront(e)
+	return nil
+}
+
+func (repo *MemoryRepository) ReleaseIdempotencyKey(_ context.Context, callerID int64, key string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	k := memoryKey{callerID, key}
+	e, ok := repo.keys[k]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	repo.order.Remove(e)
+	delete(repo.keys, k)
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteExpiredIdempotencyKeys(_ context.Context, before time.Time) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var deleted int64
+	for e := repo.order.Front(); e != nil; {
+		next := e.Next()
+		if key := e.Value.(idempotencyModel.IdempotencyKey); key.CreatedAt.Before(before) {
+			repo.order.Remove(e)
+			delete(repo.keys, memoryKey{key.CallerID, key.Key})
+			deleted++
+		}
+		e = next
+	}
+	return deleted, nil
+}
+
+// evict removes the least recently used completed key, or the least recently used one when every key is in progress
+func (repo *MemoryRepository) evict() {
+	victim := repo.order.Back()
+	for e := victim; e != nil; e = e.Prev() {
+		if e.Value.(idempotencyModel.IdempotencyKey).Completed() {
+			victim = e
+			break
+		}
+	}
+
+	key := victim.Value.(idempotencyModel.IdempotencyKey)
+	repo.order.Remove(victim)
+	delete(repo.keys, memoryKey{key.CallerID, key.Key})
+}
+
+// copyIdempotencyKey doesn't let the caller change the stored body
+func copyIdempotencyKey(key idempotencyModel.IdempotencyKey) idempotencyModel.IdempotencyKey {
+	key.ResponseBody = append([]byte(nil), key.ResponseBody...)
+	return key
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"github.com/internet-banking-ul/internal/storage"
+)
+
+type Repositories interface {
+	RepositoryIdempotency
+}
+
+type RepositoriesImpl struct {
+	// inject db impl to RepositoriesImpl event the db is being used by the child struct impl
+	db *storage.DB
+	*RepositoryIdempotencyImpl
+}
+
+func NewIdempotencyRepository(
+	db *storage.DB,
+) *RepositoriesImpl {
+	return &RepositoriesImpl{
+		db: db,
+		RepositoryIdempotencyImpl: &RepositoryIdempotencyImpl{
+			DB: db,
+		},
+	}
+}
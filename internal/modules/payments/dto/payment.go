@@ -0,0 +1,166 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	paymentModel "github.com/internet-banking-ul/internal/modules/payments/entities"
+	"github.com/internet-banking-ul/tools"
+	"github.com/internet-banking-ul/tools/money"
+)
+
+// PaymentResponse - Amount is the decimal of major units, "10500.75"
+type PaymentResponse struct {
+	ID                 int64      `json:"id"`
+	AccountID          int64      `json:"accountID"`
+	Number             string     `json:"number"`
+	Amount             string     `json:"amount"`
+	Currency           string     `json:"currency"`
+	BeneficiaryName    string     `json:"beneficiaryName"`
+	BeneficiaryTaxCode string     `json:"beneficiaryTaxCode"`
+	BeneficiaryIBAN    string     `json:"beneficiaryIBAN"`
+	BeneficiaryCode    string     `json:"beneficiaryCode"`
+	SenderCode         string     `json:"senderCode"`
+	PurposeCode        string     `json:"purposeCode"`
+	Purpose            string     `json:"purpose"`
+	ValueDate          time.Time  `json:"valueDate"`
+	Status             string     `json:"status"`
+	RejectReason       *string    `json:"rejectReason"`
+	CreatedBy          int64      `json:"createdBy"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	SignedBy           *int64     `json:"signedBy"`
+	SignedAt           *time.Time `json:"signedAt"`
+	SentAt             *time.Time `json:"sentAt"`
+	ResolvedAt         *time.Time `json:"resolvedAt"`
+}
+
+func CreatePaymentResponse(payment paymentModel.Payment) PaymentResponse {
+	resp := PaymentResponse{
+		ID:                 payment.ID,
+		AccountID:          payment.AccountID,
+		Number:             payment.Number,
+		Currency:           payment.Currency,
+		BeneficiaryName:    payment.BeneficiaryName,
+		BeneficiaryTaxCode: payment.BeneficiaryTaxCode,
+		BeneficiaryIBAN:    payment.BeneficiaryIBAN,
+		BeneficiaryCode:    payment.BeneficiaryCode,
+		SenderCode:         payment.SenderCode,
+		PurposeCode:        payment.PurposeCode,
+		Purpose:            payment.Purpose,
+		ValueDate:          payment.ValueDate,
+		Status:             payment.Status,
+		CreatedBy:          payment.CreatedBy,
+		CreatedAt:          payment.CreatedAt,
+	}
+
+	if currency, ok := money.CurrencyByCode(payment.Currency); ok {
+		resp.Amount = money.New(payment.Amount, currency).Decimal()
+	}
+	if payment.SignedBy.Valid {
+		resp.SignedBy = &payment.SignedBy.Int64
+	}
+	if payment.SignedAt.Valid {
+		resp.SignedAt = &payment.SignedAt.Time
+	}
+	if payment.SentAt.Valid {
+		resp.SentAt = &payment.SentAt.Time
+	}
+	if payment.RejectReason.Valid {
+		resp.RejectReason = &payment.RejectReason.String
+	}
+	if payment.ResolvedAt.Valid {
+		resp.ResolvedAt = &payment.ResolvedAt.Time
+	}
+
+	return resp
+}
+
+type PaymentListResponse []*PaymentResponse
+
+func CreatePaymentListResponse(paymentList paymentModel.PaymentList) PaymentListResponse {
+	paymentListResp := PaymentListResponse{}
+	for _, p := range paymentList {
+		payment := CreatePaymentResponse(*p)
+		paymentListResp = append(paymentListResp, &payment)
+	}
+	return paymentListResp
+}
+
+// PaymentRequest - body of POST. Amount is the decimal of major units, it is parsed by the service
+// in the currency of the account. BeneficiaryCode is KBe, SenderCode KOd, PurposeCode KNP.
+// ValueDate is today when omitted.
+type PaymentRequest struct {
+	AccountID          int64      `json:"accountID"`
+	Number             string     `json:"number"`
+	Amount             string     `json:"amount"`
+	BeneficiaryName    string     `json:"beneficiaryName"`
+	BeneficiaryTaxCode string     `json:"beneficiaryTaxCode"`
+	BeneficiaryIBAN    string     `json:"beneficiaryIBAN"`
+	BeneficiaryCode    string     `json:"beneficiaryCode"`
+	SenderCode         string     `json:"senderCode"`
+	PurposeCode        string     `json:"purposeCode"`
+	Purpose            string     `json:"purpose"`
+	ValueDate          *time.Time `json:"valueDate"`
+}
+
+// Apply copies the request but the amount into payment
+func (r PaymentRequest) Apply(payment *paymentModel.Payment) {
+	payment.AccountID = r.AccountID
+	payment.Number = strings.TrimSpace(r.Number)
+	payment.BeneficiaryName = strings.TrimSpace(r.BeneficiaryName)
+	payment.BeneficiaryTaxCode = strings.TrimSpace(r.BeneficiaryTaxCode)
+	payment.BeneficiaryIBAN = tools.NormalizeIBAN(r.BeneficiaryIBAN)
+	payment.BeneficiaryCode = strings.TrimSpace(r.BeneficiaryCode)
+	payment.SenderCode = strings.TrimSpace(r.SenderCode)
+	payment.PurposeCode = strings.TrimSpace(r.PurposeCode)
+	payment.Purpose = strings.TrimSpace(r.Purpose)
+	if r.ValueDate != nil {
+		payment.ValueDate = tools.StartOfDay(*r.ValueDate)
+	}
+}
+
+// PaymentPatchRequest - body of PATCH of a draft, only the fields present in the body are changed
+type PaymentPatchRequest struct {
+	AccountID          *int64     `json:"accountID"`
+	Number             *string    `json:"number"`
+	Amount             *string    `json:"amount"`
+	BeneficiaryName    *string    `json:"beneficiaryName"`
+	BeneficiaryTaxCode *string    `json:"beneficiaryTaxCode"`
+	BeneficiaryIBAN    *string    `json:"beneficiaryIBAN"`
+	BeneficiaryCode    *string    `json:"beneficiaryCode"`
+	SenderCode         *string    `json:"senderCode"`
+	PurposeCode        *string    `json:"purposeCode"`
+	Purpose            *string    `json:"purpose"`
+	ValueDate          *time.Time `json:"valueDate"`
+}
+
+// Apply copies the present fields but the amount into payment
+func (r PaymentPatchRequest) Apply(payment *paymentModel.Payment) {
+	patch := func(dst *string, src *string) {
+		if src != nil {
+			*dst = strings.TrimSpace(*src)
+		}
+	}
+
+	if r.AccountID != nil {
+		payment.AccountID = *r.AccountID
+	}
+	patch(&payment.Number, r.Number)
+	patch(&payment.BeneficiaryName, r.BeneficiaryName)
+	patch(&payment.BeneficiaryTaxCode, r.BeneficiaryTaxCode)
+	if r.BeneficiaryIBAN != nil {
+		payment.BeneficiaryIBAN = tools.NormalizeIBAN(*r.BeneficiaryIBAN)
+	}
+	patch(&payment.BeneficiaryCode, r.BeneficiaryCode)
+	patch(&payment.SenderCode, r.SenderCode)
+	patch(&payment.PurposeCode, r.PurposeCode)
+	patch(&payment.Purpose, r.Purpose)
+	if r.ValueDate != nil {
+		payment.ValueDate = tools.StartOfDay(*r.ValueDate)
+	}
+}
+
+// PaymentRejectRequest - body of the rejection of the sent payment by the bank
+type PaymentRejectRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,69 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	StatusDraft    = "DRAFT"
+	StatusSigned   = "SIGNED"
+	StatusSent     = "SENT"
+	StatusExecuted = "EXECUTED"
+	StatusRejected = "REJECTED"
+)
+
+// Statuses - allowed values of Payment.Status
+var Statuses = []string{StatusDraft, StatusSigned, StatusSent, StatusExecuted, StatusRejected}
+
+// OperationType - the approvals.OperationType of the signing of a draft, the rule of approvals.rules.PAYMENT
+const OperationType = "PAYMENT"
+
+// transitions - the state machine of the payment order: it is edited as a draft, signed by the company
+// (its approval is approved), sent to the bank and executed or rejected there. EXECUTED and REJECTED are final.
+var transitions = map[string][]string{
+	StatusDraft:  {StatusSigned},
+	StatusSigned: {StatusSent},
+	StatusSent:   {StatusExecuted, StatusRejected},
+}
+
+// CanTransition reports whether the payment may go from the status to the other one
+func CanTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Payment is a domestic transfer order in tenge from an account of the customer.
+// Amount is minor units of Currency. Version is incremented by every change, an update of a stale version
+// fails (optimistic locking).
+type Payment struct {
+	ID                 int64          `db:"ID" json:"id"`
+	CustomerID         int64          `db:"CUSTOMER_ID" json:"customer_id"`
+	AccountID          int64          `db:"ACCOUNT_ID" json:"account_id"`
+	Number             string         `db:"NUMBER" json:"number"`
+	Amount             int64          `db:"AMOUNT" json:"amount"`
+	Currency           string         `db:"CURRENCY" json:"currency"`
+	BeneficiaryName    string         `db:"BENEFICIARY_NAME" json:"beneficiary_name"`
+	BeneficiaryTaxCode string         `db:"BENEFICIARY_TAX_CODE" json:"beneficiary_tax_code"`
+	BeneficiaryIBAN    string         `db:"BENEFICIARY_IBAN" json:"beneficiary_iban"`
+	BeneficiaryCode    string         `db:"BENEFICIARY_CODE" json:"beneficiary_code"`
+	SenderCode         string         `db:"SENDER_CODE" json:"sender_code"`
+	PurposeCode        string         `db:"PURPOSE_CODE" json:"purpose_code"`
+	Purpose            string         `db:"PURPOSE" json:"purpose"`
+	ValueDate          time.Time      `db:"VALUE_DATE" json:"value_date"`
+	Status             string         `db:"STATUS" json:"status"`
+	RejectReason       sql.NullString `db:"REJECT_REASON" json:"reject_reason"`
+	Version            int64          `db:"VERSION" json:"version"`
+	CreatedBy          int64          `db:"CREATED_BY" json:"created_by"`
+	CreatedAt          time.Time      `db:"CREATED_AT" json:"created_at"`
+	SignedBy           sql.NullInt64  `db:"SIGNED_BY" json:"signed_by"`
+	SignedAt           sql.NullTime   `db:"SIGNED_AT" json:"signed_at"`
+	SentAt             sql.NullTime   `db:"SENT_AT" json:"sent_at"`
+	ResolvedAt         sql.NullTime   `db:"RESOLVED_AT" json:"resolved_at"`
+}
+
+type PaymentList []*Payment
@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{StatusDraft, StatusSigned}:  true,
+		{StatusSigned, StatusSent}:   true,
+		{StatusSent, StatusExecuted}: true,
+		{StatusSent, StatusRejected}: true,
+	}
+
+	for _, from := range Statuses {
+		for _, to := range Statuses {
+			assert.Equal(t, allowed[[2]string{from, to}], CanTransition(from, to), "%s -> %s", from, to)
+		}
+	}
+	assert.False(t, CanTransition("", StatusDraft))
+}
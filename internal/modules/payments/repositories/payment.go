@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/internet-banking-ul/internal/modules/entities"
+	paymentModel "github.com/internet-banking-ul/internal/modules/payments/entities"
+	"github.com/internet-banking-ul/internal/modules/repository"
+	"github.com/internet-banking-ul/internal/storage"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+)
+
+type RepositoryPaymentQuery interface {
+	ByID(ctx context.Context, id int64) (result paymentModel.Payment, err error)
+	LockByID(ctx context.Context, id int64) error
+	List(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (paymentModel.PaymentList, int64, string, error)
+	Count(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (count int64, err error)
+}
+
+// paymentListColumns - the sort, search and filter whitelist of List
+var paymentListColumns = entities.ListColumns{
+	Sortable: map[string]string{
+		"id":              "ID",
+		"number":          "NUMBER",
+		"amount":          "AMOUNT",
+		"beneficiaryName": "BENEFICIARY_NAME",
+		"valueDate":       "VALUE_DATE",
+		"status":          "STATUS",
+		"createdAt":       "CREATED_AT",
+	},
+	DefaultSort: "id",
+	Key:         "ID",
+	Searchable:  []string{"NUMBER", "BENEFICIARY_NAME", "BENEFICIARY_IBAN", "PURPOSE"},
+	Filterable: map[string]entities.FilterField{
+		"status":             {Column: "STATUS", Ops: []string{entities.FilterEq, entities.FilterNe, entities.FilterIn}},
+		"accountID":          {Column: "ACCOUNT_ID", Type: entities.FilterInt, Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"amount":             {Column: "AMOUNT", Type: entities.FilterInt},
+		"beneficiaryTaxCode": {Column: "BENEFICIARY_TAX_CODE", Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"beneficiaryIBAN":    {Column: "BENEFICIARY_IBAN", Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"purposeCode":        {Column: "PURPOSE_CODE", Ops: []string{entities.FilterEq, entities.FilterIn}},
+		"valueDate":          {Column: "VALUE_DATE", Type: entities.FilterTime},
+		"createdAt":          {Column: "CREATED_AT", Type: entities.FilterTime},
+	},
+}
+
+// RepositoryPaymentQueryImpl - the payment orders of the customers
+type RepositoryPaymentQueryImpl struct {
+	*repository.Repository[paymentModel.Payment]
+}
+
+func newPaymentQuery(db *storage.DB) *RepositoryPaymentQueryImpl {
+	return &RepositoryPaymentQueryImpl{
+		Repository: repository.New[paymentModel.Payment](db, "PAYMENT_ORDER", paymentListColumns, nil),
+	}
+}
+
+// ByID returns sql.ErrNoRows when the payment doesn't exist
+func (repo *RepositoryPaymentQueryImpl) ByID(ctx context.Context, id int64) (result paymentModel.Payment, err error) {
+	return repo.GetByID(ctx, id)
+}
+
+// List returns the page of the customer payments selected by baseFilter, the total of the filtered payments
+// and the cursor of the next page ("" on the last page)
+func (repo *RepositoryPaymentQueryImpl) List(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (paymentModel.PaymentList, int64, string, error) {
+	return repo.Repository.List(ctx, baseFilter, sq.Eq{"CUSTOMER_ID": customerID})
+}
+
+// Count returns the number of customer payments matching the search and the filters of baseFilter
+func (repo *RepositoryPaymentQueryImpl) Count(ctx context.Context, customerID int64, baseFilter entities.BasePaginationFilters) (count int64, err error) {
+	return repo.Repository.Count(ctx, baseFilter, sq.Eq{"CUSTOMER_ID": customerID})
+}
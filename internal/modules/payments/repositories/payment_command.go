@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	paymentModel "github.com/internet-banking-ul/internal/modules/payments/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+const paymentSequence = "PAYMENT_ORDER_SEQ"
+
+// RepositoryPaymentCommand - writes, every method runs in the transaction of ctx started by the service (storage.DB.Runner)
+type RepositoryPaymentCommand interface {
+	Create(ctx context.Context, payment *paymentModel.Payment) error
+	Update(ctx context.Context, payment *paymentModel.Payment) error
+}
+
+type RepositoryPaymentCommandImpl struct {
+	DB *storage.DB
+}
+
+// Create takes the next ID from PAYMENT_ORDER_SEQ, inserts the payment and sets payment.ID.
+// An empty Number becomes the ID.
+func (repo *RepositoryPaymentCommandImpl) Create(ctx context.Context, payment *paymentModel.Payment) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Create")
+
+	id, err := repo.DB.NextID(ctx, paymentSequence, "PAYMENT_ORDER")
+	if err != nil {
+		l.Error("NextID", zap.Error(err))
+		return err
+	}
+
+	number := payment.Number
+	if number == "" {
+		number = fmt.Sprintf("%d", id)
+	}
+
+	q := repo.DB.Builder().
+		Insert("PAYMENT_ORDER").
+		Columns(
+			"ID",
+			"CUSTOMER_ID",
+			"ACCOUNT_ID",
+			"NUMBER",
+			"AMOUNT",
+			"CURRENCY",
+			"BENEFICIARY_NAME",
+			"BENEFICIARY_TAX_CODE",
+			"BENEFICIARY_IBAN",
+			"BENEFICIARY_CODE",
+			"SENDER_CODE",
+			"PURPOSE_CODE",
+			"PURPOSE",
+			"VALUE_DATE",
+			"STATUS",
+			"REJECT_REASON",
+			"VERSION",
+			"CREATED_BY",
+			"CREATED_AT",
+			"SIGNED_BY",
+			"SIGNED_AT",
+			"SENT_AT",
+			"RESOLVED_AT",
+		).
+		Values(
+			id,
+			payment.CustomerID,
+			payment.AccountID,
+			number,
+			payment.Amount,
+			payment.Currency,
+			payment.BeneficiaryName,
+			payment.BeneficiaryTaxCode,
+			payment.BeneficiaryIBAN,
+			payment.BeneficiaryCode,
+			payment.SenderCode,
+			payment.PurposeCode,
+			payment.Purpose,
+			payment.ValueDate,
+			payment.Status,
+			payment.RejectReason,
+			payment.Version,
+			payment.CreatedBy,
+			payment.CreatedAt,
+			payment.SignedBy,
+			payment.SignedAt,
+			payment.SentAt,
+			payment.ResolvedAt,
+		)
+
+	if err = storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return err
+	}
+
+	payment.ID = id
+	payment.Number = number
+	return nil
+}
+
+// Update writes the payment if nobody changed it since it was read and increments payment.Version.
+// Returns sql.ErrNoRows when the version in the database differs.
+func (repo *RepositoryPaymentCommandImpl) Update(ctx context.Context, payment *paymentModel.Payment) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Update")
+
+	q := repo.DB.Builder().
+		Update("PAYMENT_ORDER").
+		SetMap(map[string]interface{}{
+			"ACCOUNT_ID":           payment.AccountID,
+			"NUMBER":               payment.Number,
+			"AMOUNT":               payment.Amount,
+			"CURRENCY":             payment.Currency,
+			"BENEFICIARY_NAME":     payment.BeneficiaryName,
+			"BENEFICIARY_TAX_CODE": payment.BeneficiaryTaxCode,
+			"BENEFICIARY_IBAN":     payment.BeneficiaryIBAN,
+			"BENEFICIARY_CODE":     payment.BeneficiaryCode,
+			"SENDER_CODE":          payment.SenderCode,
+			"PURPOSE_CODE":         payment.PurposeCode,
+			"PURPOSE":              payment.Purpose,
+			"VALUE_DATE":           payment.ValueDate,
+			"STATUS":               payment.Status,
+			"REJECT_REASON":        payment.RejectReason,
+			"SIGNED_BY":            payment.SignedBy,
+			"SIGNED_AT":            payment.SignedAt,
+			"SENT_AT":              payment.SentAt,
+			"RESOLVED_AT":          payment.ResolvedAt,
+			"VERSION":              payment.Version + 1,
+		}).
+		Where(sq.Eq{"ID": payment.ID, "VERSION": payment.Version})
+
+	if err := storage.ExecAffected(ctx, l, q.RunWith(repo.DB.Runner(ctx))); err != nil {
+		return err
+	}
+
+	payment.Version++
+	return nil
+}
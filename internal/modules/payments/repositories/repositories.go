@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"github.com/internet-banking-ul/internal/storage"
+)
+
+type Repositories interface {
+	RepositoryPaymentQuery
+	RepositoryPaymentCommand
+}
+
+type RepositoriesImpl struct {
+	// inject db impl to RepositoriesImpl event the db is being used by the child struct impl
+	db *storage.DB
+	*RepositoryPaymentQueryImpl
+	*RepositoryPaymentCommandImpl
+}
+
+func NewPaymentRepository(
+	db *storage.DB,
+) *RepositoriesImpl {
+	return &RepositoriesImpl{
+		db:                         db,
+		RepositoryPaymentQueryImpl: newPaymentQuery(db),
+		RepositoryPaymentCommandImpl: &RepositoryPaymentCommandImpl{
+			DB: db,
+		},
+	}
+}
@@ -0,0 +1,10 @@
+package services
+
+import "github.com/internet-banking-ul/modules/metrics"
+
+var (
+	paymentsCreated = metrics.Default.NewCounterVec("payments_created_total",
+		"Payment drafts created by currency", "currency")
+	paymentsTransited = metrics.Default.NewCounterVec("payments_transitions_total",
+		"Payments moved to the status after the draft", "status")
+)
@@ -0,0 +1,465 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	accountModel "github.com/internet-banking-ul/internal/modules/accounts/entities"
+	accountRepo "github.com/internet-banking-ul/internal/modules/accounts/repositories"
+	approvalDto "github.com/internet-banking-ul/internal/modules/approvals/dto"
+	approvalModel "github.com/internet-banking-ul/internal/modules/approvals/entities"
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	auditService "github.com/internet-banking-ul/internal/modules/audit/services"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/modules/payments/dto"
+	paymentModel "github.com/internet-banking-ul/internal/modules/payments/entities"
+	paymentRepo "github.com/internet-banking-ul/internal/modules/payments/repositories"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+	"github.com/internet-banking-ul/tools"
+	"github.com/internet-banking-ul/tools/money"
+	"go.uber.org/zap"
+)
+
+type PaymentService interface {
+	List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.PaymentListResponse, int64, string, error)
+	ByID(ctx context.Context, id int64) (*dto.PaymentResponse, error)
+	Create(ctx context.Context, req dto.PaymentRequest) (*dto.PaymentResponse, error)
+	Update(ctx context.Context, id int64, req dto.PaymentPatchRequest) (*dto.PaymentResponse, error)
+	Sign(ctx context.Context, id int64) (*dto.PaymentResponse, error)
+	Send(ctx context.Context, id int64) (*dto.PaymentResponse, error)
+	Execute(ctx context.Context, id int64) (*dto.PaymentResponse, error)
+	Reject(ctx context.Context, id int64, req dto.PaymentRejectRequest) (*dto.PaymentResponse, error)
+}
+
+// Approvals collects the signatures of the drafts by the approval rules, see approvalService.ApprovalService
+type Approvals interface {
+	SignOperation(ctx context.Context, operationType, operationID string) (*approvalDto.ApprovalResponse, error)
+	IsPending(ctx context.Context, operationType, operationID string) (bool, error)
+}
+
+// PaymentServiceImpl - the methods of the company users work with the payments of the current customer
+// (utils.ContextGetCurrentCompanyID); Execute and Reject are the result of the bank and aren't scoped
+type PaymentServiceImpl struct {
+	// Tx runs the reads and the writes of a change in one transaction
+	Tx                storage.TxManager
+	PaymentRepository paymentRepo.Repositories
+	AccountRepository accountRepo.Repositories
+	Approvals         Approvals
+	// Audit records every change of a payment in its transaction
+	Audit auditService.Recorder
+	Now   func() time.Time
+}
+
+func NewPaymentService(
+	db *storage.DB,
+	approvals Approvals,
+) *PaymentServiceImpl {
+	return &PaymentServiceImpl{
+		Tx:                db,
+		PaymentRepository: paymentRepo.NewPaymentRepository(db),
+		AccountRepository: accountRepo.NewAccountRepository(db),
+		Approvals:         approvals,
+		Audit:             auditService.NewAuditService(db),
+		Now:               time.Now,
+	}
+}
+
+func (s PaymentServiceImpl) List(ctx context.Context, baseFilter entities.BasePaginationFilters) (dto.PaymentListResponse, int64, string, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.List")
+	defer span.End()
+
+	customerID, err := currentCustomerID(ctx)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	paymentList, count, nextCursor, err := s.PaymentRepository.List(ctx, customerID, baseFilter)
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch PaymentList from DB", zap.Error(err))
+		return nil, 0, "", err
+	}
+
+	return dto.CreatePaymentListResponse(paymentList), count, nextCursor, nil
+}
+
+// ByID returns apiErrors.PaymentNotFound when the customer has no such payment
+func (s PaymentServiceImpl) ByID(ctx context.Context, id int64) (*dto.PaymentResponse, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.ByID")
+	defer span.End()
+
+	payment, err := s.owned(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.CreatePaymentResponse(payment)
+	return &resp, nil
+}
+
+// Create saves the draft of the payment from an active account of the customer
+func (s PaymentServiceImpl) Create(ctx context.Context, req dto.PaymentRequest) (*dto.PaymentResponse, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Create")
+	defer span.End()
+
+	customerID, err := currentCustomerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Now()
+	payment := paymentModel.Payment{
+		CustomerID: customerID,
+		ValueDate:  tools.StartOfDay(now),
+		Status:     paymentModel.StatusDraft,
+		CreatedBy:  userID,
+		CreatedAt:  now.UTC(),
+	}
+	req.Apply(&payment)
+
+	if err := s.check(ctx, &payment, &req.Amount); err != nil {
+		return nil, err
+	}
+
+	err = s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.PaymentRepository.Create(ctx, &payment); err != nil {
+			return err
+		}
+		return s.Audit.Record(ctx, auditEntry(auditModel.ActionCreate, nil, &payment))
+	})
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error create Payment", zap.Error(err))
+		return nil, err
+	}
+	paymentsCreated.WithLabelValues(payment.Currency).Inc()
+
+	resp := dto.CreatePaymentResponse(payment)
+	return &resp, nil
+}
+
+// Update changes the draft, apiErrors.PaymentStatusInvalid for a payment past the draft.
+// The draft is locked while it is checked and saved, so a concurrent signature waits for the update.
+func (s PaymentServiceImpl) Update(ctx context.Context, id int64, req dto.PaymentPatchRequest) (resp *dto.PaymentResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Update")
+	defer span.End()
+
+	err = s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		payment, err := s.locked(ctx, id, s.owned)
+		if err != nil {
+			return err
+		}
+		if payment.Status != paymentModel.StatusDraft {
+			return apiErrors.ThrowError(apiErrors.PaymentStatusInvalid)
+		}
+		// the signatures collected are for the draft as it is
+		pending, err := s.Approvals.IsPending(ctx, paymentModel.OperationType, strconv.FormatInt(id, 10))
+		if err != nil {
+			return err
+		}
+		if pending {
+			return apiErrors.ThrowError(apiErrors.PaymentOnApproval)
+		}
+
+		before := payment
+		req.Apply(&payment)
+		if err := s.check(ctx, &payment, req.Amount); err != nil {
+			return err
+		}
+
+		resp, err = s.save(ctx, before, payment)
+		return err
+	})
+	return resp, err
+}
+
+// Sign adds the signature of the current user to the approval of the draft (the rule of its OperationType),
+// the draft is SIGNED by the signature approving it and is returned unchanged until then. The signature
+// and the transition are written in one transaction with the draft locked, a failed transition leaves
+// the approval as it was.
+func (s PaymentServiceImpl) Sign(ctx context.Context, id int64) (resp *dto.PaymentResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Sign")
+	defer span.End()
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := false
+	err = s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		payment, err := s.locked(ctx, id, s.owned)
+		if err != nil {
+			return err
+		}
+		if !paymentModel.CanTransition(payment.Status, paymentModel.StatusSigned) {
+			return apiErrors.ThrowError(apiErrors.PaymentStatusInvalid)
+		}
+
+		approval, err := s.Approvals.SignOperation(ctx, paymentModel.OperationType, strconv.FormatInt(id, 10))
+		if err != nil {
+			return err
+		}
+		if approval.Status != approvalModel.StatusApproved {
+			unchanged := dto.CreatePaymentResponse(payment)
+			resp = &unchanged
+			return nil
+		}
+
+		before := payment
+		payment.Status = paymentModel.StatusSigned
+		payment.SignedBy = sql.NullInt64{Int64: userID, Valid: true}
+		payment.SignedAt = sql.NullTime{Time: s.Now().UTC(), Valid: true}
+
+		resp, err = s.save(ctx, before, payment)
+		signed = err == nil
+		return err
+	})
+	if err == nil && signed {
+		paymentsTransited.WithLabelValues(paymentModel.StatusSigned).Inc()
+	}
+	return resp, err
+}
+
+// Send hands the signed payment to the bank, the account has to be active and cover the amount.
+// The amount is held on the account (its available balance) until the bank executes or rejects the payment;
+// the account is locked, so the concurrent payments from it are checked one after another.
+func (s PaymentServiceImpl) Send(ctx context.Context, id int64) (*dto.PaymentResponse, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Send")
+	defer span.End()
+
+	return s.transit(ctx, id, paymentModel.StatusSent, s.owned, func(ctx context.Context, payment *paymentModel.Payment) error {
+		if err := s.AccountRepository.LockByID(ctx, payment.AccountID); err != nil {
+			return err
+		}
+		account, err := s.AccountRepository.ByID(ctx, payment.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Status != accountModel.StatusActive {
+			return apiErrors.ThrowError(apiErrors.PaymentAccountInactive)
+		}
+		if account.AvailableBalance < payment.Amount {
+			return apiErrors.ThrowError(apiErrors.PaymentInsufficientFunds)
+		}
+		if err := s.AccountRepository.AddBalances(ctx, payment.AccountID, -payment.Amount, 0); err != nil {
+			return err
+		}
+
+		payment.SentAt = sql.NullTime{Time: s.Now().UTC(), Valid: true}
+		return nil
+	})
+}
+
+// Execute records the execution of the sent payment by the bank, the amount held by Send is booked
+func (s PaymentServiceImpl) Execute(ctx context.Context, id int64) (*dto.PaymentResponse, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Execute")
+	defer span.End()
+
+	return s.transit(ctx, id, paymentModel.StatusExecuted, s.byID, func(ctx context.Context, payment *paymentModel.Payment) error {
+		if err := s.AccountRepository.AddBalances(ctx, payment.AccountID, 0, -payment.Amount); err != nil {
+			return err
+		}
+
+		payment.ResolvedAt = sql.NullTime{Time: s.Now().UTC(), Valid: true}
+		return nil
+	})
+}
+
+// Reject records the rejection of the sent payment by the bank with the reason, the amount held by Send
+// is released
+func (s PaymentServiceImpl) Reject(ctx context.Context, id int64, req dto.PaymentRejectRequest) (*dto.PaymentResponse, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Reject")
+	defer span.End()
+
+	reason := strings.TrimSpace(req.Reason)
+	if err := validateRejectReason(reason).Err(); err != nil {
+		return nil, err
+	}
+
+	return s.transit(ctx, id, paymentModel.StatusRejected, s.byID, func(ctx context.Context, payment *paymentModel.Payment) error {
+		if err := s.AccountRepository.AddBalances(ctx, payment.AccountID, payment.Amount, 0); err != nil {
+			return err
+		}
+
+		payment.RejectReason = sql.NullString{String: reason, Valid: true}
+		payment.ResolvedAt = sql.NullTime{Time: s.Now().UTC(), Valid: true}
+		return nil
+	})
+}
+
+// transit moves the payment to the status, read is s.owned for the payments of the customer and s.byID
+// for the result of the bank, apply sets the fields of the transition. The payment is locked, read,
+// checked by apply and saved in one transaction, so the checks see the data the transition is written against.
+func (s PaymentServiceImpl) transit(ctx context.Context, id int64, status string, read func(ctx context.Context, id int64) (paymentModel.Payment, error), apply func(ctx context.Context, payment *paymentModel.Payment) error) (resp *dto.PaymentResponse, err error) {
+	err = s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		payment, err := s.locked(ctx, id, read)
+		if err != nil {
+			return err
+		}
+		if !paymentModel.CanTransition(payment.Status, status) {
+			return apiErrors.ThrowError(apiErrors.PaymentStatusInvalid)
+		}
+
+		before := payment
+		if err := apply(ctx, &payment); err != nil {
+			return err
+		}
+		payment.Status = status
+
+		resp, err = s.save(ctx, before, payment)
+		return err
+	})
+	// counted once committed
+	if err == nil {
+		paymentsTransited.WithLabelValues(status).Inc()
+	}
+	return resp, err
+}
+
+// save writes the changed payment, before is the payment as read; apiErrors.PaymentConflict when it was
+// changed meanwhile
+func (s PaymentServiceImpl) save(ctx context.Context, before, payment paymentModel.Payment) (*dto.PaymentResponse, error) {
+	err := s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.PaymentRepository.Update(ctx, &payment); err != nil {
+			return err
+		}
+		return s.Audit.Record(ctx, auditEntry(auditModel.ActionUpdate, &before, &payment))
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apiErrors.ThrowError(apiErrors.PaymentConflict)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error update Payment", zap.Error(err))
+		return nil, err
+	}
+
+	resp := dto.CreatePaymentResponse(payment)
+	return &resp, nil
+}
+
+// check validates the payment, the account and the amount (when present) and sets Amount and Currency
+func (s PaymentServiceImpl) check(ctx context.Context, payment *paymentModel.Payment, amount *string) error {
+	var fields apiErrors.FieldErrors
+	validatePayment(&fields, *payment)
+
+	currency := money.KZT
+	if payment.AccountID > 0 {
+		account, err := s.AccountRepository.ByID(ctx, payment.AccountID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows) || err == nil && account.CustomerID != payment.CustomerID:
+			fields.Add("accountID", apiErrors.FieldNotFound, "accountID is not an account of the customer")
+		case err != nil:
+			logger.WorkLoggerWithContext(ctx).Error("Error fetch Account from DB", zap.Error(err))
+			return err
+		case account.Status != accountModel.StatusActive:
+			fields.Add("accountID", apiErrors.FieldInactive, "accountID must be an active account")
+		case account.Currency != money.KZT.Code:
+			fields.Add("accountID", apiErrors.FieldInvalidEnum, "accountID must be a KZT account, domestic payments are in tenge")
+		case account.IBAN == payment.BeneficiaryIBAN:
+			fields.Add("beneficiaryIBAN", apiErrors.FieldInvalidRange, "beneficiaryIBAN must differ from the IBAN of the account")
+		}
+	}
+	payment.Currency = currency.Code
+
+	if payment.ValueDate.Before(tools.StartOfDay(s.Now())) {
+		fields.Add("valueDate", apiErrors.FieldInvalidRange, "valueDate must not be in the past")
+	}
+
+	if amount != nil {
+		parsed, err := money.ParseExact(*amount, currency)
+		switch {
+		case strings.TrimSpace(*amount) == "":
+			fields.Add("amount", apiErrors.FieldRequired, "amount is required")
+		case err != nil:
+			fields.Add("amount", apiErrors.FieldInvalidFormat, "amount must be a decimal with at most 2 fraction digits")
+		case parsed.Sign() <= 0:
+			fields.Add("amount", apiErrors.FieldInvalidRange, "amount must be positive")
+		default:
+			payment.Amount = parsed.Minor()
+		}
+	}
+
+	return fields.Err()
+}
+
+// locked locks the payment until the end of the transaction of ctx and reads it by read,
+// apiErrors.PaymentNotFound when there is none
+func (s PaymentServiceImpl) locked(ctx context.Context, id int64, read func(ctx context.Context, id int64) (paymentModel.Payment, error)) (paymentModel.Payment, error) {
+	err := s.PaymentRepository.LockByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return paymentModel.Payment{}, apiErrors.ThrowError(apiErrors.PaymentNotFound)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error lock Payment", zap.Error(err))
+		return paymentModel.Payment{}, err
+	}
+	return read(ctx, id)
+}
+
+// owned returns the payment of the current customer, apiErrors.PaymentNotFound for the others
+func (s PaymentServiceImpl) owned(ctx context.Context, id int64) (paymentModel.Payment, error) {
+	customerID, err := currentCustomerID(ctx)
+	if err != nil {
+		return paymentModel.Payment{}, err
+	}
+
+	payment, err := s.byID(ctx, id)
+	if err == nil && payment.CustomerID != customerID {
+		return paymentModel.Payment{}, apiErrors.ThrowError(apiErrors.PaymentNotFound)
+	}
+	return payment, err
+}
+
+func (s PaymentServiceImpl) byID(ctx context.Context, id int64) (paymentModel.Payment, error) {
+	payment, err := s.PaymentRepository.ByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return paymentModel.Payment{}, apiErrors.ThrowError(apiErrors.PaymentNotFound)
+	}
+	if err != nil {
+		logger.WorkLoggerWithContext(ctx).Error("Error fetch Payment from DB", zap.Error(err))
+		return paymentModel.Payment{}, err
+	}
+	return payment, nil
+}
+
+// auditEntry - the change of the payment, before is nil for the created one
+func auditEntry(action string, before, after *paymentModel.Payment) auditService.Entry {
+	entry := auditService.Entry{
+		CustomerID: after.CustomerID,
+		Entity:     auditModel.EntityPayment,
+		EntityID:   strconv.FormatInt(after.ID, 10),
+		Action:     action,
+		After:      after,
+	}
+	if before != nil {
+		entry.Before = before
+	}
+	return entry
+}
+
+func currentCustomerID(ctx context.Context) (int64, error) {
+	customerID, ok := utils.ContextGetCurrentCompanyID(ctx)
+	if !ok {
+		return 0, apiErrors.ThrowError(apiErrors.AccessDenied)
+	}
+	return customerID, nil
+}
+
+func currentUserID(ctx context.Context) (int64, error) {
+	userID, ok := utils.ContextGetCurrentUserID(ctx)
+	if !ok {
+		return 0, apiErrors.ThrowError(apiErrors.UserNotLogined)
+	}
+	return int64(userID), nil
+}
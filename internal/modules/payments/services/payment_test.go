@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	"github.com/internet-banking-ul/internal/config"
+	approvalService "github.com/internet-banking-ul/internal/modules/approvals/services"
+	companyPersonModel "github.com/internet-banking-ul/internal/modules/company_person/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/modules/payments/dto"
+	paymentModel "github.com/internet-banking-ul/internal/modules/payments/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/internet-banking-ul/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireAPIError(t *testing.T, err error, id string) {
+	t.Helper()
+
+	apiErr := apiErrors.ParseError(err)
+	require.NotNil(t, apiErr, "%v", err)
+	assert.Equal(t, apiErrors.ThrowError(id).Error(), apiErr.Error())
+}
+
+func requireFieldError(t *testing.T, err error, field, code string) {
+	t.Helper()
+
+	apiErr := apiErrors.ParseError(err)
+	require.NotNil(t, apiErr, "%v", err)
+	require.Len(t, apiErr.Fields, 1, "%v", apiErr.Fields)
+	assert.Equal(t, field, apiErr.Fields[0].Field)
+	assert.Equal(t, code, apiErr.Fields[0].Code)
+}
+
+func customerContext(customerID int64, userID int32) context.Context {
+	return signerContext(customerID, userID, companyPersonModel.SignLevelNone)
+}
+
+// signerContext is the context of the user acting for the customer with the sign level, see middles.Require
+func signerContext(customerID int64, userID int32, signLevel string) context.Context {
+	ctx := context.WithValue(context.Background(), utils.ContextHolderKey, &sync.Map{})
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyID, customerID)
+	utils.SetAttribute(ctx, utils.AttributeCurrentUserID, userID)
+	utils.SetAttribute(ctx, utils.AttributeCurrentCompanyPersons, companyPersonModel.CompanyPersonList{
+		{ID: int64(userID), CompanyID: customerID, UserAccountID: int64(userID), SignLevel: signLevel, OrganizationRole: "DIRECTOR"},
+	})
+	return ctx
+}
+
+// newTestService signs the payments by the rule A+B
+func newTestService(t *testing.T, db *storage.DB) *PaymentServiceImpl {
+	t.Helper()
+
+	approvals, err := approvalService.NewApprovalService(db, config.Approvals{DefaultRule: "A+B", TTL: time.Hour, ExpireInterval: time.Minute})
+	require.NoError(t, err)
+	return NewPaymentService(db, approvals)
+}
+
+func TestPaymentServiceImpl_Lifecycle(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	for _, id := range []int{10, 20} {
+		storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+			VALUES (?, 'LEGAL', ?, 'Company', 'Company LLP', 'TOO', '17', '050140000120')`, id, id)
+	}
+	opened := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	storagetest.Exec(t, db, `INSERT INTO ACCOUNT (ID, CUSTOMER_ID, IBAN, CURRENCY, STATUS, NAME, AVAILABLE_BALANCE, LEDGER_BALANCE, OPENED_AT) VALUES
+		(1, 10, 'KZ86125KZT5004100100', 'KZT', 'ACTIVE', 'Current', 100000, 100000, ?),
+		(2, 10, 'KZ90601A861000000123', 'KZT', 'BLOCKED', 'Blocked', 0, 0, ?)`, opened, opened)
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, SIGN_LEVEL, ORGANIZATION_ROLE) VALUES
+		(101, 'cp-101', 10, 101, 'B', 'ACCOUNTANT'),
+		(102, 'cp-102', 10, 102, 'A', 'DIRECTOR')`)
+
+	now := time.Date(2022, 6, 15, 10, 0, 0, 0, time.Local)
+	s := newTestService(t, db)
+	s.Now = func() time.Time { return now }
+
+	ctx := customerContext(10, 100)
+	req := dto.PaymentRequest{
+		AccountID:          1,
+		Amount:             "1500.50",
+		BeneficiaryName:    "Supplier LLP",
+		BeneficiaryTaxCode: "050140000120",
+		BeneficiaryIBAN:    "KZ90601A861000000123",
+		BeneficiaryCode:    "17",
+		SenderCode:         "17",
+		PurposeCode:        "710",
+		Purpose:            "Payment for goods",
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		invalid := req
+		invalid.Amount = "1500.505"
+		_, err := s.Create(ctx, invalid)
+		requireFieldError(t, err, "amount", apiErrors.FieldInvalidFormat)
+
+		invalid = req
+		invalid.AccountID = 2
+		_, err = s.Create(ctx, invalid)
+		requireFieldError(t, err, "accountID", apiErrors.FieldInactive)
+
+		invalid = req
+		invalid.PurposeCode = "71"
+		_, err = s.Create(ctx, invalid)
+		requireFieldError(t, err, "purposeCode", apiErrors.FieldInvalidFormat)
+
+		yesterday := now.AddDate(0, 0, -1)
+		invalid = req
+		invalid.ValueDate = &yesterday
+		_, err = s.Create(ctx, invalid)
+		requireFieldError(t, err, "valueDate", apiErrors.FieldInvalidRange)
+
+		_, err = s.Create(customerContext(20, 200), req)
+		requireFieldError(t, err, "accountID", apiErrors.FieldNotFound)
+	})
+
+	payment, err := s.Create(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, paymentModel.StatusDraft, payment.Status)
+	assert.Equal(t, "1500.50", payment.Amount)
+	assert.Equal(t, "KZT", payment.Currency)
+	assert.Equal(t, int64(100), payment.CreatedBy)
+
+	// another customer doesn't see the payment
+	_, err = s.ByID(customerContext(20, 200), payment.ID)
+	requireAPIError(t, err, apiErrors.PaymentNotFound)
+
+	_, err = s.Send(ctx, payment.ID)
+	requireAPIError(t, err, apiErrors.PaymentStatusInvalid)
+
+	amount := "2000"
+	payment, err = s.Update(ctx, payment.ID, dto.PaymentPatchRequest{Amount: &amount})
+	require.NoError(t, err)
+	assert.Equal(t, "2000.00", payment.Amount)
+
+	// the approval needs the signatures of A and B
+	accountant, director := signerContext(10, 101, "B"), signerContext(10, 102, "A")
+	payment, err = s.Sign(accountant, payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, paymentModel.StatusDraft, payment.Status)
+	assert.Nil(t, payment.SignedBy)
+
+	_, err = s.Update(ctx, payment.ID, dto.PaymentPatchRequest{Amount: &amount})
+	requireAPIError(t, err, apiErrors.PaymentOnApproval)
+	_, err = s.Sign(accountant, payment.ID)
+	requireAPIError(t, err, apiErrors.ApprovalAlreadySigned)
+
+	payment, err = s.Sign(director, payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, paymentModel.StatusSigned, payment.Status)
+	require.NotNil(t, payment.SignedBy)
+	assert.Equal(t, int64(102), *payment.SignedBy)
+
+	_, err = s.Update(ctx, payment.ID, dto.PaymentPatchRequest{Amount: &amount})
+	requireAPIError(t, err, apiErrors.PaymentStatusInvalid)
+
+	storagetest.Exec(t, db, `UPDATE ACCOUNT SET AVAILABLE_BALANCE = 199999 WHERE ID = 1`)
+	_, err = s.Send(ctx, payment.ID)
+	requireAPIError(t, err, apiErrors.PaymentInsufficientFunds)
+
+	storagetest.Exec(t, db, `UPDATE ACCOUNT SET AVAILABLE_BALANCE = 200000, LEDGER_BALANCE = 250000 WHERE ID = 1`)
+	payment, err = s.Send(ctx, payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, paymentModel.StatusSent, payment.Status)
+	assert.Equal(t, [2]int64{0, 250000}, balances(t, db, 1), "the amount is held")
+
+	_, err = s.Sign(director, payment.ID)
+	requireAPIError(t, err, apiErrors.PaymentStatusInvalid)
+
+	// the result of the bank
+	_, err = s.Reject(ctx, payment.ID, dto.PaymentRejectRequest{Reason: " "})
+	requireFieldError(t, err, "reason", apiErrors.FieldRequired)
+	payment, err = s.Execute(ctx, payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, paymentModel.StatusExecuted, payment.Status)
+	assert.NotNil(t, payment.ResolvedAt)
+	assert.Equal(t, [2]int64{0, 50000}, balances(t, db, 1), "the amount is booked")
+	_, err = s.Reject(ctx, payment.ID, dto.PaymentRejectRequest{Reason: "Closed beneficiary account"})
+	requireAPIError(t, err, apiErrors.PaymentStatusInvalid)
+
+	list, count, _, err := s.List(ctx, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, paymentModel.StatusExecuted, list[0].Status)
+
+	// create, update, sign, send and execute are audited
+	var audited int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(1) FROM AUDIT_LOG WHERE ENTITY = 'PAYMENT' AND CUSTOMER_ID = 10`).Scan(&audited))
+	assert.Equal(t, 5, audited)
+	require.NoError(t, db.QueryRow(`SELECT COUNT(1) FROM AUDIT_LOG WHERE ENTITY = 'APPROVAL' AND CUSTOMER_ID = 10`).Scan(&audited))
+	assert.Equal(t, 2, audited, "the signatures of the approval")
+}
+
+func TestPaymentServiceImpl_Reject(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+		VALUES (10, 'LEGAL', '10', 'Company', 'Company LLP', 'TOO', '17', '050140000120')`)
+	storagetest.Exec(t, db, `INSERT INTO ACCOUNT (ID, CUSTOMER_ID, IBAN, CURRENCY, STATUS, NAME, AVAILABLE_BALANCE, LEDGER_BALANCE, OPENED_AT)
+		VALUES (1, 10, 'KZ86125KZT5004100100', 'KZT', 'ACTIVE', 'Current', 1500, 1500, ?)`, time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC))
+	storagetest.Exec(t, db, `INSERT INTO COMPANY_PERSON (ID, EXTERNAL_ID, COMPANY_ID, USER_ACCOUNT_ID, SIGN_LEVEL, ORGANIZATION_ROLE) VALUES
+		(101, 'cp-101', 10, 101, 'B', 'ACCOUNTANT'),
+		(102, 'cp-102', 10, 102, 'A', 'DIRECTOR')`)
+
+	s := newTestService(t, db)
+	ctx := customerContext(10, 100)
+	// signed returns the signed payment of 10.00
+	signed := func() int64 {
+		payment, err := s.Create(ctx, dto.PaymentRequest{
+			AccountID: 1, Amount: "10", BeneficiaryName: "Supplier LLP", BeneficiaryTaxCode: "050140000120",
+			BeneficiaryIBAN: "KZ90601A861000000123", BeneficiaryCode: "17", SenderCode: "17", PurposeCode: "710", Purpose: "Goods",
+		})
+		require.NoError(t, err)
+		_, err = s.Sign(signerContext(10, 101, "B"), payment.ID)
+		require.NoError(t, err)
+		_, err = s.Sign(signerContext(10, 102, "A"), payment.ID)
+		require.NoError(t, err)
+		return payment.ID
+	}
+
+	first, second := signed(), signed()
+	_, err := s.Send(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, [2]int64{500, 1500}, balances(t, db, 1))
+
+	// the held amount can't be sent again
+	_, err = s.Send(ctx, second)
+	requireAPIError(t, err, apiErrors.PaymentInsufficientFunds)
+
+	payment, err := s.Reject(ctx, first, dto.PaymentRejectRequest{Reason: " Closed beneficiary account "})
+	require.NoError(t, err)
+	assert.Equal(t, paymentModel.StatusRejected, payment.Status)
+	require.NotNil(t, payment.RejectReason)
+	assert.Equal(t, "Closed beneficiary account", *payment.RejectReason)
+	assert.Equal(t, [2]int64{1500, 1500}, balances(t, db, 1), "the hold is released")
+
+	_, err = s.Execute(ctx, first)
+	requireAPIError(t, err, apiErrors.PaymentStatusInvalid)
+	_, err = s.Send(ctx, second)
+	require.NoError(t, err)
+}
+
+// balances returns the available and the ledger balance of the account
+func balances(t *testing.T, db *storage.DB, accountID int64) (result [2]int64) {
+	t.Helper()
+
+	require.NoError(t, db.QueryRow(`SELECT AVAILABLE_BALANCE, LEDGER_BALANCE FROM ACCOUNT WHERE ID = ?`, accountID).Scan(&result[0], &result[1]))
+	return result
+}
+
+func TestPaymentServiceImpl_StaleVersion(t *testing.T) {
+	db := storagetest.NewSQLite(t)
+	storagetest.Exec(t, db, `INSERT INTO CUSTOMER (ID, PERSON_TYPE, EXTERNAL_ID, NAME, FULL_NAME, OWNERSHIP, RESIDENCY_AND_ECONOMIC_CODE, TAX_CODE)
+		VALUES (10, 'LEGAL', '10', 'Company', 'Company LLP', 'TOO', '17', '050140000120')`)
+	storagetest.Exec(t, db, `INSERT INTO ACCOUNT (ID, CUSTOMER_ID, IBAN, CURRENCY, STATUS, NAME, AVAILABLE_BALANCE, LEDGER_BALANCE, OPENED_AT)
+		VALUES (1, 10, 'KZ86125KZT5004100100', 'KZT', 'ACTIVE', 'Current', 0, 0, ?)`, time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	s := newTestService(t, db)
+	ctx := customerContext(10, 100)
+	payment, err := s.Create(ctx, dto.PaymentRequest{
+		AccountID: 1, Amount: "10", BeneficiaryName: "Supplier LLP", BeneficiaryTaxCode: "050140000120",
+		BeneficiaryIBAN: "KZ90601A861000000123", BeneficiaryCode: "17", SenderCode: "17", PurposeCode: "710", Purpose: "Goods",
+	})
+	require.NoError(t, err)
+
+	stale, err := s.PaymentRepository.ByID(ctx, payment.ID)
+	require.NoError(t, err)
+
+	amount := "20"
+	_, err = s.Update(ctx, payment.ID, dto.PaymentPatchRequest{Amount: &amount})
+	require.NoError(t, err)
+
+	_, err = s.save(ctx, stale, stale)
+	requireAPIError(t, err, apiErrors.PaymentConflict)
+}
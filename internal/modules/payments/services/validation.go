@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/internet-banking-ul/helpers/apiErrors"
+	paymentModel "github.com/internet-banking-ul/internal/modules/payments/entities"
+	"github.com/internet-banking-ul/tools"
+)
+
+// purposeCodePattern - KNP, the 3 digits code of the payment purpose
+const purposeCodePattern = "^[0-9]{3}$"
+
+// rejectReasonMaxLength - the length of PAYMENT_ORDER.REJECT_REASON
+const rejectReasonMaxLength = 512
+
+// max lengths of the PAYMENT_ORDER columns
+var paymentFieldLength = map[string]int{
+	"number":          64,
+	"beneficiaryName": 255,
+	"purpose":         420,
+}
+
+// validatePayment checks the fields of the payment about to be written, the amount and the account
+// are checked by the service
+func validatePayment(fields *apiErrors.FieldErrors, payment paymentModel.Payment) {
+	if payment.AccountID <= 0 {
+		fields.Add("accountID", apiErrors.FieldRequired, "accountID is required")
+	}
+
+	required := map[string]string{
+		"beneficiaryName":    payment.BeneficiaryName,
+		"beneficiaryTaxCode": payment.BeneficiaryTaxCode,
+		"beneficiaryIBAN":    payment.BeneficiaryIBAN,
+		"beneficiaryCode":    payment.BeneficiaryCode,
+		"senderCode":         payment.SenderCode,
+		"purposeCode":        payment.PurposeCode,
+		"purpose":            payment.Purpose,
+	}
+	for _, field := range []string{"beneficiaryName", "beneficiaryTaxCode", "beneficiaryIBAN", "beneficiaryCode", "senderCode", "purposeCode", "purpose"} {
+		if required[field] == "" {
+			fields.Add(field, apiErrors.FieldRequired, fmt.Sprintf("%s is required", field))
+		}
+	}
+
+	values := map[string]string{
+		"number":          payment.Number,
+		"beneficiaryName": payment.BeneficiaryName,
+		"purpose":         payment.Purpose,
+	}
+	for _, field := range []string{"number", "beneficiaryName", "purpose"} {
+		if max := paymentFieldLength[field]; utf8.RuneCountInString(values[field]) > max {
+			fields.Add(field, apiErrors.FieldTooLong, fmt.Sprintf("%s must be at most %d characters", field, max))
+		}
+	}
+
+	if code := payment.BeneficiaryTaxCode; code != "" {
+		if !tools.ValidateTaxCode(code) {
+			fields.Add("beneficiaryTaxCode", apiErrors.FieldInvalidFormat, "beneficiaryTaxCode must be a BIN or IIN of 12 digits")
+		} else if !tools.ValidateTaxCodeChecksum(code) {
+			fields.Add("beneficiaryTaxCode", apiErrors.FieldInvalidChecksum, "beneficiaryTaxCode control digit is invalid")
+		}
+	}
+
+	if payment.BeneficiaryIBAN != "" && !tools.ValidateKZIBAN(payment.BeneficiaryIBAN) {
+		fields.Add("beneficiaryIBAN", apiErrors.FieldInvalidFormat, "beneficiaryIBAN must be a valid Kazakhstan IBAN")
+	}
+
+	codes := map[string]string{
+		"beneficiaryCode": payment.BeneficiaryCode,
+		"senderCode":      payment.SenderCode,
+	}
+	for _, field := range []string{"beneficiaryCode", "senderCode"} {
+		if codes[field] != "" && !tools.ValidateResidencyAndEconomicCode(codes[field]) {
+			fields.Add(field, apiErrors.FieldInvalidFormat,
+				fmt.Sprintf("%s must be 2 digits, the first one is 1 (resident) or 2 (non-resident)", field))
+		}
+	}
+
+	if payment.PurposeCode != "" && !tools.CheckWithRegExp(payment.PurposeCode, purposeCodePattern) {
+		fields.Add("purposeCode", apiErrors.FieldInvalidFormat, "purposeCode (KNP) must be 3 digits")
+	}
+}
+
+// validateRejectReason checks the reason of the rejection by the bank
+func validateRejectReason(reason string) apiErrors.FieldErrors {
+	var fields apiErrors.FieldErrors
+	if reason == "" {
+		fields.Add("reason", apiErrors.FieldRequired, "reason is required")
+	} else if utf8.RuneCountInString(reason) > rejectReasonMaxLength {
+		fields.Add("reason", apiErrors.FieldTooLong, fmt.Sprintf("reason must be at most %d characters", rejectReasonMaxLength))
+	}
+	return fields
+}
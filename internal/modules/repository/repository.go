@@ -0,0 +1,370 @@
+// Package repository is the query side shared by the module repositories, on top of storage
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/metrics"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"go.uber.org/zap"
+)
+
+// Repository is the query side shared by the modules for the entity T read from Table. The columns are
+// the `db` tags of the fields of T in their order, untagged and `db:"-"` fields are skipped, fields of
+// embedded structs are included. Module repositories embed it and add their own queries.
+type Repository[T any] struct {
+	DB    *storage.DB
+	Table string
+	// ListColumns - the sort, search and filter whitelist of List and Count, its Key is the primary key
+	// column of GetByID ("ID" when empty)
+	ListColumns entities.ListColumns
+	// Scope is added to every query, e.g. sq.Eq{"IS_DELETED": 0} hides the deleted rows; nil for none
+	Scope sq.Sqlizer
+
+	columns []string
+	fields  map[string][]int
+}
+
+// New maps the `db` tags of T, it panics when T is not a struct or has no tagged fields
+func New[T any](db *storage.DB, table string, listColumns entities.ListColumns, scope sq.Sqlizer) *Repository[T] {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("repository: repository of %s, a struct is required", t))
+	}
+
+	repo := &Repository[T]{
+		DB:          db,
+		Table:       table,
+		ListColumns: listColumns,
+		Scope:       scope,
+		fields:      map[string][]int{},
+	}
+	repo.mapFields(t, nil)
+	if len(repo.columns) == 0 {
+		panic(fmt.Sprintf("repository: %s has no db tags", t))
+	}
+
+	return repo
+}
+
+func (repo *Repository[T]) mapFields(t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := append(append([]int{}, index...), i)
+
+		tag, tagged := field.Tag.Lookup("db")
+		column := strings.Split(tag, ",")[0]
+		switch {
+		case !tagged && field.Anonymous && field.Type.Kind() == reflect.Struct:
+			repo.mapFields(field.Type, path)
+		case !tagged || column == "" || column == "-" || !field.IsExported():
+		default:
+			repo.columns = append(repo.columns, column)
+			repo.fields[column] = path
+		}
+	}
+}
+
+// Columns returns the selected columns in the order Scan reads them
+func (repo *Repository[T]) Columns() []string {
+	return append([]string{}, repo.columns...)
+}
+
+// Scan reads a row selected with Columns into item
+func (repo *Repository[T]) Scan(row sq.RowScanner, item *T) error {
+	v := reflect.ValueOf(item).Elem()
+	dest := make([]interface{}, len(repo.columns))
+	for i, column := range repo.columns {
+		dest[i] = v.FieldByIndex(repo.fields[column]).Addr().Interface()
+	}
+	return row.Scan(dest...)
+}
+
+// Select returns the query of the columns of T in Scope
+func (repo *Repository[T]) Select() sq.SelectBuilder {
+	q := repo.DB.Builder().Select(repo.columns...).From(repo.Table)
+	if repo.Scope != nil {
+		q = q.Where(repo.Scope)
+	}
+	return q
+}
+
+func (repo *Repository[T]) key() string {
+	if repo.ListColumns.Key != "" {
+		return repo.ListColumns.Key
+	}
+	return "ID"
+}
+
+// GetByID returns sql.ErrNoRows when there is no row with the key in Scope
+func (repo *Repository[T]) GetByID(ctx context.Context, id int64) (result T, err error) {
+	return repo.get(ctx, "GetByID", sq.Eq{repo.key(): id})
+}
+
+// LockByID locks the row with the key in Scope until the end of the transaction of ctx (SELECT ... FOR UPDATE),
+// sql.ErrNoRows when there is none. The changes checked against other rows lock their common parent row first,
+// so the concurrent ones are checked one after another.
+func (repo *Repository[T]) LockByID(ctx context.Context, id int64) error {
+	if repo.DB == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("LockByID")
+
+	q := repo.DB.Builder().Select(repo.key()).From(repo.Table).Where(sq.Eq{repo.key(): id})
+	if repo.Scope != nil {
+		q = q.Where(repo.Scope)
+	}
+	if forUpdate := repo.DB.Dialect.ForUpdateSql(); forUpdate != "" {
+		q = q.Suffix(forUpdate)
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		l.Error("ToSql", zap.Error(err))
+		return err
+	}
+
+	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
+
+	var key int64
+	return q.RunWith(repo.DB.Runner(ctx)).QueryRowContext(repo.queryName(ctx, "LockByID")).Scan(&key)
+}
+
+// GetBy returns the row matching where, sql.ErrNoRows when there is none
+func (repo *Repository[T]) GetBy(ctx context.Context, where sq.Sqlizer) (result T, err error) {
+	return repo.get(ctx, "GetBy", where)
+}
+
+func (repo *Repository[T]) get(ctx context.Context, name string, where sq.Sqlizer) (result T, err error) {
+	if repo.DB == nil {
+		err = fmt.Errorf("db is nil")
+		return result, err
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named(name)
+
+	q := repo.Select().Where(where)
+
+	sql, args, e := q.ToSql()
+	if e != nil {
+		l.Error("ToSql", zap.Error(e))
+		return result, e
+	}
+
+	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
+
+	err = repo.Scan(q.RunWith(repo.DB.Runner(ctx)).QueryRowContext(repo.queryName(ctx, name)), &result)
+	return result, err
+}
+
+// queryName names the queries of the method for the metrics, the methods are shared by every table
+func (repo *Repository[T]) queryName(ctx context.Context, method string) context.Context {
+	return metrics.WithQueryName(ctx, repo.Table+"."+method)
+}
+
+// FindBy returns every row matching where ordered by orderBy, by the key when it's empty
+func (repo *Repository[T]) FindBy(ctx context.Context, where sq.Sqlizer, orderBy ...string) (results []*T, err error) {
+	if repo.DB == nil {
+		err = fmt.Errorf("db is nil")
+		return results, err
+	}
+
+	if len(orderBy) == 0 {
+		orderBy = []string{repo.key()}
+	}
+
+	return repo.find(repo.queryName(ctx, "FindBy"), logger.WorkLoggerWithContext(ctx).Named("FindBy"), repo.Select().Where(where).OrderBy(orderBy...))
+}
+
+func (repo *Repository[T]) find(ctx context.Context, l *zap.Logger, q sq.SelectBuilder) (results []*T, err error) {
+	sql, args, e := q.ToSql()
+	if e != nil {
+		l.Error("ToSql", zap.Error(e))
+		return results, e
+	}
+
+	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := q.RunWith(repo.DB.Runner(ctx)).QueryContext(ctx)
+	if err != nil {
+		l.Error("QueryContext", zap.Error(err))
+		return results, err
+	}
+	defer rows.Close()
+
+	results = []*T{}
+	for rows.Next() {
+		row := new(T)
+		if err := repo.Scan(rows, row); err != nil {
+			l.Error("Scan", zap.Error(err))
+			return results, err
+		}
+
+		results = append(results, row)
+	}
+
+	if err := rows.Close(); err != nil {
+		return results, err
+	}
+
+	return results, rows.Err()
+}
+
+// List returns the page of the rows selected by baseFilter and where, the total of the filtered rows
+// and the cursor of the next page ("" on the last page). where narrows the list, e.g. to the customer.
+func (repo *Repository[T]) List(ctx context.Context, baseFilter entities.BasePaginationFilters, where ...sq.Sqlizer) (results []*T, count int64, nextCursor string, err error) {
+	if repo.DB == nil {
+		err = fmt.Errorf("db is nil")
+		return results, count, nextCursor, err
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("List")
+
+	orderBy, err := baseFilter.OrderBy(repo.ListColumns)
+	if err != nil {
+		return results, count, nextCursor, err
+	}
+
+	keyset, err := baseFilter.KeysetPredicate(repo.ListColumns)
+	if err != nil {
+		return results, count, nextCursor, err
+	}
+
+	listWhere, err := repo.listWhere(baseFilter, where)
+	if err != nil {
+		return results, count, nextCursor, err
+	}
+
+	count, err = repo.Count(ctx, baseFilter, where...)
+	if err != nil {
+		l.Error("Count", zap.Error(err))
+		return results, count, nextCursor, err
+	}
+
+	if keyset != nil {
+		listWhere = append(listWhere, keyset)
+	}
+
+	// one row more than the page size tells whether there is a next page
+	q := repo.Select().
+		Where(listWhere).
+		OrderBy(orderBy...).
+		Offset(baseFilter.GetOffset()).
+		Limit(baseFilter.GetSize() + 1)
+
+	results, err = repo.find(repo.queryName(ctx, "List"), l, q)
+	if err != nil {
+		return results, count, nextCursor, err
+	}
+
+	if uint64(len(results)) > baseFilter.GetSize() {
+		results = results[:baseFilter.GetSize()]
+		last := results[len(results)-1]
+		nextCursor, err = baseFilter.NextCursor(repo.ListColumns, repo.value(last, baseFilter.SortColumn(repo.ListColumns)), repo.id(last))
+	}
+
+	return results, count, nextCursor, err
+}
+
+// Count returns the number of rows matching where, the search and the filters of baseFilter
+func (repo *Repository[T]) Count(ctx context.Context, baseFilter entities.BasePaginationFilters, where ...sq.Sqlizer) (count int64, err error) {
+	if repo.DB == nil {
+		err = fmt.Errorf("db is nil")
+		return
+	}
+
+	l := logger.WorkLoggerWithContext(ctx).Named("Count")
+
+	listWhere, err := repo.listWhere(baseFilter, where)
+	if err != nil {
+		return
+	}
+
+	q := repo.DB.Builder().Select("COUNT(1)").From(repo.Table).Where(listWhere)
+
+	sql, args, e := q.ToSql()
+	if e != nil {
+		l.Error("ToSql", zap.Error(e))
+		return count, e
+	}
+
+	l.Debug("Info", zap.String("sql", sql), zap.Any("args", args))
+
+	err = q.RunWith(repo.DB.Runner(ctx)).QueryRowContext(repo.queryName(ctx, "Count")).Scan(&count)
+	if err != nil {
+		l.Error("QueryRowContext", zap.Error(err))
+		return
+	}
+
+	return
+}
+
+// listWhere - the WHERE shared by List and Count: Scope, where, the search and the filters of baseFilter
+func (repo *Repository[T]) listWhere(baseFilter entities.BasePaginationFilters, where []sq.Sqlizer) (sq.And, error) {
+	result := sq.And{}
+	if repo.Scope != nil {
+		result = append(result, repo.Scope)
+	}
+	for _, pred := range where {
+		if pred != nil {
+			result = append(result, pred)
+		}
+	}
+
+	if search := baseFilter.SearchPredicate(repo.ListColumns); search != nil {
+		result = append(result, search)
+	}
+
+	filter, err := baseFilter.FilterPredicate(repo.ListColumns)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil {
+		result = append(result, filter)
+	}
+
+	return result, nil
+}
+
+// value returns the value of the column of item, the value of a driver.Valuer is unwrapped;
+// it's kept in the cursor
+func (repo *Repository[T]) value(item *T, column string) interface{} {
+	index, ok := repo.fields[column]
+	if !ok {
+		return nil
+	}
+
+	value := reflect.ValueOf(item).Elem().FieldByIndex(index).Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+// id returns the key of item, 0 when the key isn't an integer
+func (repo *Repository[T]) id(item *T) int64 {
+	index, ok := repo.fields[repo.key()]
+	if !ok {
+		return 0
+	}
+
+	v := reflect.ValueOf(item).Elem().FieldByIndex(index)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	default:
+		return 0
+	}
+}
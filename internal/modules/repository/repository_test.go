@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/internet-banking-ul/internal/modules/entities"
+	"github.com/internet-banking-ul/internal/modules/repository"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type audited struct {
+	IsDeleted int `db:"IS_DELETED"`
+}
+
+type widget struct {
+	ID    int64          `db:"ID"`
+	Name  string         `db:"NAME"`
+	Color sql.NullString `db:"COLOR"`
+	audited
+	Parts   []string `db:"-"`
+	Comment string
+}
+
+var widgetListColumns = entities.ListColumns{
+	Sortable:    map[string]string{"id": "ID", "name": "NAME", "color": "COLOR"},
+	DefaultSort: "id",
+	Key:         "ID",
+	Searchable:  []string{"NAME"},
+	Filterable: map[string]entities.FilterField{
+		"color": {Column: "COLOR", Ops: []string{entities.FilterEq, entities.FilterIn}},
+	},
+}
+
+func newWidgetRepository(t *testing.T) *repository.Repository[widget] {
+	db := storagetest.NewSQLite(t)
+	storagetest.Exec(t, db, "CREATE TABLE WIDGET (ID INTEGER PRIMARY KEY, NAME TEXT NOT NULL, COLOR TEXT, IS_DELETED INTEGER NOT NULL)")
+	for _, row := range [][]interface{}{
+		{1, "bolt", "red", 0},
+		{2, "nut", nil, 0},
+		{3, "gear", "red", 0},
+		{4, "axle", "blue", 1},
+		{5, "cog", "red", 0},
+	} {
+		storagetest.Exec(t, db, "INSERT INTO WIDGET (ID, NAME, COLOR, IS_DELETED) VALUES (?, ?, ?, ?)", row...)
+	}
+
+	return repository.New[widget](db, "WIDGET", widgetListColumns, sq.Eq{"IS_DELETED": 0})
+}
+
+func TestRepository_Get(t *testing.T) {
+	repo := newWidgetRepository(t)
+	ctx := context.Background()
+
+	assert.Equal(t, []string{"ID", "NAME", "COLOR", "IS_DELETED"}, repo.Columns())
+
+	w, err := repo.GetByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, widget{ID: 2, Name: "nut"}, w)
+
+	w, err = repo.GetBy(ctx, sq.Eq{"NAME": "gear"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), w.ID)
+	assert.Equal(t, "red", w.Color.String)
+
+	_, err = repo.GetByID(ctx, 4)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "out of scope")
+
+	require.NoError(t, repo.DB.WithinTx(ctx, func(ctx context.Context) error {
+		assert.NoError(t, repo.LockByID(ctx, 2))
+		assert.ErrorIs(t, repo.LockByID(ctx, 4), sql.ErrNoRows, "out of scope")
+		return nil
+	}))
+
+	list, err := repo.FindBy(ctx, sq.Eq{"COLOR": "red"})
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, []int64{1, 3, 5}, []int64{list[0].ID, list[1].ID, list[2].ID})
+
+	list, err = repo.FindBy(ctx, sq.Eq{"COLOR": "red"}, "NAME DESC")
+	require.NoError(t, err)
+	assert.Equal(t, "gear", list[0].Name)
+}
+
+func TestRepository_List(t *testing.T) {
+	repo := newWidgetRepository(t)
+	ctx := context.Background()
+
+	filter := entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Size: 2, Sort: "name"}}
+	filter.AddFilter("color", entities.FilterEq, "red")
+
+	list, count, cursor, err := repo.List(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	require.Len(t, list, 2)
+	assert.Equal(t, "bolt", list[0].Name)
+	assert.Equal(t, "cog", list[1].Name)
+	require.NotEmpty(t, cursor)
+
+	filter.Cursor = cursor
+	list, count, cursor, err = repo.List(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	require.Len(t, list, 1)
+	assert.Equal(t, "gear", list[0].Name)
+	assert.Empty(t, cursor)
+
+	count, err = repo.Count(ctx, entities.BasePaginationFilters{}, sq.NotEq{"ID": 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	_, _, _, err = repo.List(ctx, entities.BasePaginationFilters{BaseFilter: entities.BaseFilter{Sort: "comment"}})
+	assert.Error(t, err)
+}
+
+func TestNewRepository_NoTags(t *testing.T) {
+	assert.Panics(t, func() {
+		repository.New[struct{ Name string }](nil, "WIDGET", widgetListColumns, nil)
+	})
+}
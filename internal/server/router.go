@@ -1,25 +1,47 @@
 package server
 
 import (
-	"database/sql"
+	"context"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
-	"github.com/gofiber/fiber/v2/middleware/etag"
 	"github.com/gofiber/fiber/v2/middleware/pprof"
+	accountHandlers "github.com/internet-banking-ul/internal/handlers/accounts"
+	approvalHandlers "github.com/internet-banking-ul/internal/handlers/approvals"
+	auditHandlers "github.com/internet-banking-ul/internal/handlers/audit"
 	companyPersonHandlers "github.com/internet-banking-ul/internal/handlers/company_person"
 	customerHandlers "github.com/internet-banking-ul/internal/handlers/customer"
+	healthHandlers "github.com/internet-banking-ul/internal/handlers/health"
+	metricsHandlers "github.com/internet-banking-ul/internal/handlers/metrics"
+	paymentHandlers "github.com/internet-banking-ul/internal/handlers/payments"
+	"github.com/internet-banking-ul/internal/config"
 	"github.com/internet-banking-ul/internal/middles"
+	accountService "github.com/internet-banking-ul/internal/modules/accounts/services"
+	approvalService "github.com/internet-banking-ul/internal/modules/approvals/services"
+	auditService "github.com/internet-banking-ul/internal/modules/audit/services"
+	companyPersonRepo "github.com/internet-banking-ul/internal/modules/company_person/repositories"
 	companyPersonService "github.com/internet-banking-ul/internal/modules/company_person/services"
 	customerService "github.com/internet-banking-ul/internal/modules/customer/services"
+	paymentService "github.com/internet-banking-ul/internal/modules/payments/services"
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/health"
 	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/metrics"
+	"github.com/internet-banking-ul/modules/tracing"
 )
 
-//NewServer all rest api
-func NewServer(db *sql.DB) *fiber.App {
+//NewServer all rest api, every /api/v1 route requires the bearer token checked with auth.
+//The background jobs of the modules run until ctx is done. The returned registry holds the readiness checks.
+func NewServer(ctx context.Context, db *storage.DB, cfg *config.Config, auth middles.AuthenticateConfig) (*fiber.App, *health.Registry, error) {
+	approvals, err := approvalService.NewApprovalService(db, cfg.Approvals)
+	if err != nil {
+		return nil, nil, err
+	}
+	go approvals.RunExpiry(ctx, cfg.Approvals.ExpireInterval)
+
 	app := fiber.New(fiber.Config{
 		Prefork:       false,
 		CaseSensitive: true,
@@ -33,10 +55,22 @@ func NewServer(db *sql.DB) *fiber.App {
 
 	app.Use(pprof.New())
 
-	app.Use(etag.New(etag.Config{
-		Weak: false,
-		Next: nil,
-	}))
+	if cfg.Metrics.Enabled {
+		setupMetrics(app, db, cfg.Metrics)
+	}
+
+	// the probes are registered before the logger, the orchestrator calls them every few seconds
+	registry := newHealthRegistry(db, cfg)
+	registry.Register(approvals.Expiry)
+	healthHandlers.NewHealthHandler(registry).RegisterHealth(app)
+
+	app.Use(middles.ETag())
+
+	// added after the metrics hook, the spans of the queries wrap their metrics
+	if cfg.Tracing.Exporter != "" {
+		db.AddQueryHook(tracing.QueryHook(metrics.QueryName))
+	}
+	app.Use(middles.Tracing())
 
 	app.Use(middles.Logger(3*time.Second, logger.WorkLogger))
 
@@ -44,9 +78,53 @@ func NewServer(db *sql.DB) *fiber.App {
 		Level: compress.LevelBestSpeed, // Compress everything
 	}))
 
-	v1 := app.Group("/api/v1")
+	idempotency := middles.NewIdempotencyConfig(cfg.Idempotency, db)
+	if idempotency.Expiry != nil {
+		registry.Register(idempotency.Expiry)
+	}
+	go idempotency.RunExpiry(ctx, cfg.Idempotency.ExpireInterval)
+
+	companyPersons := middles.CompanyPersons(companyPersonRepo.NewCompanyPersonRepository(db).ActiveByUserAccountID)
+	v1 := app.Group("/api/v1", middles.SetupContextHolder(), middles.Authenticate(auth), companyPersons, middles.Idempotency(idempotency))
 	customerHandlers.NewCustomerHandler(customerService.NewCustomerService(db)).RegisterCustomer(v1)
 	companyPersonHandlers.NewCompanyPersonHandler(companyPersonService.NewCompanyPersonService(db)).RegisterCompanyPerson(v1)
+	approvalHandlers.NewApprovalHandler(approvals).RegisterApprovals(v1)
+	accountHandlers.NewAccountHandler(accountService.NewAccountService(db)).RegisterAccounts(v1)
+	paymentHandlers.NewPaymentHandler(paymentService.NewPaymentService(db, approvals)).RegisterPayments(v1)
+	auditHandlers.NewAuditHandler(auditService.NewAuditService(db)).RegisterAudit(v1)
+
+	return app, registry, nil
+}
+
+// newHealthRegistry checks the dependencies of every instance: the DB and its pool, the log file and the disk space
+func newHealthRegistry(db *storage.DB, cfg *config.Config) *health.Registry {
+	registry := health.NewRegistry(cfg.Health.CheckTimeout)
+	registry.Register(health.DBPing("db", db.DB))
+	if cfg.Health.MaxPoolSaturation > 0 {
+		registry.Register(health.DBPool("db_pool", db.DB, cfg.Health.MaxPoolSaturation))
+	}
+	if cfg.LogFile != "" {
+		registry.Register(health.Writable("log_file", cfg.LogFile))
+	}
+	if cfg.Health.MinFreeSpaceMB > 0 {
+		registry.Register(
+			health.DiskSpace("disk_data_dir", cfg.DataDir, cfg.Health.MinFreeSpaceMB),
+			health.DiskSpace("disk_temp_dir", cfg.TempDir, cfg.Health.MinFreeSpaceMB),
+		)
+	}
+	return registry
+}
+
+// setupMetrics serves /metrics, the metrics of the requests, the DB pool and the queries next to
+// the business counters of metrics.Default
+func setupMetrics(app *fiber.App, db *storage.DB, cfg config.Metrics) {
+	registry := metrics.NewRegistry()
+	registry.SetMaxSeries(cfg.MaxSeries)
+	metrics.Default.SetMaxSeries(cfg.MaxSeries)
+
+	metrics.NewDBStats(registry, db.DB)
+	db.AddQueryHook(metrics.NewQueries(registry).Hook)
 
-	return app
+	app.Use(middles.Metrics(registry))
+	metricsHandlers.NewMetricsHandler(registry, metrics.Default).RegisterMetrics(app)
 }
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/internet-banking-ul/internal/consts"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrUniqueViolation - the write broke a unique constraint or index, see Dialect.UniqueViolation
+var ErrUniqueViolation = errors.New("unique constraint violated")
+
+// Dialect describes the SQL differences between the supported databases
+type Dialect struct {
+	// Name is the value of db.dialect in config
+	Name string
+	// DriverName is the database/sql driver registered for the dialect
+	DriverName string
+	// Placeholder is used for every query built by Builder
+	Placeholder sq.PlaceholderFormat
+	// Pagination renders Limit/Offset of the builders
+	Pagination sq.PaginationFormat
+}
+
+var (
+	// Oracle - go-oci8, :1 placeholders, OFFSET n ROWS FETCH NEXT m ROWS ONLY
+	Oracle = Dialect{
+		Name:        consts.DialectOracle,
+		DriverName:  consts.DriverName,
+		Placeholder: sq.Colon,
+		Pagination:  sq.OffsetFetch,
+	}
+
+	// Postgres - lib/pq, $1 placeholders, LIMIT m OFFSET n
+	Postgres = Dialect{
+		Name:        consts.DialectPostgres,
+		DriverName:  "postgres",
+		Placeholder: sq.Dollar,
+		Pagination:  sq.LimitOffset,
+	}
+
+	// SQLite - go-sqlite3, ? placeholders, LIMIT m OFFSET n
+	SQLite = Dialect{
+		Name:        consts.DialectSQLite,
+		DriverName:  "sqlite3",
+		Placeholder: sq.Question,
+		Pagination:  sqlitePagination{},
+	}
+
+	dialects = map[string]Dialect{
+		Oracle.Name:   Oracle,
+		Postgres.Name: Postgres,
+		SQLite.Name:   SQLite,
+	}
+)
+
+// DialectByName returns the dialect registered for name
+func DialectByName(name string) (Dialect, error) {
+	if d, ok := dialects[name]; ok {
+		return d, nil
+	}
+	return Dialect{}, fmt.Errorf("unknown db dialect %q", name)
+}
+
+// Builder returns the squirrel StatementBuilder with the dialect's placeholder and pagination formats
+func (d Dialect) Builder() sq.StatementBuilderType {
+	return sq.StatementBuilder.
+		PlaceholderFormat(d.Placeholder).
+		PaginationFormat(d.Pagination)
+}
+
+// NextIDSql returns the query selecting the next primary key value.
+// Oracle and postgres use the sequence, SQLite has no sequences, so MAX(ID)+1
+// is taken, which is safe only inside a write transaction (SQLite locks the database).
+func (d Dialect) NextIDSql(sequence, table string) string {
+	switch d.Name {
+	case consts.DialectPostgres:
+		return fmt.Sprintf("SELECT nextval('%s')", sequence)
+	case consts.DialectSQLite:
+		return fmt.Sprintf("SELECT COALESCE(MAX(ID), 0) + 1 FROM %s", table)
+	default:
+		return fmt.Sprintf("SELECT %s.NEXTVAL FROM DUAL", sequence)
+	}
+}
+
+// ReleaseSavepointSql returns the statement releasing the savepoint, "" for Oracle which has none
+// (its savepoints live until the end of the transaction)
+func (d Dialect) ReleaseSavepointSql(name string) string {
+	if d.Name == consts.DialectOracle {
+		return ""
+	}
+	return "RELEASE SAVEPOINT " + name
+}
+
+// UniqueViolation wraps err in ErrUniqueViolation when the driver reports a broken unique constraint or index,
+// so the repositories let the services map a concurrent duplicate to their own error. Other errors are returned as is.
+func (d Dialect) UniqueViolation(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var unique bool
+	switch d.Name {
+	case consts.DialectPostgres:
+		var pqErr *pq.Error
+		unique = errors.As(err, &pqErr) && pqErr.Code == "23505"
+	case consts.DialectSQLite:
+		var sqliteErr sqlite3.Error
+		unique = errors.As(err, &sqliteErr) &&
+			(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+	default:
+		// go-oci8 reports the ORA code in the message only
+		unique = strings.HasPrefix(err.Error(), "ORA-00001:")
+	}
+
+	if unique {
+		return fmt.Errorf("%w: %s", ErrUniqueViolation, err)
+	}
+	return err
+}
+
+// ForUpdateSql returns the suffix of a SELECT locking its rows until the end of the transaction,
+// "" for SQLite whose write transactions lock the whole database
+func (d Dialect) ForUpdateSql() string {
+	if d.Name == consts.DialectSQLite {
+		return ""
+	}
+	return "FOR UPDATE"
+}
+
+// SetTransactionSql returns the statement applying opts as the first one of the transaction, "" when there is
+// nothing to apply or the driver applies opts in BeginTx. go-oci8 ignores sql.TxOptions, so Oracle sets them itself;
+// it has read committed and serializable transactions only, a read only one sees the data as of its start.
+func (d Dialect) SetTransactionSql(opts sql.TxOptions) (string, error) {
+	if d.Name != consts.DialectOracle {
+		return "", nil
+	}
+	if opts.ReadOnly {
+		return "SET TRANSACTION READ ONLY", nil
+	}
+	switch opts.Isolation {
+	case sql.LevelDefault:
+		return "", nil
+	case sql.LevelReadCommitted:
+		return "SET TRANSACTION ISOLATION LEVEL READ COMMITTED", nil
+	case sql.LevelSerializable:
+		return "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE", nil
+	default:
+		return "", fmt.Errorf("isolation level %s is not supported by %s", opts.Isolation, d.Name)
+	}
+}
+
+// sqlitePagination is sq.LimitOffset, but SQLite doesn't accept OFFSET without LIMIT
+type sqlitePagination struct{}
+
+func (sqlitePagination) WritePagination(sql *bytes.Buffer, limit, offset string) {
+	if len(limit) == 0 && len(offset) > 0 {
+		limit = "-1"
+	}
+	sq.LimitOffset.WritePagination(sql, limit, offset)
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialectBuilder(t *testing.T) {
+	cases := map[string]string{
+		"oracle":   "SELECT ID FROM CUSTOMER WHERE ID > :1 OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		"postgres": "SELECT ID FROM CUSTOMER WHERE ID > $1 LIMIT 10 OFFSET 20",
+		"sqlite":   "SELECT ID FROM CUSTOMER WHERE ID > ? LIMIT 10 OFFSET 20",
+	}
+
+	for name, expected := range cases {
+		d, err := DialectByName(name)
+		require.NoError(t, err)
+
+		sql, _, err := d.Builder().Select("ID").From("CUSTOMER").Where("ID > ?", 1).Limit(10).Offset(20).ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, expected, sql, name)
+	}
+
+	_, err := DialectByName("mssql")
+	assert.Error(t, err)
+}
+
+func TestSQLiteOffsetWithoutLimit(t *testing.T) {
+	sql, _, err := SQLite.Builder().Select("ID").From("CUSTOMER").Offset(5).ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT ID FROM CUSTOMER LIMIT -1 OFFSET 5", sql)
+}
+
+func TestDialectForUpdateSql(t *testing.T) {
+	assert.Equal(t, "FOR UPDATE", Oracle.ForUpdateSql())
+	assert.Equal(t, "FOR UPDATE", Postgres.ForUpdateSql())
+	assert.Empty(t, SQLite.ForUpdateSql())
+}
+
+func TestDialectUniqueViolation(t *testing.T) {
+	assert.NoError(t, Oracle.UniqueViolation(nil))
+	assert.ErrorIs(t, Oracle.UniqueViolation(errors.New("ORA-00001: unique constraint (IB.APPROVAL_PENDING_UK) violated")), ErrUniqueViolation)
+	assert.ErrorIs(t, Postgres.UniqueViolation(&pq.Error{Code: "23505"}), ErrUniqueViolation)
+
+	for _, err := range []error{errors.New("ORA-02291: integrity constraint violated"), &pq.Error{Code: "23503"}} {
+		assert.NotErrorIs(t, Oracle.UniqueViolation(err), ErrUniqueViolation)
+		assert.NotErrorIs(t, Postgres.UniqueViolation(err), ErrUniqueViolation)
+	}
+}
+
+func TestDialectSetTransactionSql(t *testing.T) {
+	for _, tc := range []struct {
+		opts     sql.TxOptions
+		expected string
+	}{
+		{sql.TxOptions{}, ""},
+		{sql.TxOptions{Isolation: sql.LevelReadCommitted}, "SET TRANSACTION ISOLATION LEVEL READ COMMITTED"},
+		{sql.TxOptions{Isolation: sql.LevelSerializable}, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE"},
+		{sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}, "SET TRANSACTION READ ONLY"},
+	} {
+		stmt, err := Oracle.SetTransactionSql(tc.opts)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, stmt)
+	}
+
+	for _, level := range []sql.IsolationLevel{sql.LevelRepeatableRead, sql.LevelReadUncommitted} {
+		_, err := Oracle.SetTransactionSql(sql.TxOptions{Isolation: level})
+		assert.Error(t, err, level.String())
+
+		stmt, err := Postgres.SetTransactionSql(sql.TxOptions{Isolation: level})
+		require.NoError(t, err, "lib/pq sets the level in BeginTx")
+		assert.Empty(t, stmt)
+	}
+}
@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// QueryHook is called before every statement the pool of a DB sends to the database, the function it returns
+// once the statement is done: when it fails or when the rows of the query are closed, so reading them counts.
+// The error is the one of the statement or of reading its rows. Commit and Rollback aren't hooked.
+type QueryHook func(ctx context.Context, query string) (done func(err error))
+
+// queryHooks is the chain of the hooks of a pool, swapped whole so the statements run meanwhile read
+// a consistent chain without a lock
+type queryHooks struct {
+	mu    sync.Mutex
+	chain atomic.Value // QueryHook
+}
+
+func noopDone(error) {}
+
+// AddQueryHook hooks every statement of the pool, the SAVEPOINTs of WithinTx and the DB.NextID queries
+// included. A hook added later runs around the ones added before it.
+func (db *DB) AddQueryHook(hook QueryHook) {
+	db.hooks.mu.Lock()
+	defer db.hooks.mu.Unlock()
+
+	inner, _ := db.hooks.chain.Load().(QueryHook)
+	if inner == nil {
+		db.hooks.chain.Store(hook)
+		return
+	}
+
+	db.hooks.chain.Store(QueryHook(func(ctx context.Context, query string) func(err error) {
+		outerDone := hook(ctx, query)
+		innerDone := inner(ctx, query)
+		return func(err error) {
+			innerDone(err)
+			outerDone(err)
+		}
+	}))
+}
+
+func (h *queryHooks) before(ctx context.Context, query string) func(err error) {
+	if hook, _ := h.chain.Load().(QueryHook); hook != nil {
+		return hook(ctx, query)
+	}
+	return noopDone
+}
+
+// hookedConnector opens the connections of the pool through the driver of the dialect
+type hookedConnector struct {
+	connector driver.Connector
+	hooks     *queryHooks
+}
+
+func (c *hookedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &hookedConn{Conn: conn, hooks: c.hooks}, nil
+}
+
+func (c *hookedConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// dsnConnector is the connector of a driver without driver.DriverContext
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// hookedConn runs the hooks around the statements of the connection. database/sql falls back to a
+// prepared statement when the driver skips ExecContext or QueryContext, the statement is hooked then.
+type hookedConn struct {
+	driver.Conn
+	hooks *queryHooks
+}
+
+func (c *hookedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	done := c.hooks.before(ctx, query)
+	result, err := execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		done(err)
+	}
+	return result, err
+}
+
+func (c *hookedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	done := c.hooks.before(ctx, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		if err != driver.ErrSkip {
+			done(err)
+		}
+		return nil, err
+	}
+	return &hookedRows{Rows: rows, done: done}, nil
+}
+
+func (c *hookedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hookedStmt{Stmt: stmt, query: query, hooks: c.hooks}, nil
+}
+
+func (c *hookedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // the drivers without ConnBeginTx
+}
+
+func (c *hookedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *hookedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (c *hookedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *hookedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// hookedStmt is a statement database/sql prepared for the connection
+type hookedStmt struct {
+	driver.Stmt
+	query string
+	hooks *queryHooks
+}
+
+func (s *hookedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	done := s.hooks.before(ctx, s.query)
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			result, err = s.Stmt.Exec(values) //nolint:staticcheck // the drivers without StmtExecContext
+		}
+	}
+	done(err)
+	return result, err
+}
+
+func (s *hookedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	done := s.hooks.before(ctx, s.query)
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = s.Stmt.Query(values) //nolint:staticcheck // the drivers without StmtQueryContext
+		}
+	}
+	if err != nil {
+		done(err)
+		return nil, err
+	}
+	return &hookedRows{Rows: rows, done: done}, nil
+}
+
+func (s *hookedStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("the driver doesn't support the named argument %q", arg.Name)
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// hookedRows ends the hook of the query when the rows are closed, the error of reading them is the error
+// of the query
+type hookedRows struct {
+	driver.Rows
+	done func(err error)
+	err  error
+	once sync.Once
+}
+
+func (r *hookedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return err
+}
+
+func (r *hookedRows) Close() error {
+	err := r.Rows.Close()
+	r.once.Do(func() {
+		if r.err == nil {
+			r.err = err
+		}
+		r.done(r.err)
+	})
+	return err
+}
@@ -0,0 +1,120 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/internet-banking-ul/modules/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookedQuery struct {
+	query string
+	done  bool
+	err   error
+}
+
+// queryRecorder records the statements of the pool
+type queryRecorder struct {
+	mu      sync.Mutex
+	queries []*hookedQuery
+}
+
+func (r *queryRecorder) hook(ctx context.Context, query string) func(err error) {
+	q := &hookedQuery{query: query}
+	r.mu.Lock()
+	r.queries = append(r.queries, q)
+	r.mu.Unlock()
+	return func(err error) {
+		q.done, q.err = true, err
+	}
+}
+
+func (r *queryRecorder) last() *hookedQuery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.queries[len(r.queries)-1]
+}
+
+func (r *queryRecorder) reset() {
+	r.mu.Lock()
+	r.queries = nil
+	r.mu.Unlock()
+}
+
+func TestDB_AddQueryHook(t *testing.T) {
+	db := newTxDB(t)
+	ctx := context.Background()
+	var r queryRecorder
+	db.AddQueryHook(r.hook)
+	registry := metrics.NewRegistry()
+	db.AddQueryHook(metrics.NewQueries(registry).Hook)
+
+	var n int
+	require.NoError(t, db.Builder().Select("COUNT(1)").From("TX_TEST").RunWith(db.Runner(ctx)).QueryRowContext(ctx).Scan(&n))
+	assert.Equal(t, &hookedQuery{query: "SELECT COUNT(1) FROM TX_TEST", done: true}, r.last())
+
+	var buf bytes.Buffer
+	require.NoError(t, metrics.Write(&buf, registry))
+	assert.Contains(t, buf.String(), `db_query_duration_seconds_count{method="TestDB_AddQueryHook"} 1`, "the caller of the builder")
+
+	err := db.Builder().Select("ID").From("WIDGET").RunWith(db.Runner(ctx)).QueryRowContext(ctx).Scan(&n)
+	require.Error(t, err)
+	assert.True(t, r.last().done)
+	assert.EqualError(t, r.last().err, err.Error(), "the error of QueryRow")
+
+	// the query is done once its rows are read
+	require.NoError(t, insert(ctx, db, 1))
+	rows, err := db.Builder().Select("ID").From("TX_TEST").RunWith(db.Runner(ctx)).QueryContext(ctx)
+	require.NoError(t, err)
+	assert.False(t, r.last().done)
+	for rows.Next() {
+	}
+	require.NoError(t, rows.Close())
+	assert.True(t, r.last().done)
+	assert.NoError(t, r.last().err)
+}
+
+func TestDB_AddQueryHook_Tx(t *testing.T) {
+	db := newTxDB(t)
+	ctx := context.Background()
+	var r queryRecorder
+	db.AddQueryHook(r.hook)
+
+	err := db.WithinTx(ctx, func(ctx context.Context) error {
+		return db.WithinTx(ctx, func(ctx context.Context) error {
+			id, err := db.NextID(ctx, "TX_TEST_SEQ", "TX_TEST")
+			if err != nil {
+				return err
+			}
+			return insert(ctx, db, id)
+		})
+	})
+	require.NoError(t, err)
+
+	var queries []string
+	for _, q := range r.queries {
+		assert.True(t, q.done, q.query)
+		queries = append(queries, q.query)
+	}
+	assert.Contains(t, queries, "SAVEPOINT SP_1")
+	assert.Contains(t, queries, db.Dialect.NextIDSql("TX_TEST_SEQ", "TX_TEST"))
+	assert.Contains(t, queries, "INSERT INTO TX_TEST (ID) VALUES (?)")
+
+	// a hook added later runs around the ones before it
+	r.reset()
+	var order []string
+	db.AddQueryHook(func(ctx context.Context, query string) func(err error) {
+		order = append(order, "outer")
+		assert.Empty(t, r.queries)
+		return func(err error) {
+			assert.True(t, r.last().done)
+			order = append(order, "outer done")
+		}
+	})
+	assert.Equal(t, 1, count(t, ctx, db))
+	assert.Equal(t, []string{"outer", "outer done"}, order)
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/internet-banking-ul/internal/config"
+	sq "github.com/internet-banking-ul/modules/squirrel"
+	"github.com/internet-banking-ul/tools"
+	"go.uber.org/zap"
+)
+
+// DB is the connection pool together with the dialect it speaks.
+// Repositories build their queries with Builder and run them with RunWith(repo.DB.Runner(ctx)),
+// so they take part in the transaction of WithinTx.
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+	// Isolation is the level of the transactions started by WithinTx
+	Isolation sql.IsolationLevel
+	hooks     *queryHooks
+}
+
+// OpenDSN opens the pool of dsn through the driver of dialect, the statements of the pool run the hooks
+// of AddQueryHook
+func OpenDSN(dialect Dialect, dsn string) (*DB, error) {
+	probe, err := sql.Open(dialect.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	_ = probe.Close()
+
+	var connector driver.Connector = dsnConnector{dsn: dsn, driver: drv}
+	if driverCtx, ok := drv.(driver.DriverContext); ok {
+		if connector, err = driverCtx.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+
+	hooks := &queryHooks{}
+	return &DB{
+		DB:      sql.OpenDB(&hookedConnector{connector: connector, hooks: hooks}),
+		Dialect: dialect,
+		hooks:   hooks,
+	}, nil
+}
+
+// Open opens the pool for the dialect from config and applies the pool settings.
+// The driver has to be compiled in, Oracle needs `-tags oracle` (see Makefile).
+func Open(cfg *config.Config) (*DB, error) {
+	dialect, err := DialectByName(cfg.DB.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	isolation, err := IsolationLevelByName(cfg.DB.IsolationLevel)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dialect.SetTransactionSql(sql.TxOptions{Isolation: isolation}); err != nil {
+		return nil, err
+	}
+
+	if !tools.StringInSlice(sql.Drivers(), dialect.DriverName) {
+		return nil, fmt.Errorf("sql driver %q for dialect %q is not compiled in", dialect.DriverName, dialect.Name)
+	}
+
+	db, err := OpenDSN(dialect, cfg.DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+	db.Isolation = isolation
+	return db, nil
+}
+
+// Builder returns the squirrel StatementBuilder for the dialect of db
+func (db *DB) Builder() sq.StatementBuilderType {
+	return db.Dialect.Builder()
+}
+
+// NextID returns the next primary key value for table (see Dialect.NextIDSql), in the transaction of ctx
+// like Runner
+func (db *DB) NextID(ctx context.Context, sequence, table string) (id int64, err error) {
+	err = db.rowQueryer(ctx).QueryRowContext(ctx, db.Dialect.NextIDSql(sequence, table)).Scan(&id)
+	return
+}
+
+// Execer is an insert/update/delete builder bound to a runner with RunWith
+type Execer interface {
+	sq.Sqlizer
+	ExecContext(ctx context.Context) (sql.Result, error)
+}
+
+// ExecAffected runs q and returns sql.ErrNoRows when no row was affected
+func ExecAffected(ctx context.Context, l *zap.Logger, q Execer) error {
+	query, args, err := q.ToSql()
+	if err != nil {
+		l.Error("ToSql", zap.Error(err))
+		return err
+	}
+
+	l.Debug("Info", zap.String("sql", query), zap.Any("args", args))
+
+	res, err := q.ExecContext(ctx)
+	if err != nil {
+		l.Error("ExecContext", zap.Error(err))
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		l.Error("RowsAffected", zap.Error(err))
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
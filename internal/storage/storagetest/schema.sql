@@ -0,0 +1,145 @@
+CREATE TABLE CUSTOMER (
+    ID                          INTEGER PRIMARY KEY,
+    IS_DELETED                  INTEGER      NOT NULL DEFAULT 0,
+    PERSON_TYPE                 VARCHAR(32)  NOT NULL,
+    EXTERNAL_ID                 VARCHAR(64)  NOT NULL,
+    NAME                        VARCHAR(255) NOT NULL,
+    FULL_NAME                   VARCHAR(512) NOT NULL,
+    INTL_NAME                   VARCHAR(255),
+    OWNERSHIP                   VARCHAR(64)  NOT NULL,
+    RESIDENCY_AND_ECONOMIC_CODE VARCHAR(2)   NOT NULL,
+    TAX_CODE                    VARCHAR(12)  NOT NULL
+);
+
+-- one live customer per external id, on Oracle a function-based index on
+-- CASE WHEN IS_DELETED = 0 THEN EXTERNAL_ID END
+CREATE UNIQUE INDEX CUSTOMER_EXTERNAL_ID_UK ON CUSTOMER (EXTERNAL_ID) WHERE IS_DELETED = 0;
+
+CREATE TABLE COMPANY_PERSON (
+    ID                INTEGER PRIMARY KEY,
+    IS_DELETED        INTEGER     NOT NULL DEFAULT 0,
+    EXTERNAL_ID       VARCHAR(64) NOT NULL,
+    COMPANY_ID        INTEGER     NOT NULL REFERENCES CUSTOMER (ID),
+    USER_ACCOUNT_ID   INTEGER     NOT NULL,
+    MANAGER_ID        INTEGER,
+    VALID_FROM        TIMESTAMP,
+    VALID_TO          TIMESTAMP,
+    SIGN_LEVEL        VARCHAR(16) NOT NULL,
+    ORGANIZATION_ROLE VARCHAR(64) NOT NULL
+);
+
+CREATE TABLE APPROVAL (
+    ID             INTEGER PRIMARY KEY,
+    COMPANY_ID     INTEGER     NOT NULL REFERENCES CUSTOMER (ID),
+    OPERATION_TYPE VARCHAR(64) NOT NULL,
+    OPERATION_ID   VARCHAR(64) NOT NULL,
+    RULE           VARCHAR(64) NOT NULL,
+    STATUS         VARCHAR(16) NOT NULL,
+    VERSION        INTEGER     NOT NULL DEFAULT 0,
+    CREATED_BY     INTEGER     NOT NULL,
+    CREATED_AT     TIMESTAMP   NOT NULL,
+    EXPIRES_AT     TIMESTAMP   NOT NULL,
+    RESOLVED_AT    TIMESTAMP
+);
+
+-- one pending approval per operation, on Oracle a function-based index on
+-- CASE WHEN STATUS = 'PENDING' THEN COMPANY_ID END (and OPERATION_TYPE, OPERATION_ID)
+CREATE UNIQUE INDEX APPROVAL_PENDING_UK ON APPROVAL (COMPANY_ID, OPERATION_TYPE, OPERATION_ID) WHERE STATUS = 'PENDING';
+
+CREATE TABLE APPROVAL_SIGNATURE (
+    ID                INTEGER PRIMARY KEY,
+    APPROVAL_ID       INTEGER     NOT NULL REFERENCES APPROVAL (ID),
+    COMPANY_PERSON_ID INTEGER     NOT NULL REFERENCES COMPANY_PERSON (ID),
+    USER_ACCOUNT_ID   INTEGER     NOT NULL,
+    SIGN_LEVEL        VARCHAR(16) NOT NULL,
+    DECISION          VARCHAR(16) NOT NULL,
+    CREATED_AT        TIMESTAMP   NOT NULL,
+    UNIQUE (APPROVAL_ID, USER_ACCOUNT_ID)
+);
+
+CREATE TABLE ACCOUNT (
+    ID                INTEGER PRIMARY KEY,
+    CUSTOMER_ID       INTEGER      NOT NULL REFERENCES CUSTOMER (ID),
+    IBAN              VARCHAR(34)  NOT NULL UNIQUE,
+    CURRENCY          VARCHAR(3)   NOT NULL,
+    STATUS            VARCHAR(16)  NOT NULL,
+    NAME              VARCHAR(255) NOT NULL,
+    AVAILABLE_BALANCE BIGINT       NOT NULL DEFAULT 0,
+    LEDGER_BALANCE    BIGINT       NOT NULL DEFAULT 0,
+    OPENED_AT         TIMESTAMP    NOT NULL,
+    CLOSED_AT         TIMESTAMP
+);
+
+CREATE TABLE ACCOUNT_TRANSACTION (
+    ID                INTEGER PRIMARY KEY,
+    ACCOUNT_ID        INTEGER      NOT NULL REFERENCES ACCOUNT (ID),
+    BOOKED_AT         TIMESTAMP    NOT NULL,
+    DOCUMENT_NUMBER   VARCHAR(64)  NOT NULL,
+    AMOUNT            BIGINT       NOT NULL,
+    BALANCE_AFTER     BIGINT       NOT NULL,
+    COUNTERPARTY_NAME VARCHAR(255) NOT NULL,
+    COUNTERPARTY_IBAN VARCHAR(34)  NOT NULL,
+    DESCRIPTION       VARCHAR(512) NOT NULL
+);
+
+CREATE TABLE PAYMENT_ORDER (
+    ID                   INTEGER PRIMARY KEY,
+    CUSTOMER_ID          INTEGER      NOT NULL REFERENCES CUSTOMER (ID),
+    ACCOUNT_ID           INTEGER      NOT NULL REFERENCES ACCOUNT (ID),
+    NUMBER               VARCHAR(64)  NOT NULL,
+    AMOUNT               BIGINT       NOT NULL,
+    CURRENCY             VARCHAR(3)   NOT NULL,
+    BENEFICIARY_NAME     VARCHAR(255) NOT NULL,
+    BENEFICIARY_TAX_CODE VARCHAR(12)  NOT NULL,
+    BENEFICIARY_IBAN     VARCHAR(34)  NOT NULL,
+    BENEFICIARY_CODE     VARCHAR(2)   NOT NULL,
+    SENDER_CODE          VARCHAR(2)   NOT NULL,
+    PURPOSE_CODE         VARCHAR(3)   NOT NULL,
+    PURPOSE              VARCHAR(420) NOT NULL,
+    VALUE_DATE           TIMESTAMP    NOT NULL,
+    STATUS               VARCHAR(16)  NOT NULL,
+    REJECT_REASON        VARCHAR(512),
+    VERSION              INTEGER      NOT NULL DEFAULT 0,
+    CREATED_BY           INTEGER      NOT NULL,
+    CREATED_AT           TIMESTAMP    NOT NULL,
+    SIGNED_BY            INTEGER,
+    SIGNED_AT            TIMESTAMP,
+    SENT_AT              TIMESTAMP,
+    RESOLVED_AT          TIMESTAMP
+);
+
+CREATE TABLE IDEMPOTENCY_KEY (
+    CALLER_ID       INTEGER      NOT NULL,
+    IDEMPOTENCY_KEY VARCHAR(255) NOT NULL,
+    REQUEST_HASH    VARCHAR(64)  NOT NULL,
+    RESPONSE_STATUS INTEGER,
+    CONTENT_TYPE    VARCHAR(255),
+    RESPONSE_BODY   BLOB,
+    CREATED_AT      TIMESTAMP    NOT NULL,
+    LOCKED_UNTIL    TIMESTAMP,
+    PRIMARY KEY (CALLER_ID, IDEMPOTENCY_KEY)
+);
+
+CREATE TABLE AUDIT_LOG (
+    ID          INTEGER PRIMARY KEY,
+    CUSTOMER_ID INTEGER,
+    ACTOR_ID    INTEGER,
+    IP          VARCHAR(64)  NOT NULL,
+    DEVICE_ID   VARCHAR(255) NOT NULL,
+    ENTITY      VARCHAR(64)  NOT NULL,
+    ENTITY_ID   VARCHAR(64)  NOT NULL,
+    ACTION      VARCHAR(16)  NOT NULL,
+    CHANGES     TEXT         NOT NULL,
+    CREATED_AT  TIMESTAMP    NOT NULL,
+    PREV_HASH   VARCHAR(64)  NOT NULL,
+    HASH        VARCHAR(64)  NOT NULL
+);
+
+CREATE INDEX AUDIT_LOG_CUSTOMER_IX ON AUDIT_LOG (CUSTOMER_ID, ID);
+
+-- the last record of the chain of every customer, 0 for the records without one
+CREATE TABLE AUDIT_LOG_HEAD (
+    CUSTOMER_ID INTEGER PRIMARY KEY,
+    LAST_ID     INTEGER     NOT NULL,
+    HASH        VARCHAR(64) NOT NULL
+);
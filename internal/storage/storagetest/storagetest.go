@@ -0,0 +1,55 @@
+// Package storagetest opens throwaway SQLite databases with the service schema,
+// so repositories can be tested without an Oracle client installed.
+package storagetest
+
+import (
+	_ "embed"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/modules/logger"
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+)
+
+//go:embed schema.sql
+var schema string
+
+// NewSQLite creates a SQLite file in t.TempDir() with the schema applied.
+// The pool is closed by t.Cleanup.
+func NewSQLite(t testing.TB) *storage.DB {
+	t.Helper()
+
+	// repositories log through the global loggers, daylight initializes them in the service
+	if logger.WorkLogger == nil {
+		logger.WorkLogger, logger.SqlLogger = zap.NewNop(), zap.NewNop()
+	}
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "al_hilal_core.db") + "?_foreign_keys=on"
+	db, err := storage.OpenDSN(storage.SQLite, dsn)
+	if err != nil {
+		t.Fatalf("open sqlite: %s", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	for _, stmt := range strings.Split(schema, ";") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("apply schema: %s\n%s", err, stmt)
+		}
+	}
+
+	return db
+}
+
+// Exec runs a fixture statement with ? placeholders and fails the test on error
+func Exec(t testing.TB, db *storage.DB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("exec %q: %s", query, err)
+	}
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/internet-banking-ul/modules/squirrel"
+)
+
+// TxManager runs a unit of work in one transaction. The transaction travels in the context passed to fn,
+// the repositories pick it up with DB.Runner, so the services don't thread *sql.Tx through their calls.
+type TxManager interface {
+	// WithinTx runs fn in a transaction, committed when fn returns nil and rolled back when it returns
+	// an error or panics. Called inside another WithinTx it runs in a savepoint of the outer transaction:
+	// an error of fn rolls back to the savepoint only, opts are ignored then.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
+}
+
+// TxOption changes the options of the transaction started by WithinTx
+type TxOption func(opts *sql.TxOptions)
+
+// WithIsolation sets the isolation level instead of db.isolation_level of config
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(opts *sql.TxOptions) {
+		opts.Isolation = level
+	}
+}
+
+// ReadOnly starts a read only transaction
+func ReadOnly() TxOption {
+	return func(opts *sql.TxOptions) {
+		opts.ReadOnly = true
+	}
+}
+
+// isolationLevels - the values of db.isolation_level, "" is the default level of the database
+var isolationLevels = map[string]sql.IsolationLevel{
+	"":                 sql.LevelDefault,
+	"read_committed":   sql.LevelReadCommitted,
+	"repeatable_read":  sql.LevelRepeatableRead,
+	"serializable":     sql.LevelSerializable,
+	"read_uncommitted": sql.LevelReadUncommitted,
+}
+
+// IsolationLevelByName returns the isolation level for the value of db.isolation_level
+func IsolationLevelByName(name string) (sql.IsolationLevel, error) {
+	if level, ok := isolationLevels[name]; ok {
+		return level, nil
+	}
+	return sql.LevelDefault, fmt.Errorf("unknown isolation level %q", name)
+}
+
+type txKey struct{}
+
+// txState is the transaction of the context and the depth of the savepoints opened in it
+type txState struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// TxFromContext returns the transaction started by WithinTx for ctx
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return state.tx, true
+}
+
+// Runner returns the transaction of ctx or the pool when there is none, repositories run
+// their queries with RunWith(repo.DB.Runner(ctx)). A transaction is bound to one connection,
+// so the context carrying it must not be shared by concurrent goroutines.
+func (db *DB) Runner(ctx context.Context) sq.BaseRunner {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db.DB
+}
+
+// rowQueryer is the transaction of ctx or the pool, for the raw statements Runner can't run
+func (db *DB) rowQueryer(ctx context.Context) interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+} {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db.DB
+}
+
+// WithinTx implements TxManager, the isolation level is db.isolation_level of config unless
+// WithIsolation is passed. The options the driver ignores are set by Dialect.SetTransactionSql.
+func (db *DB) WithinTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) (err error) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return db.withinSavepoint(ctx, state, fn)
+	}
+
+	txOptions := sql.TxOptions{Isolation: db.Isolation}
+	for _, opt := range opts {
+		opt(&txOptions)
+	}
+
+	setTransaction, err := db.Dialect.SetTransactionSql(txOptions)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &txOptions)
+	if err != nil {
+		return err
+	}
+
+	if setTransaction != "" {
+		if _, err = tx.ExecContext(ctx, setTransaction); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, &txState{tx: tx})); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback: %s)", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withinSavepoint runs fn in a savepoint of the transaction of parent
+func (db *DB) withinSavepoint(ctx context.Context, parent *txState, fn func(ctx context.Context) error) (err error) {
+	state := &txState{tx: parent.tx, depth: parent.depth + 1}
+	name := fmt.Sprintf("SP_%d", state.depth)
+
+	if _, err = state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(r)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint: %s)", err, rbErr)
+		}
+		return err
+	}
+
+	if release := db.Dialect.ReleaseSavepointSql(name); release != "" {
+		_, err = state.tx.ExecContext(ctx, release)
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package storage_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/internet-banking-ul/internal/storage"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTxDB(t *testing.T) *storage.DB {
+	db := storagetest.NewSQLite(t)
+	storagetest.Exec(t, db, "CREATE TABLE TX_TEST (ID INTEGER PRIMARY KEY)")
+	return db
+}
+
+func insert(ctx context.Context, db *storage.DB, id int64) error {
+	_, err := db.Builder().Insert("TX_TEST").Columns("ID").Values(id).RunWith(db.Runner(ctx)).ExecContext(ctx)
+	return err
+}
+
+func count(t *testing.T, ctx context.Context, db *storage.DB) (n int) {
+	require.NoError(t, db.Builder().Select("COUNT(1)").From("TX_TEST").RunWith(db.Runner(ctx)).QueryRowContext(ctx).Scan(&n))
+	return n
+}
+
+func TestWithinTx(t *testing.T) {
+	db := newTxDB(t)
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+
+	_, ok := storage.TxFromContext(ctx)
+	assert.False(t, ok)
+
+	err := db.WithinTx(ctx, func(ctx context.Context) error {
+		_, ok := storage.TxFromContext(ctx)
+		assert.True(t, ok)
+		require.NoError(t, insert(ctx, db, 1))
+		// the uncommitted row is seen through the runner of the transaction
+		assert.Equal(t, 1, count(t, ctx, db))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count(t, ctx, db))
+
+	err = db.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, insert(ctx, db, 2))
+		return errFailed
+	})
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, 1, count(t, ctx, db), "rolled back")
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = db.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, insert(ctx, db, 3))
+			panic("boom")
+		})
+	})
+	assert.Equal(t, 1, count(t, ctx, db), "rolled back on panic")
+
+	err = db.WithinTx(ctx, func(ctx context.Context) error {
+		return insert(ctx, db, 4)
+	}, storage.WithIsolation(sql.LevelSerializable))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count(t, ctx, db))
+}
+
+func TestWithinTx_Savepoint(t *testing.T) {
+	db := newTxDB(t)
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+
+	err := db.WithinTx(ctx, func(ctx context.Context) error {
+		outer, _ := storage.TxFromContext(ctx)
+		require.NoError(t, insert(ctx, db, 1))
+
+		err := db.WithinTx(ctx, func(ctx context.Context) error {
+			inner, _ := storage.TxFromContext(ctx)
+			assert.Same(t, outer, inner)
+			require.NoError(t, insert(ctx, db, 2))
+			return errFailed
+		})
+		assert.ErrorIs(t, err, errFailed)
+		assert.Equal(t, 1, count(t, ctx, db), "the savepoint is rolled back only")
+
+		assert.Panics(t, func() {
+			_ = db.WithinTx(ctx, func(ctx context.Context) error {
+				require.NoError(t, insert(ctx, db, 3))
+				panic("boom")
+			})
+		})
+		assert.Equal(t, 1, count(t, ctx, db))
+
+		// NextID runs in the transaction too, SQLite sees the uncommitted row
+		id, err := db.NextID(ctx, "TX_TEST_SEQ", "TX_TEST")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), id)
+		return db.WithinTx(ctx, func(ctx context.Context) error {
+			return insert(ctx, db, 4)
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count(t, ctx, db))
+
+	// the outer rollback discards the released savepoints
+	err = db.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, db.WithinTx(ctx, func(ctx context.Context) error {
+			return insert(ctx, db, 5)
+		}))
+		return errFailed
+	})
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, 2, count(t, ctx, db))
+}
+
+func TestIsolationLevelByName(t *testing.T) {
+	level, err := storage.IsolationLevelByName("")
+	require.NoError(t, err)
+	assert.Equal(t, sql.LevelDefault, level)
+
+	level, err = storage.IsolationLevelByName("serializable")
+	require.NoError(t, err)
+	assert.Equal(t, sql.LevelSerializable, level)
+
+	_, err = storage.IsolationLevelByName("snapshot")
+	assert.Error(t, err)
+
+	assert.Empty(t, storage.Oracle.ReleaseSavepointSql("SP_1"))
+	assert.Equal(t, "RELEASE SAVEPOINT SP_1", storage.Postgres.ReleaseSavepointSql("SP_1"))
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
 )
 
 // ContextHolderKey key for map in context
@@ -21,6 +22,11 @@ func contextGetAttribute(ctx context.Context, attribute string) interface{} {
 	return nil
 }
 
+// GetAttribute - get value from map stored in context, nil when not set
+func GetAttribute(ctx context.Context, attribute string) interface{} {
+	return contextGetAttribute(ctx, attribute)
+}
+
 // SetAttribute - set value to map stored in context
 func SetAttribute(ctx context.Context, attribute string, value interface{}) {
 	if contextHolder, ok := ctx.Value(ContextHolderKey).(*sync.Map); ok {
@@ -30,6 +36,16 @@ func SetAttribute(ctx context.Context, attribute string, value interface{}) {
 	}
 }
 
+// DetachContext returns the context without the deadline and cancellation of ctx sharing its context holder
+// and its span, for the work going on after the handler returns (a streamed response)
+func DetachContext(ctx context.Context) context.Context {
+	detached := context.WithValue(context.Background(), ContextHolderKey, ctx.Value(ContextHolderKey))
+	if span := tracing.SpanFromContext(ctx); span != nil {
+		detached = tracing.ContextWithSpan(detached, span)
+	}
+	return detached
+}
+
 // contextGetStringAttribute -
 func contextGetStringAttribute(ctx context.Context, attribute string) (string, bool) {
 	value := contextGetAttribute(ctx, attribute)
@@ -53,6 +69,14 @@ func contextGetInt32Attribute(ctx context.Context, attribute string) (int32, boo
 	return 0, false
 }
 
+// contextGetInt64Attribute -
+func contextGetInt64Attribute(ctx context.Context, attribute string) (int64, bool) {
+	if result, ok := contextGetAttribute(ctx, attribute).(int64); ok {
+		return result, true
+	}
+	return 0, false
+}
+
 // contextGetBoolAttribute -
 func contextGetBoolAttribute(ctx context.Context, attribute string) (bool, bool) {
 	value := contextGetAttribute(ctx, attribute)
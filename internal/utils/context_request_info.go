@@ -23,6 +23,11 @@ const (
 	AttributeCurrentUsername       = "current_username"
 	AttributeCurrentUserID         = "current_user_id"
 	AttributeCurrentUser           = "current_user"
+	AttributeCurrentCompanyID      = "current_company_id"
+	// AttributeCurrentCompanyPersons - see company_person/entities.ContextGetCurrentCompanyPersons
+	AttributeCurrentCompanyPersons = "current_company_persons"
+	// AttributeTracingMetadata is the logger.Metadata of the request, stored by middles.Tracing
+	AttributeTracingMetadata = "tracing_metadata"
 )
 
 func ContextGetLocale(ctx context.Context) (string, bool) {
@@ -45,6 +50,11 @@ func ContextGetCurrentUserID(ctx context.Context) (int32, bool) {
 	return contextGetInt32Attribute(ctx, AttributeCurrentUserID)
 }
 
+// ContextGetCurrentCompanyID returns the company the caller acts for, set by the authorization middlewares
+func ContextGetCurrentCompanyID(ctx context.Context) (int64, bool) {
+	return contextGetInt64Attribute(ctx, AttributeCurrentCompanyID)
+}
+
 //func ContextGetCurrentUser(ctx context.Context) *models.Customers {
 //	result := contextGetAttribute(ctx, AttributeCurrentUser)
 //	if result != nil {
@@ -5,7 +5,8 @@ import (
 	"runtime"
 
 	"github.com/internet-banking-ul/modules/daylight"
-	_ "github.com/mattn/go-oci8"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 func main() {
@@ -0,0 +1,132 @@
+package daylight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/modules/health"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/tools"
+	"github.com/theckman/go-flock"
+	"go.uber.org/zap"
+)
+
+// readyPollInterval - how often takeOver checks the readiness and the lock
+const readyPollInterval = 100 * time.Millisecond
+
+// takeOver replaces the running instance once this one, serving on ln, is ready: the readiness is checked
+// in-process, since a probe of the shared address may be answered by the old instance. The new connections
+// are then steered to ln and the old instance (the pid file) is signalled to drain, the lock and the pid files
+// are taken as soon as it releases the lock. The old instance keeps serving when this one isn't ready.
+func takeOver(ctx context.Context, cfg *config.Config, ln net.Listener, registry *health.Registry, lock *flock.Flock) error {
+	l := logger.WorkLoggerWithContext(ctx).Named("Handoff")
+
+	readyCtx, cancel := context.WithTimeout(ctx, cfg.Handoff.ReadyTimeout)
+	defer cancel()
+	if err := waitReady(readyCtx, registry); err != nil {
+		return fmt.Errorf("not ready in %s: %w", cfg.Handoff.ReadyTimeout, err)
+	}
+
+	unsteer, err := steerConnections(ln)
+	if err != nil {
+		// the connections queued on the old socket when it's closed are reset
+		l.Warn("Failed steer connections, the old instance drains its queue without it", zap.Error(err))
+	}
+
+	if pid, ok := readPid(cfg.GetPidPath()); ok && pid != os.Getpid() {
+		l.Info("Draining the old instance", zap.Int("pid", pid))
+		// ESRCH when it's gone already, the lock tells the rest
+		_ = KillPid(ctx, tools.ToStr(pid))
+	}
+
+	lockCtx, cancelLock := context.WithTimeout(ctx, cfg.Handoff.LockTimeout)
+	defer cancelLock()
+	locked, err := lock.TryLockContext(lockCtx, readyPollInterval)
+	if err != nil || !locked {
+		return fmt.Errorf("lock %s is not released in %s: %v", lock.Path(), cfg.Handoff.LockTimeout, err)
+	}
+
+	// the old socket is closed before the lock is released
+	if unsteer != nil {
+		if err := unsteer(); err != nil {
+			l.Warn("Failed detach the steering program, the next handoff can't steer", zap.Error(err))
+		}
+	}
+
+	if err := savePid(ctx, cfg); err != nil {
+		return fmt.Errorf("can't create pid: %w", err)
+	}
+
+	l.Info("Took over", zap.String("addr", ln.Addr().String()))
+	return nil
+}
+
+// waitReady checks the readiness of registry until it passes
+func waitReady(ctx context.Context, registry *health.Registry) error {
+	var last health.Report
+	for {
+		report := registry.Ready(ctx)
+		if report.OK() {
+			return nil
+		}
+		// the checks cut by the deadline tell less than the previous run
+		if ctx.Err() == nil || last.Status == "" {
+			last = report
+		}
+
+		select {
+		case <-ctx.Done():
+			var failed []string
+			for _, check := range last.Checks {
+				if check.Status != health.StatusOK {
+					failed = append(failed, check.Name+": "+check.Error)
+				}
+			}
+			return fmt.Errorf("failed checks %s", strings.Join(failed, ", "))
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+// drainBacklog waits up to timeout for the server to accept the connections queued on ln before it's closed,
+// the kernel resets the ones left in the queue of a closed SO_REUSEPORT socket
+func drainBacklog(ctx context.Context, ln net.Listener, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		n, err := acceptQueueLen(ln)
+		if err != nil || n == 0 {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d connections still queued after %s", n, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// readPid returns the pid saved by savePid
+func readPid(path string) (int, bool) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	var pidMap map[string]string
+	if err := json.Unmarshal(dat, &pidMap); err != nil || pidMap["pid"] == "" {
+		return 0, false
+	}
+
+	return tools.ToInt(pidMap["pid"]), true
+}
@@ -0,0 +1,107 @@
+//go:build linux
+// +build linux
+
+package daylight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// ListenFDEnv is the environment variable with the descriptor of the listener inherited from the parent
+// process, e.g. 3 for the first of exec.Cmd.ExtraFiles
+const ListenFDEnv = "AL_HILAL_CORE_LISTEN_FD"
+
+// handoffListener returns the inherited listener (see ListenFDEnv) or binds addr with SO_REUSEPORT,
+// so the next instance can bind it while this one serves
+func handoffListener(ctx context.Context, network, addr string) (net.Listener, error) {
+	if fd := os.Getenv(ListenFDEnv); fd != "" {
+		n, err := strconv.Atoi(fd)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ListenFDEnv, err)
+		}
+		f := os.NewFile(uintptr(n), "listener")
+		// FileListener works on a duplicate of the descriptor
+		defer f.Close()
+		return net.FileListener(f)
+	}
+
+	lc := net.ListenConfig{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+			err := c.Control(func(fd uintptr) {
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+			})
+			if err != nil {
+				return err
+			}
+			return sockErr
+		},
+	}
+	return lc.Listen(ctx, network, addr)
+}
+
+// steerConnections makes the kernel hand every new connection of the SO_REUSEPORT group to ln, so the old
+// instance only has to drain its accept queue; unsteer restores the hashing between the sockets.
+// The group is the old instance (index 0) and this one (index 1): the program returns 1, which falls back
+// to the hashing once the old socket is closed and this one moves to index 0. An inherited listener is
+// the socket of the old instance itself, there's nothing to steer.
+func steerConnections(ln net.Listener) (unsteer func() error, err error) {
+	if os.Getenv(ListenFDEnv) != "" {
+		return func() error { return nil }, nil
+	}
+
+	program := []unix.SockFilter{{Code: unix.BPF_RET | unix.BPF_K, K: 1}}
+	err = controlListener(ln, func(fd int) error {
+		return unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_REUSEPORT_CBPF,
+			&unix.SockFprog{Len: uint16(len(program)), Filter: &program[0]})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("attach reuseport program: %w", err)
+	}
+
+	return func() error {
+		// the next handoff steers by the index of its own socket
+		return controlListener(ln, func(fd int) error {
+			return unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_DETACH_REUSEPORT_BPF, 0)
+		})
+	}, nil
+}
+
+// acceptQueueLen is the number of the connections waiting in the accept queue of ln,
+// the tcpi_unacked of a listening socket
+func acceptQueueLen(ln net.Listener) (int, error) {
+	var n int
+	err := controlListener(ln, func(fd int) error {
+		info, err := unix.GetsockoptTCPInfo(fd, unix.IPPROTO_TCP, unix.TCP_INFO)
+		if err != nil {
+			return err
+		}
+		n = int(info.Unacked)
+		return nil
+	})
+	return n, err
+}
+
+func controlListener(ln net.Listener, fn func(fd int) error) error {
+	sc, ok := ln.(syscall.Conn)
+	if !ok {
+		return fmt.Errorf("%T has no descriptor", ln)
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var fnErr error
+	if err := raw.Control(func(fd uintptr) { fnErr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return fnErr
+}
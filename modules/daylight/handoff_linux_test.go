@@ -0,0 +1,185 @@
+//go:build linux
+// +build linux
+
+package daylight
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/internal/config"
+	healthHandlers "github.com/internet-banking-ul/internal/handlers/health"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/internet-banking-ul/modules/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theckman/go-flock"
+)
+
+func TestHandoffListener_ReusePort(t *testing.T) {
+	ctx := context.Background()
+
+	old, err := handoffListener(ctx, "tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer old.Close()
+
+	next, err := handoffListener(ctx, "tcp4", old.Addr().String())
+	require.NoError(t, err, "the next instance binds the port in use")
+	defer next.Close()
+	assert.Equal(t, old.Addr().String(), next.Addr().String())
+}
+
+func TestHandoffListener_Inherited(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	// File is a duplicate the inherited listener takes the ownership of
+	f, err := ln.(*net.TCPListener).File()
+	require.NoError(t, err)
+
+	t.Setenv(ListenFDEnv, strconv.Itoa(int(f.Fd())))
+	inherited, err := handoffListener(context.Background(), "tcp4", "127.0.0.1:1")
+	require.NoError(t, err)
+	defer inherited.Close()
+	assert.Equal(t, ln.Addr().String(), inherited.Addr().String())
+
+	t.Setenv(ListenFDEnv, "stdin")
+	_, err = handoffListener(context.Background(), "tcp4", "127.0.0.1:0")
+	assert.Error(t, err)
+}
+
+func TestTakeOver(t *testing.T) {
+	ctx := context.Background()
+	db := storagetest.NewSQLite(t)
+
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.DataDir = dir
+	cfg.PidFilePath = filepath.Join(dir, "al_hilal_core.pid")
+	cfg.LockFilePath = filepath.Join(dir, "al_hilal_core.lock")
+	cfg.Handoff = config.Handoff{Enabled: true, ReadyTimeout: 2 * time.Second, LockTimeout: 2 * time.Second}
+
+	// the running instance: a pid that doesn't exist and the lock held until it's drained
+	require.NoError(t, ioutil.WriteFile(cfg.GetPidPath(), []byte(`{"pid":"999999999"}`), 0644))
+	old := flock.New(cfg.LockFilePath)
+	locked, err := old.TryLock()
+	require.NoError(t, err)
+	require.True(t, locked)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_ = old.Unlock()
+	}()
+
+	registry := health.NewRegistry(time.Second)
+	registry.Register(health.DBPing("db", db.DB))
+	ln := listen(t, "127.0.0.1:0")
+
+	lock := flock.New(cfg.LockFilePath)
+	require.NoError(t, takeOver(ctx, cfg, ln, registry, lock))
+	assert.True(t, lock.Locked())
+	defer lock.Unlock()
+
+	pid, ok := readPid(cfg.GetPidPath())
+	require.True(t, ok)
+	assert.Equal(t, os.Getpid(), pid)
+}
+
+func TestTakeOver_NotReady(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.PidFilePath = filepath.Join(dir, "al_hilal_core.pid")
+	cfg.LockFilePath = filepath.Join(dir, "al_hilal_core.lock")
+	cfg.Handoff = config.Handoff{Enabled: true, ReadyTimeout: 300 * time.Millisecond, LockTimeout: time.Second}
+	require.NoError(t, ioutil.WriteFile(cfg.GetPidPath(), []byte(`{"pid":"999999999"}`), 0644))
+
+	registry := health.NewRegistry(time.Second)
+	registry.Register(health.NewChecker("db", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}))
+	// the old instance answers the probes of the shared address, it mustn't be taken for this one
+	old := listen(t, "127.0.0.1:0")
+	serveHealth(t, old, health.NewRegistry(time.Second))
+	ln := listen(t, old.Addr().String())
+
+	lock := flock.New(cfg.LockFilePath)
+	err := takeOver(ctx, cfg, ln, registry, lock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "db: connection refused")
+	assert.False(t, lock.Locked())
+
+	pid, ok := readPid(cfg.GetPidPath())
+	require.True(t, ok)
+	assert.Equal(t, 999999999, pid, "the pid file of the running instance is kept")
+}
+
+func TestSteerConnections(t *testing.T) {
+	old := listen(t, "127.0.0.1:0")
+	next := listen(t, old.Addr().String())
+
+	unsteer, err := steerConnections(next)
+	require.NoError(t, err)
+	for i := 0; i < 8; i++ {
+		conn, err := net.Dial("tcp4", old.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+	}
+
+	assert.Eventually(t, func() bool { return queued(t, next) == 8 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, queued(t, old), "nothing more for the old instance to drain")
+	assert.NoError(t, unsteer())
+}
+
+func TestDrainBacklog(t *testing.T) {
+	ln := listen(t, "127.0.0.1:0")
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp4", ln.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+	}
+	require.Eventually(t, func() bool { return queued(t, ln) == 3 }, time.Second, 10*time.Millisecond)
+
+	assert.Error(t, drainBacklog(context.Background(), ln, 50*time.Millisecond), "nobody accepts")
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	assert.NoError(t, drainBacklog(context.Background(), ln, time.Second))
+}
+
+func listen(t *testing.T, addr string) net.Listener {
+	ln, err := handoffListener(context.Background(), "tcp4", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	return ln
+}
+
+func queued(t *testing.T, ln net.Listener) int {
+	n, err := acceptQueueLen(ln)
+	require.NoError(t, err)
+	return n
+}
+
+// serveHealth serves the probes of registry on ln the way the server does
+func serveHealth(t *testing.T, ln net.Listener, registry *health.Registry) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	healthHandlers.NewHealthHandler(registry).RegisterHealth(app)
+
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+// +build !linux
+
+package daylight
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+func handoffListener(_ context.Context, _, _ string) (net.Listener, error) {
+	return nil, errors.New("handoff is supported on linux only")
+}
+
+func steerConnections(_ net.Listener) (func() error, error) {
+	return nil, errors.New("handoff is supported on linux only")
+}
+
+func acceptQueueLen(_ net.Listener) (int, error) {
+	return 0, errors.New("handoff is supported on linux only")
+}
@@ -0,0 +1,181 @@
+package daylight
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/modules/health"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+	"github.com/theckman/go-flock"
+	"go.uber.org/zap"
+)
+
+// backlogDrainTimeout bounds the wait for the accept queue of the listener, which a load not steered away
+// keeps filling
+const backlogDrainTimeout = 5 * time.Second
+
+// Shutdown stops the service in the phases of config.Shutdown on the first SIGINT/SIGTERM,
+// a second signal exits at once
+type Shutdown struct {
+	Config config.Shutdown
+	// Health fails the readiness as soon as the shutdown starts, so the load balancer stops routing here
+	// during Config.Delay
+	Health *health.Registry
+	App    *fiber.App
+	// Listener is the SO_REUSEPORT listener of the handoff, its accept queue is drained before it's closed
+	Listener net.Listener
+	// Stop cancels the context of the background jobs of the modules
+	Stop context.CancelFunc
+	DB   io.Closer
+	Lock *flock.Flock
+	// PidPath is the pid file removed at the end, unless another instance has taken it over
+	PidPath string
+	// Spans is the file of the span exporter, closed once the jobs are stopped
+	Spans io.Closer
+	// Loggers are flushed at the end
+	Loggers []*zap.Logger
+}
+
+// Notify starts waiting for the signals, it has to be called before the server listens.
+// The returned channel is closed when the shutdown is done.
+func (s *Shutdown) Notify(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+
+	signals := make(chan os.Signal, 2)
+	//register for interupt (Ctrl+C) and SIGTERM (docker)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		defer close(done)
+		defer signal.Stop(signals)
+
+		sig := <-signals
+		logger.WorkLoggerWithContext(ctx).Info("Shutting down", zap.Stringer("signal", sig))
+
+		go func() {
+			select {
+			case sig := <-signals:
+				logger.WorkLoggerWithContext(ctx).Warn("Shutdown interrupted", zap.Stringer("signal", sig))
+				os.Exit(2)
+			case <-done:
+			}
+		}()
+
+		s.Run(ctx)
+	}()
+
+	return done
+}
+
+// Run stops the service: the readiness fails at once, after Config.Delay (and the accept queue of a handoff Listener) the listener is closed
+// and the in-flight requests are waited for up to Config.DrainTimeout, then the background jobs are stopped, the span file and the DB pool are closed;
+// the pid file is removed, the lock released and the loggers flushed last. A failed phase doesn't stop the next ones, but the DB pool is
+// closed only after the requests still in flight past Config.DrainTimeout, they keep using it.
+func (s *Shutdown) Run(ctx context.Context) {
+	l := logger.WorkLoggerWithContext(ctx).Named("Shutdown")
+
+	if s.Health != nil {
+		s.Health.Drain()
+	}
+
+	if s.Config.Delay > 0 {
+		l.Info("Waiting before closing the listener", zap.Duration("delay", s.Config.Delay))
+		time.Sleep(s.Config.Delay)
+	}
+
+	if s.App != nil && s.Listener != nil {
+		if err := drainBacklog(ctx, s.Listener, backlogDrainTimeout); err != nil {
+			l.Error("Failed drain the accept queue", zap.Error(err))
+		}
+	}
+
+	var served <-chan struct{}
+	if s.App != nil {
+		var err error
+		if served, err = shutdownWithTimeout(s.App, s.Config.DrainTimeout); err != nil {
+			l.Error("Failed drain in-flight requests", zap.Error(err))
+		}
+	}
+
+	if s.Stop != nil {
+		s.Stop()
+	}
+
+	if s.Spans != nil {
+		// the spans ended from now on are dropped
+		tracing.SetExporter(nil, nil)
+		if err := s.Spans.Close(); err != nil {
+			l.Error("Failed close span file", zap.Error(err))
+		}
+	}
+
+	if s.DB != nil {
+		if served != nil {
+			select {
+			case <-served:
+			default:
+				l.Warn("Waiting for the in-flight requests before closing the DB pool")
+				<-served
+			}
+		}
+		if err := withTimeout(s.Config.CloseTimeout, s.DB.Close); err != nil {
+			l.Error("Failed close DB connection", zap.Error(err))
+		}
+	}
+
+	// after a handoff the pid file is of the new instance
+	if pid, ok := readPid(s.PidPath); s.PidPath != "" && (!ok || pid == os.Getpid()) {
+		if err := os.Remove(s.PidPath); err != nil && !os.IsNotExist(err) {
+			l.Error("Failed remove pid file", zap.String("path", s.PidPath), zap.Error(err))
+		}
+	}
+
+	if s.Lock != nil {
+		if err := s.Lock.Unlock(); err != nil {
+			l.Error("Failed release lock file", zap.String("path", s.Lock.Path()), zap.Error(err))
+		}
+	}
+
+	l.Info("al_hilal_core stopped")
+
+	for _, zl := range s.Loggers {
+		// syncing the console fails with EINVAL on some terminals, nothing to report it to anyway
+		_ = zl.Sync()
+	}
+}
+
+// shutdownWithTimeout is fiber's ShutdownWithTimeout, which comes with the releases requiring a newer go:
+// the listener is closed at once and the in-flight requests are waited for up to timeout.
+// served is closed once the last of them is done, after the timeout too.
+func shutdownWithTimeout(app *fiber.App, timeout time.Duration) (served <-chan struct{}, err error) {
+	done := make(chan struct{})
+	err = withTimeout(timeout, func() error {
+		defer close(done)
+		return app.Shutdown()
+	})
+	return done, err
+}
+
+// withTimeout runs fn and gives up waiting for it after timeout, fn keeps running then
+func withTimeout(timeout time.Duration, fn func() error) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- fn()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("not done in %s", timeout)
+	}
+}
@@ -0,0 +1,212 @@
+//go:build !windows
+// +build !windows
+
+package daylight
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/internal/storage/storagetest"
+	"github.com/internet-banking-ul/modules/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theckman/go-flock"
+)
+
+func TestShutdown_Signal(t *testing.T) {
+	ctx := context.Background()
+	db := storagetest.NewSQLite(t)
+
+	dir := t.TempDir()
+	pidPath := filepath.Join(dir, "al_hilal_core.pid")
+	require.NoError(t, ioutil.WriteFile(pidPath, []byte(`{"pid":"`+strconv.Itoa(os.Getpid())+`"}`), 0644))
+	lockPath := filepath.Join(dir, "al_hilal_core.lock")
+	lock := flock.New(lockPath)
+	locked, err := lock.TryLock()
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	started := make(chan struct{})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		return c.SendString("done")
+	})
+
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	shutdown := &Shutdown{
+		Config:  config.Shutdown{DrainTimeout: 5 * time.Second, CloseTimeout: 5 * time.Second},
+		App:     app,
+		Stop:    stopJobs,
+		DB:      db,
+		Lock:    lock,
+		PidPath: pidPath,
+	}
+	done := shutdown.Notify(ctx)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- app.Listener(ln) }()
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if !assert.NoError(t, err) {
+			body <- ""
+			return
+		}
+		defer resp.Body.Close()
+		content, _ := ioutil.ReadAll(resp.Body)
+		body <- string(content)
+	}()
+
+	<-started
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+	// the request in flight is served, nothing new is accepted
+	assert.Equal(t, "done", <-body)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown is not done")
+	}
+	assert.NoError(t, <-served)
+
+	_, err = net.DialTimeout("tcp", ln.Addr().String(), time.Second)
+	assert.Error(t, err, "the listener is closed")
+
+	assert.ErrorIs(t, jobsCtx.Err(), context.Canceled)
+	assert.Error(t, db.Ping(), "the pool is closed")
+	_, err = os.Stat(pidPath)
+	assert.True(t, os.IsNotExist(err), "the pid file is removed")
+
+	relock := flock.New(lockPath)
+	locked, err = relock.TryLock()
+	require.NoError(t, err)
+	assert.True(t, locked, "the lock is released")
+	_ = relock.Unlock()
+}
+
+func TestShutdown_NotReadyDuringDelay(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	require.True(t, registry.Ready(context.Background()).OK())
+
+	shutdown := &Shutdown{Config: config.Shutdown{Delay: 300 * time.Millisecond}, Health: registry}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		shutdown.Run(context.Background())
+	}()
+
+	assert.Eventually(t, func() bool { return !registry.Ready(context.Background()).OK() }, 100*time.Millisecond, 5*time.Millisecond,
+		"the readiness fails before the delay is over")
+	select {
+	case <-done:
+		t.Fatal("the delay is skipped")
+	default:
+	}
+	<-done
+}
+
+func TestShutdownWithTimeout(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app.Get("/stuck", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	go func() {
+		if resp, err := http.Get("http://" + ln.Addr().String() + "/stuck"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	served, err := shutdownWithTimeout(app, 50*time.Millisecond)
+	assert.Error(t, err)
+	select {
+	case <-served:
+		t.Fatal("the request is still in flight")
+	default:
+	}
+
+	close(release)
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request is served")
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestShutdown_DBClosedAfterRequests(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var requestDone, dbClosed int32
+	app.Get("/stuck", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		atomic.StoreInt32(&requestDone, 1)
+		return nil
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	go func() {
+		if resp, err := http.Get("http://" + ln.Addr().String() + "/stuck"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	shutdown := &Shutdown{
+		Config: config.Shutdown{DrainTimeout: 50 * time.Millisecond, CloseTimeout: time.Second},
+		App:    app,
+		DB: closerFunc(func() error {
+			assert.Equal(t, int32(1), atomic.LoadInt32(&requestDone), "the pool is closed after the request")
+			atomic.StoreInt32(&dbClosed, 1)
+			return nil
+		}),
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		shutdown.Run(context.Background())
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&dbClosed), "the pool stays open past the drain timeout")
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown is not done")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&dbClosed))
+}
@@ -2,24 +2,25 @@ package daylight
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"os"
-	"os/signal"
-	"strings"
-	"syscall"
 	"time"
 
 	"log"
 
 	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/internal/middles"
+	auditModel "github.com/internet-banking-ul/internal/modules/audit/entities"
+	"github.com/internet-banking-ul/internal/modules/entities"
 	"github.com/internet-banking-ul/internal/server"
+	"github.com/internet-banking-ul/internal/storage"
 	"github.com/internet-banking-ul/modules/logger"
 	"github.com/internet-banking-ul/tools"
+	"github.com/theckman/go-flock"
 	"go.uber.org/zap"
 )
 
@@ -58,7 +59,7 @@ func Start(ctx context.Context) {
 		Exit(1)
 	}
 
-	isDebug := strings.Contains(cfg.Environment, "dev")
+	isDebug := cfg.IsDevelopment()
 	logger.Init(
 		isDebug,
 		&logger.Config{
@@ -78,18 +79,35 @@ func Start(ctx context.Context) {
 
 	l := logger.WorkLoggerWithContext(ctx)
 
-	sqlDB, err := sql.Open("oci8", cfg.DSN())
+	if cfg.CursorSecret != "" {
+		entities.SetCursorSecret([]byte(cfg.CursorSecret))
+	} else {
+		l.Warn("cursor_secret is not set, list cursors are valid for this process only")
+	}
+
+	if cfg.AuditKey != "" {
+		auditModel.SetHashKey([]byte(cfg.AuditKey))
+	} else {
+		l.Warn("audit_key is not set, the audit trail is hashed without a key")
+	}
+
+	spanFile, err := setupTracing(cfg.Tracing)
 	if err != nil {
-		l.Error("Failed close DB connection", zap.Error(err))
+		l.Error("Failed open span file", zap.String("file", cfg.Tracing.File), zap.Error(err))
+		Exit(1)
+	}
+
+	auth, err := middles.NewAuthenticateConfig(cfg.Auth)
+	if err != nil {
+		l.Error("Failed load auth keys", zap.Error(err))
+		Exit(1)
+	}
+
+	sqlDB, err := storage.Open(cfg)
+	if err != nil {
+		l.Error("Failed open DB connection", zap.String("dialect", cfg.DB.Dialect), zap.Error(err))
 		Exit(1)
 	}
-	defer func() {
-		err := sqlDB.Close()
-		if err != nil {
-			l.Error("Failed close DB connection", zap.Error(err))
-			Exit(1)
-		}
-	}()
 
 	err = sqlDB.PingContext(ctx)
 	if err != nil {
@@ -97,8 +115,11 @@ func Start(ctx context.Context) {
 		Exit(1)
 	}
 
-	f := tools.LockOrDie(ctx, cfg.LockFilePath)
-	defer f.Unlock()
+	// in handoff the lock is taken after the running instance releases it, see takeOver
+	f := flock.New(cfg.LockFilePath)
+	if !cfg.Handoff.Enabled {
+		f = tools.LockOrDie(ctx, cfg.LockFilePath)
+	}
 
 	if err := tools.MakeDirectory(cfg.TempDir); err != nil {
 		l.
@@ -111,37 +132,77 @@ func Start(ctx context.Context) {
 		Exit(1)
 	}
 
-	killOld(ctx, cfg)
-
 	rand.Seed(time.Now().UTC().UnixNano())
 
-	// save the current pid and version
-	if err := savePid(ctx, cfg); err != nil {
-		log.Fatalf("can't create pid: %s", err)
-		Exit(1)
+	if !cfg.Handoff.Enabled {
+		killOld(ctx, cfg)
+
+		// save the current pid and version
+		if err := savePid(ctx, cfg); err != nil {
+			log.Fatalf("can't create pid: %s", err)
+			Exit(1)
+		}
 	}
-	defer delPidFile(cfg)
 
 	logger.WorkLoggerWithContext(ctx).Info("al_hilal_core started")
 
-	srv := server.NewServer(sqlDB)
-	if err := srv.Listen(cfg.ServerPort); err != nil {
-		log.Panic(err)
+	// the background jobs of the modules run until the shutdown stops them
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+
+	srv, registry, err := server.NewServer(jobsCtx, sqlDB, cfg, auth)
+	if err != nil {
+		l.Error("Failed build server", zap.Error(err))
+		Exit(1)
 	}
 
-	go initGracefulShutDown(cfg)
-}
+	shutdown := &Shutdown{
+		Config:  cfg.Shutdown,
+		Health:  registry,
+		App:     srv,
+		Stop:    stopJobs,
+		DB:      sqlDB,
+		Lock:    f,
+		PidPath: cfg.GetPidPath(),
+		Spans:   spanFile,
+		Loggers: []*zap.Logger{logger.WorkLogger, logger.SqlLogger},
+	}
+	done := shutdown.Notify(ctx)
 
-func initGracefulShutDown(
-	cfg *config.Config,
-) {
-	sigChan := make(chan os.Signal, 1)
-	//register for interupt (Ctrl+C) and SIGTERM (docker)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		os.Exit(2)
-	}()
+	failed := func(msg string, err error) {
+		l.Error(msg, zap.String("addr", cfg.ListenAddr()), zap.Error(err))
+		shutdown.Run(ctx)
+		os.Exit(1)
+	}
+
+	if !cfg.Handoff.Enabled {
+		if err := srv.Listen(cfg.ListenAddr()); err != nil {
+			// nothing to drain, the rest is released
+			shutdown.App = nil
+			failed("Failed listen", err)
+		}
+	} else {
+		ln, err := handoffListener(ctx, srv.Config().Network, cfg.ListenAddr())
+		if err != nil {
+			shutdown.App = nil
+			failed("Failed listen", err)
+		}
+		shutdown.Listener = ln
+
+		served := make(chan error, 1)
+		go func() {
+			served <- srv.Listener(ln)
+		}()
+
+		if err := takeOver(ctx, cfg, ln, registry, f); err != nil {
+			failed("Failed take over", err)
+		}
+		if err := <-served; err != nil {
+			failed("Failed serve", err)
+		}
+	}
+
+	// Listen returns as soon as the listener is closed, the requests are drained after it
+	<-done
 }
 
 func savePid(ctx context.Context, cfg *config.Config) error {
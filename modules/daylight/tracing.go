@@ -0,0 +1,32 @@
+package daylight
+
+import (
+	"io"
+	"os"
+
+	"github.com/internet-banking-ul/internal/config"
+	"github.com/internet-banking-ul/internal/consts"
+	"github.com/internet-banking-ul/modules/logger"
+	"github.com/internet-banking-ul/modules/tracing"
+	"go.uber.org/zap"
+)
+
+// setupTracing sets the exporter of the spans, the returned file is closed by the shutdown
+func setupTracing(cfg config.Tracing) (io.Closer, error) {
+	onError := func(err error) {
+		logger.WorkLogger.Warn("Failed export span", zap.Error(err))
+	}
+
+	switch cfg.Exporter {
+	case consts.TracingExporterStdout:
+		tracing.SetExporter(tracing.NewJSONExporter(os.Stdout), onError)
+	case consts.TracingExporterOTLPFile:
+		f, err := os.OpenFile(cfg.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		tracing.SetExporter(tracing.NewOTLPExporter(f, cfg.ServiceName), onError)
+		return f, nil
+	}
+	return nil, nil
+}
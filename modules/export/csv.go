@@ -0,0 +1,34 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// utf8BOM makes Excel read the file as UTF-8, the names are in Cyrillic mostly
+const utf8BOM = "\xEF\xBB\xBF"
+
+type CSVWriter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	started bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: w, csv: csv.NewWriter(w)}
+}
+
+func (cw *CSVWriter) Write(row []string) error {
+	if !cw.started {
+		cw.started = true
+		if _, err := io.WriteString(cw.w, utf8BOM); err != nil {
+			return err
+		}
+	}
+	return cw.csv.Write(row)
+}
+
+func (cw *CSVWriter) Close() error {
+	cw.csv.Flush()
+	return cw.csv.Error()
+}
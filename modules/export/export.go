@@ -0,0 +1,52 @@
+// Package export writes a table into CSV, XLSX or PDF row by row, so a document of any size is produced
+// with the memory of a row (a page for PDF). The result is usually sent by tools.SendExportedFile.
+package export
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+// Formats - the supported formats
+var Formats = []string{FormatCSV, FormatXLSX, FormatPDF}
+
+var ErrUnknownFormat = errors.New("export: unknown format")
+
+// Writer writes the rows of a table, the first row is the header
+type Writer interface {
+	Write(row []string) error
+
+	// Close finishes the document, the underlying io.Writer isn't closed
+	Close() error
+}
+
+type Options struct {
+	// Title is the sheet name of XLSX and the heading of PDF
+	Title string
+
+	// Notes are the lines under the PDF heading
+	Notes []string
+
+	// Widths are the relative column widths of PDF, equal when empty
+	Widths []float64
+}
+
+// NewWriter returns the Writer of the format, ErrUnknownFormat for a format out of Formats
+func NewWriter(format string, w io.Writer, options Options) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, options.Title), nil
+	case FormatPDF:
+		return NewPDFWriter(w, options), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
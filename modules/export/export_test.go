@@ -0,0 +1,133 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func write(t *testing.T, format string, options Options, rows ...[]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, options)
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	_, err := NewWriter("docx", ioutil.Discard, Options{})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestCSVWriter(t *testing.T) {
+	data := write(t, FormatCSV, Options{}, []string{"Date", "Counterparty"}, []string{"2022-01-10", `ТОО "Альфа", Алматы`})
+	assert.Equal(t, utf8BOM+"Date,Counterparty\n2022-01-10,\"ТОО \"\"Альфа\"\", Алматы\"\n", string(data))
+}
+
+func TestXLSXWriter(t *testing.T) {
+	data := write(t, FormatXLSX, Options{Title: "Statement: KZ86/2022"},
+		[]string{"Date", "Document", "Amount"},
+		[]string{"2022-01-10", "000123", "-1050.75"},
+		[]string{"<&>", "7", "12345678901234567890"},
+	)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		parts[f.Name] = string(body)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		require.Contains(t, parts, name)
+		require.NoError(t, xml.Unmarshal([]byte(parts[name]), new(interface{})), name)
+	}
+	assert.Contains(t, parts["xl/workbook.xml"], `name="Statement_ KZ86_2022"`)
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &sheet))
+	require.Len(t, sheet.Rows, 3)
+
+	row := sheet.Rows[1].Cells
+	assert.Equal(t, "000123", row[1].Inline, "leading zeros stay text")
+	assert.Equal(t, "C2", row[2].Ref)
+	assert.Equal(t, "-1050.75", row[2].Value)
+	assert.Empty(t, row[2].Type)
+
+	row = sheet.Rows[2].Cells
+	assert.Equal(t, "<&>", row[0].Inline)
+	assert.Equal(t, "7", row[1].Value)
+	assert.Equal(t, "12345678901234567890", row[2].Inline, "Excel would round 20 digits")
+}
+
+func TestColumnName(t *testing.T) {
+	for i, expected := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, expected, columnName(i))
+	}
+}
+
+func TestPDFWriter(t *testing.T) {
+	rows := [][]string{{"Date", "Counterparty", "Amount"}}
+	for i := 0; i < 100; i++ {
+		rows = append(rows, []string{"2022-01-10", fmt.Sprintf("ТОО (Әлем) №%d", i), "1050.75"})
+	}
+	data := write(t, FormatPDF, Options{Title: "Statement", Notes: []string{"Period: 2022-01-01 - 2022-01-31"}, Widths: []float64{1, 4, 1}}, rows...)
+	pdf := string(data)
+
+	require.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	assert.Contains(t, pdf, `(TOO \(Alem\) No0) Tj`)
+	assert.Contains(t, pdf, "/Count 3 ")
+
+	// every xref entry points at its object
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	require.Len(t, startxref, 2)
+	offset, err := strconv.Atoi(startxref[1])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(pdf[offset:], "xref\n"))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf[offset:], -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		at, err := strconv.Atoi(entry[1])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(pdf[at:], fmt.Sprintf("%d 0 obj\n", i+1)), "object %d", i+1)
+	}
+
+	// stream lengths
+	for _, m := range regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)\nendstream`).FindAllStringSubmatch(pdf, -1) {
+		assert.Equal(t, m[1], strconv.Itoa(len(m[2])))
+	}
+}
+
+func TestPDFWriter_Empty(t *testing.T) {
+	pdf := string(write(t, FormatPDF, Options{Title: "Statement"}, []string{"Date", "Amount"}))
+	assert.Contains(t, pdf, "/Count 1 ")
+}
@@ -0,0 +1,294 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 landscape in points
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 36.0
+	pdfFontSize   = 8.0
+	pdfLeading    = 12.0
+
+	// pdfCharWidth - the average width of a Helvetica character in em, the cells are cut by it
+	pdfCharWidth = 0.52
+)
+
+// PDFWriter writes a simple tabular PDF with the standard Helvetica font: the title and the notes
+// on the first page, the header row on every page. Only the current page is kept in memory.
+// The text is WinAnsi, Cyrillic is transliterated and the other characters are replaced by '?'.
+type PDFWriter struct {
+	w       *countingWriter
+	options Options
+
+	header  []string
+	widths  []float64
+	offsets map[int]int64
+	pages   []int
+	nextObj int
+
+	page   bytes.Buffer
+	y      float64
+	opened bool
+	err    error
+}
+
+// The objects of the document: 1 is the catalog, 2 the page tree written last, 3 and 4 the fonts
+const (
+	pdfCatalogObj  = 1
+	pdfPagesObj    = 2
+	pdfFontObj     = 3
+	pdfBoldFontObj = 4
+)
+
+func NewPDFWriter(w io.Writer, options Options) *PDFWriter {
+	return &PDFWriter{
+		w:       &countingWriter{w: w},
+		options: options,
+		offsets: map[int]int64{},
+		nextObj: pdfBoldFontObj + 1,
+	}
+}
+
+func (pw *PDFWriter) Write(row []string) error {
+	if pw.err != nil {
+		return pw.err
+	}
+
+	if pw.header == nil {
+		pw.header = append([]string{}, row...)
+		pw.widths = pw.columnWidths(len(row))
+		pw.err = pw.start()
+		return pw.err
+	}
+
+	if !pw.opened || pw.y < pdfMargin+pdfLeading {
+		if pw.err = pw.newPage(); pw.err != nil {
+			return pw.err
+		}
+	}
+	pw.writeRow(row, "F1")
+	return nil
+}
+
+func (pw *PDFWriter) Close() error {
+	if pw.err != nil {
+		return pw.err
+	}
+	if pw.header == nil {
+		if err := pw.Write([]string{""}); err != nil {
+			return err
+		}
+	}
+	if !pw.opened {
+		if err := pw.newPage(); err != nil {
+			return err
+		}
+	}
+	if err := pw.flushPage(); err != nil {
+		return err
+	}
+
+	kids := make([]string, len(pw.pages))
+	for i, obj := range pw.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", obj)
+	}
+	if err := pw.object(pdfPagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))); err != nil {
+		return err
+	}
+
+	xref := pw.w.n
+	var b strings.Builder
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", pw.nextObj)
+	for obj := 1; obj < pw.nextObj; obj++ {
+		fmt.Fprintf(&b, "%010d 00000 n \n", pw.offsets[obj])
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", pw.nextObj, pdfCatalogObj, xref)
+	_, err := io.WriteString(pw.w, b.String())
+	return err
+}
+
+func (pw *PDFWriter) start() error {
+	if _, err := io.WriteString(pw.w, "%PDF-1.4\n%\xE2\xE3\xCF\xD3\n"); err != nil {
+		return err
+	}
+	for _, o := range []struct {
+		obj  int
+		body string
+	}{
+		{pdfCatalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObj)},
+		{pdfFontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"},
+		{pdfBoldFontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>"},
+	} {
+		if err := pw.object(o.obj, o.body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newPage writes the full page and starts the next one with the header row
+func (pw *PDFWriter) newPage() error {
+	if pw.opened {
+		if err := pw.flushPage(); err != nil {
+			return err
+		}
+	}
+
+	pw.opened = true
+	pw.page.Reset()
+	pw.y = pdfPageHeight - pdfMargin
+
+	if len(pw.pages) == 0 {
+		if pw.options.Title != "" {
+			pw.y -= 14
+			pw.text(pdfMargin, pw.y, "F2", 14, pw.options.Title)
+			pw.y -= 8
+		}
+		for _, note := range pw.options.Notes {
+			pw.y -= pdfLeading
+			pw.text(pdfMargin, pw.y, "F1", 9, note)
+		}
+		pw.y -= pdfLeading
+	}
+
+	pw.writeRow(pw.header, "F2")
+	fmt.Fprintf(&pw.page, "%.2f %.2f m %.2f %.2f l S\n", pdfMargin, pw.y+pdfLeading-3, pdfPageWidth-pdfMargin, pw.y+pdfLeading-3)
+	return nil
+}
+
+func (pw *PDFWriter) flushPage() error {
+	pw.text(pdfPageWidth-pdfMargin-20, pdfMargin/2, "F1", pdfFontSize, fmt.Sprintf("%d", len(pw.pages)+1))
+
+	content := pw.nextObj
+	page := pw.nextObj + 1
+	pw.nextObj += 2
+
+	stream := fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", pw.page.Len(), pw.page.String())
+	if err := pw.object(content, stream); err != nil {
+		return err
+	}
+	pw.pages = append(pw.pages, page)
+	return pw.object(page, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] /Contents %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> >>",
+		pdfPagesObj, pdfPageWidth, pdfPageHeight, content, pdfFontObj, pdfBoldFontObj,
+	))
+}
+
+func (pw *PDFWriter) writeRow(row []string, font string) {
+	pw.y -= pdfLeading
+	x := pdfMargin
+	for i, width := range pw.widths {
+		value := ""
+		if i < len(row) {
+			value = row[i]
+		}
+		pw.text(x, pw.y, font, pdfFontSize, fitText(value, width-4, pdfFontSize))
+		x += width
+	}
+}
+
+func (pw *PDFWriter) text(x, y float64, font string, size float64, s string) {
+	fmt.Fprintf(&pw.page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+func (pw *PDFWriter) object(obj int, body string) error {
+	pw.offsets[obj] = pw.w.n
+	_, err := fmt.Fprintf(pw.w, "%d 0 obj\n%s\nendobj\n", obj, body)
+	return err
+}
+
+// columnWidths shares the printable width by Options.Widths
+func (pw *PDFWriter) columnWidths(n int) []float64 {
+	weights := pw.options.Widths
+	if len(weights) != n {
+		weights = make([]float64, n)
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+
+	widths := make([]float64, n)
+	for i, w := range weights {
+		widths[i] = (pdfPageWidth - 2*pdfMargin) * w / total
+	}
+	return widths
+}
+
+// fitText cuts the text to the width approximately, marking the cut with ".."
+func fitText(s string, width, size float64) string {
+	max := int(width / (size * pdfCharWidth))
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	if max <= 2 {
+		return ""
+	}
+	return string(runes[:max-2]) + ".."
+}
+
+// pdfString encodes the text into the WinAnsi literal string with the special characters escaped
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if t, ok := transliteration[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7F:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// transliteration of the Russian and Kazakh letters, Helvetica of WinAnsi has no Cyrillic
+var transliteration = func() map[rune]string {
+	lower := map[rune]string{
+		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i",
+		'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+		'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+		'э': "e", 'ю': "iu", 'я': "ia", 'ә': "a", 'ғ': "g", 'қ': "q", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u",
+		'һ': "h", 'і': "i",
+	}
+	result := map[rune]string{'№': "No"}
+	for r, t := range lower {
+		result[r] = t
+		upper := []rune(strings.ToUpper(string(r)))[0]
+		if t != "" {
+			t = strings.ToUpper(t[:1]) + t[1:]
+		}
+		result[upper] = t
+	}
+	return result
+}()
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
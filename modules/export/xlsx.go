@@ -0,0 +1,163 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// xlsxNumber - the cells written as numbers, the rest is text: no leading zeros (document numbers)
+// and no more than 15 significant digits Excel keeps
+var xlsxNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]{0,14})(\.[0-9]+)?$`)
+
+// XLSXWriter writes the Office Open XML workbook of one sheet. The sheet is streamed into the zip entry
+// with inline strings, so no shared string table is collected in memory.
+type XLSXWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	title string
+	rows  int
+	err   error
+}
+
+func NewXLSXWriter(w io.Writer, title string) *XLSXWriter {
+	return &XLSXWriter{zip: zip.NewWriter(w), title: sheetName(title)}
+}
+
+func (xw *XLSXWriter) Write(row []string) error {
+	if xw.err != nil {
+		return xw.err
+	}
+
+	if xw.sheet == nil {
+		entry, err := xw.zip.Create("xl/worksheets/sheet1.xml")
+		if err != nil {
+			xw.err = err
+			return err
+		}
+		xw.sheet = bufio.NewWriter(entry)
+		xw.sheet.WriteString(xml.Header)
+		xw.sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	}
+
+	xw.rows++
+	r := strconv.Itoa(xw.rows)
+	style := ""
+	if xw.rows == 1 {
+		style = ` s="1"`
+	}
+
+	xw.sheet.WriteString(`<row r="` + r + `">`)
+	for i, value := range row {
+		ref := columnName(i) + r
+		if xw.rows > 1 && xlsxNumber.MatchString(value) {
+			xw.sheet.WriteString(`<c r="` + ref + `"><v>` + value + `</v></c>`)
+			continue
+		}
+		xw.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"` + style + `><is><t xml:space="preserve">`)
+		xml.EscapeText(xw.sheet, []byte(value))
+		xw.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	xw.err = err
+	return err
+}
+
+func (xw *XLSXWriter) Close() error {
+	if xw.err != nil {
+		return xw.err
+	}
+	if xw.sheet == nil {
+		if err := xw.Write(nil); err != nil {
+			return err
+		}
+	}
+
+	xw.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+
+	var title strings.Builder
+	xml.EscapeText(&title, []byte(xw.title))
+
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", strings.Replace(xlsxWorkbook, "{{title}}", title.String(), 1)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		entry, err := xw.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, xml.Header+part.body); err != nil {
+			return err
+		}
+	}
+
+	return xw.zip.Close()
+}
+
+// columnName returns the letters of the zero based column: A, B, ... Z, AA, AB
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// sheetName - Excel limits the name to 31 characters without []:*?/\
+func sheetName(title string) string {
+	title = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(title))
+
+	if runes := []rune(title); len(runes) > 31 {
+		title = string(runes[:31])
+	}
+	if title == "" {
+		return "Sheet1"
+	}
+	return title
+}
+
+const xlsxContentTypes = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="{{title}}" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles - the style 1 is the bold header
+const xlsxStyles = `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// DBPing pings the database
+func DBPing(name string, db *sql.DB) HealthChecker {
+	return NewChecker(name, db.PingContext)
+}
+
+// DBPool fails when the share of the open connections limit in use reaches maxSaturation,
+// the pool without a limit never does
+func DBPool(name string, db *sql.DB, maxSaturation float64) HealthChecker {
+	return NewChecker(name, func(ctx context.Context) error {
+		stats := db.Stats()
+		if stats.MaxOpenConnections <= 0 {
+			return nil
+		}
+
+		saturation := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		if saturation >= maxSaturation {
+			return fmt.Errorf("%d of %d connections in use, %d waits", stats.InUse, stats.MaxOpenConnections, stats.WaitCount)
+		}
+		return nil
+	})
+}
+
+// Writable checks the file can be opened for writing, the file is created when missing
+func Writable(name, path string) HealthChecker {
+	return NewChecker(name, func(ctx context.Context) error {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	})
+}
+
+// DiskSpace fails when less than minFreeMB megabytes are available to the process in dir
+func DiskSpace(name, dir string, minFreeMB uint64) HealthChecker {
+	return NewChecker(name, func(ctx context.Context) error {
+		free, err := freeSpace(dir)
+		if err != nil {
+			return err
+		}
+
+		if freeMB := free >> 20; freeMB < minFreeMB {
+			return fmt.Errorf("%d MB free in %s, %d MB required", freeMB, dir, minFreeMB)
+		}
+		return nil
+	})
+}
+
+// Heartbeat is the check of a background job: it fails when the job hasn't beaten for maxAge,
+// counted from the creation until the first beat
+type Heartbeat struct {
+	name   string
+	maxAge time.Duration
+	last   int64
+	Now    func() time.Time
+}
+
+func NewHeartbeat(name string, maxAge time.Duration) *Heartbeat {
+	return &Heartbeat{name: name, maxAge: maxAge, last: time.Now().UnixNano(), Now: time.Now}
+}
+
+// Beat is called by the job after every successful run, a nil Heartbeat ignores it
+func (h *Heartbeat) Beat() {
+	if h == nil {
+		return
+	}
+	atomic.StoreInt64(&h.last, h.Now().UnixNano())
+}
+
+func (h *Heartbeat) Name() string {
+	return h.name
+}
+
+func (h *Heartbeat) Check(ctx context.Context) error {
+	last := time.Unix(0, atomic.LoadInt64(&h.last))
+	if age := h.Now().Sub(last); age > h.maxAge {
+		return fmt.Errorf("last run %s ago", age.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package health runs the checks behind the liveness, readiness and startup probes.
+// The server registers the checks of its dependencies, every module may register its own HealthChecker.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// HealthChecker is a dependency the service can't serve without
+type HealthChecker interface {
+	// Name is unique within the Registry, it names the check in the report
+	Name() string
+	// Check returns nil when the dependency is usable, ctx carries the timeout of the check
+	Check(ctx context.Context) error
+}
+
+type checkerFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c checkerFunc) Name() string {
+	return c.name
+}
+
+func (c checkerFunc) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// NewChecker makes a HealthChecker of the function
+func NewChecker(name string, check func(ctx context.Context) error) HealthChecker {
+	return checkerFunc{name: name, check: check}
+}
+
+// CheckResult is the outcome of one check. The probes are unauthenticated, so only the name and the status
+// are served, the latency and the error are for the logs.
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"-"`
+	Error     string  `json:"-"`
+}
+
+// shutdownCheck fails the readiness once Drain is called
+const shutdownCheck = "shutdown"
+
+// Report is StatusOK when every check passes
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// OK tells whether every check passed
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Registry holds the checks of the readiness, they run concurrently each within Timeout
+type Registry struct {
+	Timeout time.Duration
+
+	mu       sync.RWMutex
+	checkers []HealthChecker
+	started  int32
+	draining int32
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{Timeout: timeout}
+}
+
+// Register adds the checks, a name registered already is a programming error
+func (r *Registry) Register(checkers ...HealthChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, checker := range checkers {
+		for _, registered := range r.checkers {
+			if registered.Name() == checker.Name() {
+				panic(fmt.Sprintf("health: checker %q is registered already", checker.Name()))
+			}
+		}
+		r.checkers = append(r.checkers, checker)
+	}
+}
+
+// Live is always StatusOK: answering at all is the liveness, the dependencies are up to Ready
+func (r *Registry) Live() Report {
+	return Report{Status: StatusOK}
+}
+
+// Drain fails the readiness from now on, the shutdown calls it on the signal so the load balancer
+// stops routing to the instance while it still serves
+func (r *Registry) Drain() {
+	atomic.StoreInt32(&r.draining, 1)
+}
+
+// Ready runs every check, the results are sorted by name. It fails without running them once Drain is called.
+func (r *Registry) Ready(ctx context.Context) Report {
+	if atomic.LoadInt32(&r.draining) == 1 {
+		return Report{
+			Status: StatusFail,
+			Checks: []CheckResult{{Name: shutdownCheck, Status: StatusFail, Error: "shutting down"}},
+		}
+	}
+
+	r.mu.RLock()
+	checkers := append([]HealthChecker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make([]CheckResult, len(checkers))}
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker HealthChecker) {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Checks, func(i, j int) bool {
+		return report.Checks[i].Name < report.Checks[j].Name
+	})
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+// Startup is Ready until it passes once, StatusOK without running the checks after that
+func (r *Registry) Startup(ctx context.Context) Report {
+	if atomic.LoadInt32(&r.started) == 1 {
+		return Report{Status: StatusOK}
+	}
+
+	report := r.Ready(ctx)
+	if report.OK() {
+		atomic.StoreInt32(&r.started, 1)
+	}
+	return report
+}
+
+// run gives up on a check hanging past the timeout even when it ignores ctx
+func (r *Registry) run(ctx context.Context, checker HealthChecker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				result <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		result <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("not done in %s", r.Timeout)
+	}
+
+	checkResult := CheckResult{
+		Name:      checker.Name(),
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		checkResult.Status = StatusFail
+		checkResult.Error = err.Error()
+	}
+	return checkResult
+}
@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Ready(t *testing.T) {
+	registry := NewRegistry(100 * time.Millisecond)
+	registry.Register(
+		NewChecker("db", func(ctx context.Context) error { return nil }),
+		NewChecker("cache", func(ctx context.Context) error { return errors.New("refused") }),
+		NewChecker("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}),
+		NewChecker("broken", func(ctx context.Context) error { panic("boom") }),
+	)
+
+	start := time.Now()
+	report := registry.Ready(context.Background())
+	assert.Less(t, time.Since(start), time.Second, "the hanging check is given up")
+
+	assert.False(t, report.OK())
+	require.Len(t, report.Checks, 4)
+	assert.Equal(t, []string{"broken", "cache", "db", "slow"},
+		[]string{report.Checks[0].Name, report.Checks[1].Name, report.Checks[2].Name, report.Checks[3].Name})
+	assert.Equal(t, "panic: boom", report.Checks[0].Error)
+	assert.Equal(t, "refused", report.Checks[1].Error)
+	assert.Equal(t, CheckResult{Name: "db", Status: StatusOK, LatencyMs: report.Checks[2].LatencyMs}, report.Checks[2])
+	assert.Equal(t, StatusFail, report.Checks[3].Status)
+	assert.GreaterOrEqual(t, report.Checks[3].LatencyMs, float64(100))
+
+	assert.True(t, registry.Live().OK())
+	assert.Panics(t, func() {
+		registry.Register(NewChecker("db", func(ctx context.Context) error { return nil }))
+	})
+}
+
+func TestRegistry_Startup(t *testing.T) {
+	var err error
+	registry := NewRegistry(time.Second)
+	registry.Register(NewChecker("db", func(ctx context.Context) error { return err }))
+
+	err = errors.New("starting")
+	assert.False(t, registry.Startup(context.Background()).OK())
+
+	err = nil
+	assert.True(t, registry.Startup(context.Background()).OK())
+
+	// started once, the later failures are up to the readiness
+	err = errors.New("down")
+	assert.True(t, registry.Startup(context.Background()).OK())
+	assert.False(t, registry.Ready(context.Background()).OK())
+}
+
+func TestRegistry_Drain(t *testing.T) {
+	registry := NewRegistry(time.Second)
+	registry.Register(NewChecker("db", func(ctx context.Context) error { return nil }))
+	require.True(t, registry.Ready(context.Background()).OK())
+
+	registry.Drain()
+	report := registry.Ready(context.Background())
+	assert.False(t, report.OK())
+	assert.Equal(t, []CheckResult{{Name: "shutdown", Status: StatusFail, Error: "shutting down"}}, report.Checks)
+	assert.True(t, registry.Live().OK(), "still alive")
+}
+
+func TestReport_JSON(t *testing.T) {
+	body, err := json.Marshal(Report{Status: StatusFail, Checks: []CheckResult{
+		{Name: "db", Status: StatusFail, LatencyMs: 2000.4, Error: "dial tcp 10.0.0.5:1521: connection refused"},
+	}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"fail","checks":[{"name":"db","status":"fail"}]}`, string(body), "the details are logged only")
+}
+
+func TestDBChecks(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(2)
+	ctx := context.Background()
+
+	assert.NoError(t, DBPing("db", db).Check(ctx))
+
+	pool := DBPool("db_pool", db, 0.5)
+	assert.NoError(t, pool.Check(ctx))
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	assert.EqualError(t, pool.Check(ctx), "1 of 2 connections in use, 0 waits")
+	require.NoError(t, conn.Close())
+	assert.NoError(t, pool.Check(ctx))
+
+	require.NoError(t, db.Close())
+	assert.Error(t, DBPing("db", db).Check(ctx))
+}
+
+func TestFileChecks(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	assert.NoError(t, Writable("log_file", filepath.Join(dir, "core.log")).Check(ctx))
+	assert.Error(t, Writable("log_file", filepath.Join(dir, "missing", "core.log")).Check(ctx))
+
+	assert.NoError(t, DiskSpace("disk", dir, 1).Check(ctx))
+	assert.Error(t, DiskSpace("disk", dir, 1<<40).Check(ctx), "an exabyte")
+	assert.Error(t, DiskSpace("disk", filepath.Join(dir, "missing"), 1).Check(ctx))
+}
+
+func TestHeartbeat(t *testing.T) {
+	now := time.Now()
+	heartbeat := NewHeartbeat("job", time.Minute)
+	heartbeat.Now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, heartbeat.Check(ctx), "the job has a minute to run first")
+
+	now = now.Add(2 * time.Minute)
+	assert.EqualError(t, heartbeat.Check(ctx), "last run 2m0s ago")
+
+	heartbeat.Beat()
+	assert.NoError(t, heartbeat.Check(ctx))
+
+	var missing *Heartbeat
+	assert.NotPanics(t, missing.Beat)
+}
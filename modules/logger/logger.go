@@ -5,6 +5,7 @@ import (
 	"os"
 	"sync"
 
+	"github.com/internet-banking-ul/modules/tracing"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -20,8 +21,15 @@ func SqlLoggerWithContext(ctx context.Context) *zap.Logger {
 	return WithContext(SqlLogger, ctx)
 }
 
+// WithContext adds the Metadata of the request and the IDs of the current span of ctx
 func WithContext(logger *zap.Logger, ctx context.Context) *zap.Logger {
-	if metadata, ok := getMetadata(ctx); ok {
+	metadata, ok := getMetadata(ctx)
+	if span := tracing.SpanFromContext(ctx); span != nil {
+		sc := span.SpanContext()
+		metadata.TraceID, metadata.SpanID = sc.TraceID.String(), sc.SpanID.String()
+		ok = true
+	}
+	if ok {
 		return logger.With(zap.Any("tracing_metadata", metadata))
 	}
 	return logger
@@ -1,5 +1,6 @@
 package logger
 
+// Metadata is logged with every line of WithContext, TraceID and SpanID are of the current span of the context
 type Metadata struct {
 	Ch    string `json:"ch"`
 	ReqID string `json:"req_id"`
@@ -9,4 +10,7 @@ type Metadata struct {
 	PrsID int64  `json:"prs_id"`
 	UsrAg string `json:"usr_ag"`
 	AppVr string `json:"app_vr,omitempty"`
+
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
 }
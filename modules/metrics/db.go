@@ -0,0 +1,25 @@
+package metrics
+
+import "database/sql"
+
+// NewDBStats reads the sql.DBStats of the pool on every scrape
+func NewDBStats(r *Registry, db *sql.DB) {
+	r.NewGaugeFunc("db_max_open_connections", "Limit of the open connections", func() float64 {
+		return float64(db.Stats().MaxOpenConnections)
+	})
+	r.NewGaugeFunc("db_open_connections", "Connections open, in use and idle", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	r.NewGaugeFunc("db_in_use_connections", "Connections in use", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	r.NewGaugeFunc("db_idle_connections", "Idle connections", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	r.NewCounterFunc("db_wait_count_total", "Connections waited for", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+	r.NewCounterFunc("db_wait_duration_seconds_total", "Time blocked waiting for a connection", func() float64 {
+		return db.Stats().WaitDuration.Seconds()
+	})
+}
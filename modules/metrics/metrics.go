@@ -0,0 +1,200 @@
+// Package metrics keeps counters, gauges and histograms and writes them in the Prometheus text format.
+// A metric belongs to a Registry, the business counters of the services go to Default:
+//
+//	var paymentsCreated = metrics.Default.NewCounterVec("payments_created_total", "Payments created", "currency")
+//	...
+//	paymentsCreated.WithLabelValues(payment.Currency).Inc()
+//
+// The label values must come from a small set (a status, a route template), never from an ID:
+// past the SetMaxSeries limit of the registry the new label sets are counted under OverflowValue.
+package metrics
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowValue replaces every label value of the series over the limit of a metric
+const OverflowValue = "other"
+
+// DefBuckets are the buckets of the request and query durations, in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// value is a float64 updated atomically
+type value struct {
+	bits uint64
+}
+
+func (v *value) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&v.bits)
+		if atomic.CompareAndSwapUint64(&v.bits, old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+func (v *value) Set(f float64) {
+	atomic.StoreUint64(&v.bits, math.Float64bits(f))
+}
+
+func (v *value) Get() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&v.bits))
+}
+
+// Counter only goes up
+type Counter struct {
+	v value
+}
+
+func (c *Counter) Inc() {
+	c.v.Add(1)
+}
+
+// Add ignores a negative delta, a counter never decreases
+func (c *Counter) Add(delta float64) {
+	if delta > 0 {
+		c.v.Add(delta)
+	}
+}
+
+// Gauge goes up and down
+type Gauge struct {
+	v value
+}
+
+func (g *Gauge) Set(f float64) {
+	g.v.Set(f)
+}
+
+func (g *Gauge) Inc() {
+	g.v.Add(1)
+}
+
+func (g *Gauge) Dec() {
+	g.v.Add(-1)
+}
+
+func (g *Gauge) Add(delta float64) {
+	g.v.Add(delta)
+}
+
+// Histogram counts the observations by buckets
+type Histogram struct {
+	upperBounds []float64
+	// counts[i] are the observations in (upperBounds[i-1], upperBounds[i]], the last one above every bound
+	counts []uint64
+	sum    value
+	count  uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{upperBounds: buckets, counts: make([]uint64, len(buckets)+1)}
+}
+
+func (h *Histogram) Observe(f float64) {
+	i := 0
+	for i < len(h.upperBounds) && f > h.upperBounds[i] {
+		i++
+	}
+	atomic.AddUint64(&h.counts[i], 1)
+	h.sum.Add(f)
+	atomic.AddUint64(&h.count, 1)
+}
+
+// family is a metric with its series by label values
+type family struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+
+	maxSeries *int64
+	newSeries func() interface{}
+	// fn is read on every scrape instead of the series
+	fn func() float64
+
+	mu     sync.RWMutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	metric      interface{}
+}
+
+// with returns the series of the label values, the overflow series past maxSeries
+func (f *family) with(labelValues []string) interface{} {
+	if len(labelValues) != len(f.labels) {
+		panic("metrics: " + f.name + " takes the labels " + strings.Join(f.labels, ", "))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	f.mu.RLock()
+	s, ok := f.series[key]
+	f.mu.RUnlock()
+	if ok {
+		return s.metric
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.series[key]; ok {
+		return s.metric
+	}
+	if limit := atomic.LoadInt64(f.maxSeries); limit > 0 && int64(len(f.series)) >= limit {
+		overflow := make([]string, len(labelValues))
+		for i := range overflow {
+			overflow[i] = OverflowValue
+		}
+		labelValues = overflow
+		key = strings.Join(overflow, "\xff")
+		if s, ok := f.series[key]; ok {
+			return s.metric
+		}
+	}
+
+	// the values may be backed by a reused buffer, e.g. of a fiber.Ctx
+	values := make([]string, len(labelValues))
+	for i, v := range labelValues {
+		values[i] = strings.Clone(v)
+	}
+	s = &series{labelValues: values, metric: f.newSeries()}
+	f.series[key] = s
+	return s.metric
+}
+
+// CounterVec is a Counter per label values
+type CounterVec struct {
+	f *family
+}
+
+func (v *CounterVec) WithLabelValues(labelValues ...string) *Counter {
+	return v.f.with(labelValues).(*Counter)
+}
+
+// GaugeVec is a Gauge per label values
+type GaugeVec struct {
+	f *family
+}
+
+func (v *GaugeVec) WithLabelValues(labelValues ...string) *Gauge {
+	return v.f.with(labelValues).(*Gauge)
+}
+
+// HistogramVec is a Histogram per label values
+type HistogramVec struct {
+	f *family
+}
+
+func (v *HistogramVec) WithLabelValues(labelValues ...string) *Histogram {
+	return v.f.with(labelValues).(*Histogram)
+}
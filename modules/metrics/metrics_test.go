@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func write(t *testing.T, registries ...*Registry) string {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, registries...))
+	return buf.String()
+}
+
+func TestWrite(t *testing.T) {
+	r := NewRegistry()
+	created := r.NewCounterVec("payments_created_total", "Payments created", "currency")
+	created.WithLabelValues("USD").Inc()
+	created.WithLabelValues("KZT").Add(2)
+	created.WithLabelValues("KZT").Add(-1)
+	inFlight := r.NewGauge("in_flight", "Requests\nin flight")
+	inFlight.Inc()
+	inFlight.Inc()
+	inFlight.Dec()
+	duration := r.NewHistogramVec("duration_seconds", "Duration", []float64{0.1, 1}, "route")
+	duration.WithLabelValues(`/a"b`).Observe(0.05)
+	duration.WithLabelValues(`/a"b`).Observe(0.5)
+	duration.WithLabelValues(`/a"b`).Observe(3)
+
+	other := NewRegistry()
+	other.NewGaugeFunc("db_open_connections", "Open", func() float64 { return 4 })
+
+	assert.Equal(t, `# HELP db_open_connections Open
+# TYPE db_open_connections gauge
+db_open_connections 4
+# HELP duration_seconds Duration
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/a\"b",le="0.1"} 1
+duration_seconds_bucket{route="/a\"b",le="1"} 2
+duration_seconds_bucket{route="/a\"b",le="+Inf"} 3
+duration_seconds_sum{route="/a\"b"} 3.55
+duration_seconds_count{route="/a\"b"} 3
+# HELP in_flight Requests\nin flight
+# TYPE in_flight gauge
+in_flight 1
+# HELP payments_created_total Payments created
+# TYPE payments_created_total counter
+payments_created_total{currency="KZT"} 2
+payments_created_total{currency="USD"} 1
+`, write(t, r, other))
+}
+
+func TestMaxSeries(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests", "route", "status")
+	r.SetMaxSeries(2)
+
+	requests.WithLabelValues("/a", "200").Inc()
+	requests.WithLabelValues("/b", "200").Inc()
+	requests.WithLabelValues("/c", "200").Inc()
+	requests.WithLabelValues("/d", "404").Inc()
+	requests.WithLabelValues("/a", "200").Inc()
+
+	assert.Equal(t, `# HELP requests_total Requests
+# TYPE requests_total counter
+requests_total{route="/a",status="200"} 2
+requests_total{route="/b",status="200"} 1
+requests_total{route="other",status="other"} 2
+`, write(t, r))
+}
+
+func TestRegister_Invalid(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("requests_total", "Requests")
+
+	assert.Panics(t, func() { r.NewCounter("requests_total", "Requests") }, "registered already")
+	assert.Panics(t, func() { r.NewCounter("requests-total", "Requests") })
+	assert.Panics(t, func() { r.NewHistogramVec("duration", "Duration", nil, "le") })
+	assert.Panics(t, func() { r.NewHistogramVec("duration", "Duration", []float64{1, 0.5}) })
+	assert.Panics(t, func() {
+		r.NewCounterVec("by_status_total", "By status", "status").WithLabelValues("200", "GET")
+	})
+}
+
+func TestShortFuncName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"github.com/internet-banking-ul/internal/modules/payments/repositories.(*RepositoryPaymentCommandImpl).Update": "RepositoryPaymentCommandImpl.Update",
+		"github.com/internet-banking-ul/internal/modules/payments/services.PaymentServiceImpl.Create.func1":            "PaymentServiceImpl.Create",
+		"github.com/internet-banking-ul/internal/modules/audit/services.(*AuditServiceImpl).Record.func2.1":            "AuditServiceImpl.Record",
+		"github.com/internet-banking-ul/internal/storage.(*Repository[...]).List":                                      "Repository[...].List",
+		"main.main": "main",
+		"":          "unknown",
+	} {
+		assert.Equal(t, expected, shortFuncName(name), name)
+	}
+}
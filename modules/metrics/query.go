@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+)
+
+type queryNameKey struct{}
+
+// WithQueryName names the queries run with ctx, instead of the repository method found in the stack
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// Queries times the queries by the repository method running them, its Hook is a storage.QueryHook
+type Queries struct {
+	duration *HistogramVec
+	errors   *CounterVec
+}
+
+func NewQueries(r *Registry) *Queries {
+	return &Queries{
+		duration: r.NewHistogramVec("db_query_duration_seconds", "Duration of the queries by repository method", nil, "method"),
+		errors:   r.NewCounterVec("db_query_errors_total", "Failed queries by repository method", "method"),
+	}
+}
+
+func (q *Queries) Hook(ctx context.Context, query string) func(err error) {
+	method := QueryName(ctx)
+	start := time.Now()
+	return func(err error) {
+		q.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		if err != nil {
+			q.errors.WithLabelValues(method).Inc()
+		}
+	}
+}
+
+// QueryName is the name of WithQueryName or the repository method running the query
+func QueryName(ctx context.Context) string {
+	if name, _ := ctx.Value(queryNameKey{}).(string); name != "" {
+		return name
+	}
+	return callerMethod()
+}
+
+// callerFrames are the packages between the repository method and the hook, the driver of storage
+// calls it from database/sql
+var callerFrames = []string{"database/sql.", "/internal/storage.", "/modules/squirrel.", "/modules/metrics.", "/modules/tracing."}
+
+// callerMethod is the first function in the stack outside callerFrames, e.g. RepositoryPaymentCommandImpl.Update
+func callerMethod() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !inCallerFrames(frame.Function) {
+			return shortFuncName(frame.Function)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+func inCallerFrames(function string) bool {
+	for _, pkg := range callerFrames {
+		if strings.Contains(function, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortFuncName drops the package path and the closure suffixes:
+// github.com/x/repositories.(*RepositoryImpl).Update.func1 is RepositoryImpl.Update
+func shortFuncName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+
+	for {
+		i := strings.LastIndex(name, ".func")
+		if i < 0 || strings.Trim(name[i+len(".func"):], "0123456789.") != "" {
+			break
+		}
+		name = name[:i]
+	}
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
@@ -0,0 +1,29 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/internet-banking-ul/modules/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueries_Hook(t *testing.T) {
+	r := metrics.NewRegistry()
+	hook := metrics.NewQueries(r).Hook
+	ctx := context.Background()
+
+	hook(ctx, "SELECT 1")(nil)
+	hook(metrics.WithQueryName(ctx, "WIDGET.List"), "SELECT ID FROM WIDGET")(errors.New("no such table: WIDGET"))
+
+	var buf bytes.Buffer
+	require.NoError(t, metrics.Write(&buf, r))
+	out := buf.String()
+	assert.Contains(t, out, `db_query_duration_seconds_count{method="TestQueries_Hook"} 1`, "the caller of the hook")
+	assert.Contains(t, out, `db_query_duration_seconds_count{method="WIDGET.List"} 1`)
+	assert.Contains(t, out, `db_query_errors_total{method="WIDGET.List"} 1`)
+	assert.NotContains(t, out, `db_query_errors_total{method="TestQueries_Hook"}`)
+}
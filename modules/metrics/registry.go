@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultMaxSeries is the limit of the label sets per metric of NewRegistry
+const DefaultMaxSeries = 500
+
+// Default is the registry of the business counters, served by /metrics next to the server metrics
+var Default = NewRegistry()
+
+var nameRe = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Registry holds the metrics, a name registered twice is a programming error
+type Registry struct {
+	maxSeries int64
+
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry limits every metric to DefaultMaxSeries label sets
+func NewRegistry() *Registry {
+	return &Registry{maxSeries: DefaultMaxSeries, families: map[string]*family{}}
+}
+
+// SetMaxSeries limits the label sets of every metric, the registered ones included; 0 is no limit
+func (r *Registry) SetMaxSeries(n int) {
+	atomic.StoreInt64(&r.maxSeries, int64(n))
+}
+
+func (r *Registry) register(f *family) *family {
+	if !nameRe.MatchString(f.name) {
+		panic(fmt.Sprintf("metrics: invalid name %q", f.name))
+	}
+	for _, label := range f.labels {
+		if !nameRe.MatchString(label) || label == "le" {
+			panic(fmt.Sprintf("metrics: invalid label %q of %s", label, f.name))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[f.name]; ok {
+		panic(fmt.Sprintf("metrics: %s is registered already", f.name))
+	}
+	f.maxSeries = &r.maxSeries
+	f.series = map[string]*series{}
+	r.families[f.name] = f
+	return f
+}
+
+func (r *Registry) NewCounter(name, help string) *Counter {
+	return r.NewCounterVec(name, help).WithLabelValues()
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{r.register(&family{name: name, help: help, typ: typeCounter, labels: labels,
+		newSeries: func() interface{} { return &Counter{} }})}
+}
+
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	return r.NewGaugeVec(name, help).WithLabelValues()
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{r.register(&family{name: name, help: help, typ: typeGauge, labels: labels,
+		newSeries: func() interface{} { return &Gauge{} }})}
+}
+
+// NewHistogramVec panics when the buckets aren't increasing, nil buckets are DefBuckets
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			panic(fmt.Sprintf("metrics: the buckets of %s aren't increasing", name))
+		}
+	}
+	buckets = append([]float64(nil), buckets...)
+
+	return &HistogramVec{r.register(&family{name: name, help: help, typ: typeHistogram, labels: labels,
+		newSeries: func() interface{} { return newHistogram(buckets) }})}
+}
+
+// NewCounterFunc is a counter read from fn on every scrape, fn must not decrease
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&family{name: name, help: help, typ: typeCounter, fn: fn})
+}
+
+// NewGaugeFunc is a gauge read from fn on every scrape
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&family{name: name, help: help, typ: typeGauge, fn: fn})
+}
+
+// Write writes every metric of the registries in the text format, the metrics sorted by name
+func Write(w io.Writer, registries ...*Registry) error {
+	var families []*family
+	for _, r := range registries {
+		r.mu.RLock()
+		for _, f := range r.families {
+			families = append(families, f)
+		}
+		r.mu.RUnlock()
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (f *family) write(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.typ)
+
+	if f.fn != nil {
+		writeSample(w, f.name, nil, nil, "", "", f.fn())
+		return
+	}
+
+	f.mu.RLock()
+	all := make([]*series, 0, len(f.series))
+	for _, s := range f.series {
+		all = append(all, s)
+	}
+	f.mu.RUnlock()
+	sort.Slice(all, func(i, j int) bool {
+		return strings.Join(all[i].labelValues, "\xff") < strings.Join(all[j].labelValues, "\xff")
+	})
+
+	for _, s := range all {
+		switch m := s.metric.(type) {
+		case *Counter:
+			writeSample(w, f.name, f.labels, s.labelValues, "", "", m.v.Get())
+		case *Gauge:
+			writeSample(w, f.name, f.labels, s.labelValues, "", "", m.v.Get())
+		case *Histogram:
+			var cumulative uint64
+			for i, bound := range m.upperBounds {
+				cumulative += atomic.LoadUint64(&m.counts[i])
+				writeSample(w, f.name+"_bucket", f.labels, s.labelValues, "le", formatFloat(bound), float64(cumulative))
+			}
+			count := atomic.LoadUint64(&m.count)
+			writeSample(w, f.name+"_bucket", f.labels, s.labelValues, "le", "+Inf", float64(count))
+			writeSample(w, f.name+"_sum", f.labels, s.labelValues, "", "", m.sum.Get())
+			writeSample(w, f.name+"_count", f.labels, s.labelValues, "", "", float64(count))
+		}
+	}
+}
+
+// writeSample writes one line, extraLabel is the le of the histogram buckets
+func writeSample(w *bufio.Writer, name string, labels, labelValues []string, extraLabel, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, label, escapeLabelValue(labelValues[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var (
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
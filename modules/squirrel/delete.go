@@ -11,6 +11,7 @@ import (
 
 type deleteData struct {
 	PlaceholderFormat PlaceholderFormat
+	PaginationFormat  PaginationFormat
 	RunWith           BaseRunner
 	Prefixes          []Sqlizer
 	From              string
@@ -61,17 +62,7 @@ func (d *deleteData) ToSql() (sqlStr string, args []interface{}, err error) {
 		sql.WriteString(strings.Join(d.OrderBys, ", "))
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" FETCH NEXT ")
-		sql.WriteString(d.Limit)
-		sql.WriteString(" ROWS ONLY ")
-	}
-
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
-		sql.WriteString(" ROWS ")
-	}
+	writePagination(d.PaginationFormat, sql, d.Limit, d.Offset)
 
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
@@ -102,6 +93,12 @@ func (b DeleteBuilder) PlaceholderFormat(f PlaceholderFormat) DeleteBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(DeleteBuilder)
 }
 
+// PaginationFormat sets PaginationFormat (e.g. LimitOffset or OffsetFetch)
+// for the query.
+func (b DeleteBuilder) PaginationFormat(f PaginationFormat) DeleteBuilder {
+	return builder.Set(b, "PaginationFormat", f).(DeleteBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
@@ -14,15 +14,18 @@ import (
 
 type insertData struct {
 	PlaceholderFormat PlaceholderFormat
-	RunWith           BaseRunner
-	Prefixes          []Sqlizer
-	StatementKeyword  string
-	Options           []string
-	Into              string
-	Columns           []string
-	Values            [][]interface{}
-	Suffixes          []Sqlizer
-	Select            *SelectBuilder
+	// PaginationFormat is not used by INSERT, it is kept so that a
+	// StatementBuilder with a PaginationFormat can build inserts too.
+	PaginationFormat PaginationFormat
+	RunWith          BaseRunner
+	Prefixes         []Sqlizer
+	StatementKeyword string
+	Options          []string
+	Into             string
+	Columns          []string
+	Values           [][]interface{}
+	Suffixes         []Sqlizer
+	Select           *SelectBuilder
 }
 
 func (d *insertData) Exec() (sql.Result, error) {
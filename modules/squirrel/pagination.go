@@ -0,0 +1,58 @@
+package squirrel
+
+import "bytes"
+
+// PaginationFormat is the interface that wraps the WritePagination method.
+//
+// WritePagination writes the LIMIT/OFFSET part of a statement into sql.
+// limit and offset are empty strings when they are not set on the builder.
+type PaginationFormat interface {
+	WritePagination(sql *bytes.Buffer, limit, offset string)
+}
+
+var (
+	// LimitOffset is a PaginationFormat instance that writes
+	// "LIMIT n OFFSET m" (MySQL, PostgreSQL, SQLite). It is the default.
+	LimitOffset = limitOffsetFormat{}
+
+	// OffsetFetch is a PaginationFormat instance that writes the SQL:2008
+	// "OFFSET m ROWS FETCH NEXT n ROWS ONLY" (Oracle 12c+, SQL Server 2012+).
+	OffsetFetch = offsetFetchFormat{}
+)
+
+type limitOffsetFormat struct{}
+
+func (limitOffsetFormat) WritePagination(sql *bytes.Buffer, limit, offset string) {
+	if len(limit) > 0 {
+		sql.WriteString(" LIMIT ")
+		sql.WriteString(limit)
+	}
+
+	if len(offset) > 0 {
+		sql.WriteString(" OFFSET ")
+		sql.WriteString(offset)
+	}
+}
+
+type offsetFetchFormat struct{}
+
+func (offsetFetchFormat) WritePagination(sql *bytes.Buffer, limit, offset string) {
+	if len(offset) > 0 {
+		sql.WriteString(" OFFSET ")
+		sql.WriteString(offset)
+		sql.WriteString(" ROWS")
+	}
+
+	if len(limit) > 0 {
+		sql.WriteString(" FETCH NEXT ")
+		sql.WriteString(limit)
+		sql.WriteString(" ROWS ONLY")
+	}
+}
+
+func writePagination(f PaginationFormat, sql *bytes.Buffer, limit, offset string) {
+	if f == nil {
+		f = LimitOffset
+	}
+	f.WritePagination(sql, limit, offset)
+}
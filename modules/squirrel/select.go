@@ -11,6 +11,7 @@ import (
 
 type selectData struct {
 	PlaceholderFormat PlaceholderFormat
+	PaginationFormat  PaginationFormat
 	RunWith           BaseRunner
 	Prefixes          []Sqlizer
 	Options           []string
@@ -137,17 +138,7 @@ func (d *selectData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 		}
 	}
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
-		sql.WriteString(" ROWS ")
-	}
-
-	if len(d.Limit) > 0 {
-		sql.WriteString(" FETCH NEXT ")
-		sql.WriteString(d.Limit)
-		sql.WriteString(" ROWS ONLY ")
-	}
+	writePagination(d.PaginationFormat, sql, d.Limit, d.Offset)
 
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
@@ -179,6 +170,12 @@ func (b SelectBuilder) PlaceholderFormat(f PlaceholderFormat) SelectBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(SelectBuilder)
 }
 
+// PaginationFormat sets PaginationFormat (e.g. LimitOffset or OffsetFetch)
+// for the query.
+func (b SelectBuilder) PaginationFormat(f PaginationFormat) SelectBuilder {
+	return builder.Set(b, "PaginationFormat", f).(SelectBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
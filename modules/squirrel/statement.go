@@ -36,6 +36,11 @@ func (b StatementBuilderType) PlaceholderFormat(f PlaceholderFormat) StatementBu
 	return builder.Set(b, "PlaceholderFormat", f).(StatementBuilderType)
 }
 
+// PaginationFormat sets the PaginationFormat field for any child builders.
+func (b StatementBuilderType) PaginationFormat(f PaginationFormat) StatementBuilderType {
+	return builder.Set(b, "PaginationFormat", f).(StatementBuilderType)
+}
+
 // RunWith sets the RunWith field for any child builders.
 func (b StatementBuilderType) RunWith(runner BaseRunner) StatementBuilderType {
 	return setRunWith(b, runner).(StatementBuilderType)
@@ -79,3 +79,16 @@ func TestStatementBuilderWhere(t *testing.T) {
 	expectedArgs := []interface{}{1, 2}
 	assert.Equal(t, expectedArgs, args)
 }
+
+func TestStatementBuilderPaginationFormat(t *testing.T) {
+	sb := StatementBuilder.PlaceholderFormat(Colon).PaginationFormat(OffsetFetch)
+
+	sql, args, err := sb.Select("a").From("b").Where("c = ?", 1).Limit(10).Offset(20).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM b WHERE c = :1 OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, _, err = sb.Insert("b").Columns("a").Values(1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO b (a) VALUES (:1)", sql)
+}
@@ -12,6 +12,7 @@ import (
 
 type updateData struct {
 	PlaceholderFormat PlaceholderFormat
+	PaginationFormat  PaginationFormat
 	RunWith           BaseRunner
 	Prefixes          []Sqlizer
 	Table             string
@@ -113,17 +114,7 @@ func (d *updateData) ToSql() (sqlStr string, args []interface{}, err error) {
 		sql.WriteString(strings.Join(d.OrderBys, ", "))
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" FETCH NEXT ")
-		sql.WriteString(d.Limit)
-		sql.WriteString(" ROWS ONLY ")
-	}
-
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
-		sql.WriteString(" ROWS ")
-	}
+	writePagination(d.PaginationFormat, sql, d.Limit, d.Offset)
 
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
@@ -154,6 +145,12 @@ func (b UpdateBuilder) PlaceholderFormat(f PlaceholderFormat) UpdateBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(UpdateBuilder)
 }
 
+// PaginationFormat sets PaginationFormat (e.g. LimitOffset or OffsetFetch)
+// for the query.
+func (b UpdateBuilder) PaginationFormat(f PaginationFormat) UpdateBuilder {
+	return builder.Set(b, "PaginationFormat", f).(UpdateBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
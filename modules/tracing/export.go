@@ -0,0 +1,194 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Exporter receives every ended span of the sampled traces, it's called by the goroutine ending the span
+type Exporter interface {
+	Export(span SpanData) error
+}
+
+type exporterHolder struct {
+	exporter Exporter
+	onError  func(err error)
+}
+
+var exporter atomic.Value
+
+// SetExporter sets the exporter of the spans, nil stops exporting. onError is called with the failures
+// of Export and may be nil.
+func SetExporter(e Exporter, onError func(err error)) {
+	exporter.Store(exporterHolder{exporter: e, onError: onError})
+}
+
+func export(span SpanData) {
+	holder, _ := exporter.Load().(exporterHolder)
+	if holder.exporter == nil {
+		return
+	}
+	if err := holder.exporter.Export(span); err != nil && holder.onError != nil {
+		holder.onError(err)
+	}
+}
+
+// writerExporter writes a line per span
+type writerExporter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	encode func(span SpanData) interface{}
+}
+
+func (e *writerExporter) Export(span SpanData) error {
+	line, err := json.Marshal(e.encode(span))
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(line)
+	return err
+}
+
+// NewJSONExporter writes the spans to w as JSON lines meant to be read, e.g. on the console:
+//
+//	{"name":"GET /api/v1/payments/:id","kind":"server","trace_id":"…","span_id":"…","start":"…","duration_ms":1.2,…}
+func NewJSONExporter(w io.Writer) Exporter {
+	return &writerExporter{w: w, encode: func(span SpanData) interface{} {
+		attributes := make(map[string]interface{}, len(span.Attributes))
+		for _, a := range span.Attributes {
+			attributes[a.Key] = a.Value
+		}
+		line := jsonSpan{
+			Name:       span.Name,
+			Kind:       span.Kind.String(),
+			TraceID:    span.SpanContext.TraceID.String(),
+			SpanID:     span.SpanContext.SpanID.String(),
+			Start:      span.Start.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+			DurationMs: float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+			Attributes: attributes,
+			Error:      span.Error,
+		}
+		if span.Parent.IsValid() {
+			line.ParentSpanID = span.Parent.String()
+		}
+		return line
+	}}
+}
+
+type jsonSpan struct {
+	Name         string                 `json:"name"`
+	Kind         string                 `json:"kind"`
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	Start        string                 `json:"start"`
+	DurationMs   float64                `json:"duration_ms"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// NewOTLPExporter writes the spans to w as the OTLP/JSON ExportTraceServiceRequest, a request per line,
+// the format read by the file receiver of the OpenTelemetry Collector
+func NewOTLPExporter(w io.Writer, serviceName string) Exporter {
+	resource := otlpResource{Attributes: []otlpAttribute{{Key: "service.name", Value: otlpValue(serviceName)}}}
+	scope := otlpScope{Name: serviceName}
+
+	return &writerExporter{w: w, encode: func(span SpanData) interface{} {
+		s := otlpSpan{
+			TraceID:           span.SpanContext.TraceID.String(),
+			SpanID:            span.SpanContext.SpanID.String(),
+			Name:              span.Name,
+			Kind:              int(span.Kind) + 1,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+		}
+		if span.Parent.IsValid() {
+			s.ParentSpanID = span.Parent.String()
+		}
+		for _, a := range span.Attributes {
+			s.Attributes = append(s.Attributes, otlpAttribute{Key: a.Key, Value: otlpValue(a.Value)})
+		}
+		if span.Error != "" {
+			s.Status = &otlpStatus{Code: otlpStatusError, Message: span.Error}
+		}
+
+		return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+			Resource:   resource,
+			ScopeSpans: []otlpScopeSpans{{Scope: scope, Spans: []otlpSpan{s}}},
+		}}}
+	}}
+}
+
+const otlpStatusError = 2
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId,omitempty"`
+	Name         string `json:"name"`
+	// Kind is the SpanKind of OTLP, SPAN_KIND_INTERNAL is 1
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpValue is the AnyValue of v, the 64-bit integers are strings in OTLP/JSON
+func otlpValue(v interface{}) map[string]interface{} {
+	switch v := v.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	case bool:
+		return map[string]interface{}{"boolValue": v}
+	case int:
+		return map[string]interface{}{"intValue": strconv.Itoa(v)}
+	case int32:
+		return map[string]interface{}{"intValue": strconv.FormatInt(int64(v), 10)}
+	case int64:
+		return map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		return map[string]interface{}{"doubleValue": v}
+	}
+	return map[string]interface{}{"stringValue": fmt.Sprint(v)}
+}
@@ -0,0 +1,21 @@
+package tracing
+
+import "context"
+
+// QueryHook is a storage.QueryHook starting a client span per statement, named by name(ctx) (the repository method).
+// A statement run outside a traced request or job isn't traced.
+func QueryHook(name func(ctx context.Context) string) func(ctx context.Context, query string) func(err error) {
+	return func(ctx context.Context, query string) func(err error) {
+		parent := SpanFromContext(ctx)
+		if parent == nil {
+			return func(err error) {}
+		}
+		span := start(name(ctx), KindClient, parent.SpanContext())
+		span.SetAttribute("db.statement", query)
+
+		return func(err error) {
+			span.SetError(err)
+			span.End()
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SpanKey is the key of the server span in the values of a fasthttp.RequestCtx (the fiber Locals),
+// the handlers pass the RequestCtx to the services as their context
+const SpanKey = "tracing_span"
+
+type spanKey struct{}
+
+// SpanKind tells a request served (server) from a call made (client) and the work in between (internal)
+type SpanKind int
+
+const (
+	KindInternal SpanKind = iota
+	KindServer
+	KindClient
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	}
+	return "internal"
+}
+
+// Attribute is a key of the OpenTelemetry semantic conventions (http.route, db.statement) and its value
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// SpanData is the span passed to the Exporter
+type SpanData struct {
+	Name        string
+	Kind        SpanKind
+	SpanContext SpanContext
+	// Parent is invalid for the root span of a trace
+	Parent     SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	// Error is the message of the failure, empty when the span succeeded
+	Error string
+}
+
+// Span is a timed operation of a trace, exported when it ends
+type Span struct {
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// Start starts a child of the span of ctx, or a new trace when ctx has none
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	var parent SpanContext
+	if span := SpanFromContext(ctx); span != nil {
+		parent = span.SpanContext()
+	}
+	span := start(name, KindInternal, parent)
+	return ContextWithSpan(ctx, span), span
+}
+
+// StartServer starts the span of a request continuing the trace of the caller,
+// a new trace when remote is invalid
+func StartServer(name string, remote SpanContext) *Span {
+	return start(name, KindServer, remote)
+}
+
+func start(name string, kind SpanKind, parent SpanContext) *Span {
+	span := &Span{data: SpanData{Name: name, Kind: kind, Start: time.Now()}}
+	if parent.IsValid() {
+		span.data.SpanContext = SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Flags: parent.Flags}
+		span.data.Parent = parent.SpanID
+	} else {
+		span.data.SpanContext = SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Flags: FlagSampled}
+	}
+	return span
+}
+
+// ContextWithSpan makes span the parent of the spans started with the returned context
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span of Start or the server span stored under SpanKey, nil when there is none
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	if span, ok := ctx.Value(spanKey{}).(*Span); ok {
+		return span
+	}
+	if span, ok := ctx.Value(SpanKey).(*Span); ok {
+		return span
+	}
+	return nil
+}
+
+func (s *Span) SpanContext() SpanContext {
+	// set once by the constructors
+	return s.data.SpanContext
+}
+
+// SetName renames the span, e.g. by the route template known once the request is routed
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, Attribute{Key: key, Value: value})
+}
+
+// SetError marks the span failed, a nil err is ignored
+func (s *Span) SetError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End exports the span of a sampled trace, the calls after the first one are ignored
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.SpanContext.IsSampled() {
+		export(data)
+	}
+}
@@ -0,0 +1,116 @@
+// Package tracing keeps the spans of the requests, the services and the queries, propagated with the W3C
+// trace context (the traceparent header) and written by the Exporter set with SetExporter.
+// The server span of a request is started by middles.Tracing, the services add theirs:
+//
+//	ctx, span := tracing.Start(ctx, "PaymentService.Create")
+//	defer span.End()
+//
+// The spans are created without an exporter too, the logger of logger.WorkLoggerWithContext
+// reads the trace and span IDs from the context.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// TraceparentHeader carries the SpanContext of the caller, see https://www.w3.org/TR/trace-context/
+const TraceparentHeader = "traceparent"
+
+// FlagSampled is the trace flag asking to record the spans, the spans of an unsampled trace aren't exported
+const FlagSampled byte = 0x01
+
+var errInvalidTraceparent = errors.New("invalid traceparent")
+
+// TraceID is shared by every span of a trace
+type TraceID [16]byte
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID identifies a span within its trace
+type SpanID [8]byte
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext is the part of a span propagated to the callees
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent is the header value of version 00: 00-<trace id>-<span id>-<flags>
+func (sc SpanContext) Traceparent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceparent reads the header of version 00, the headers of a later version are read by the same
+// fields and may carry more after them
+func ParseTraceparent(h string) (SpanContext, error) {
+	h = strings.TrimSpace(h)
+	if len(h) < 55 || h[2] != '-' || h[35] != '-' || h[52] != '-' {
+		return SpanContext{}, errInvalidTraceparent
+	}
+
+	var version [1]byte
+	if !decodeHex(version[:], h[:2]) || version[0] == 0xff || (version[0] == 0 && len(h) != 55) ||
+		(len(h) > 55 && h[55] != '-') {
+		return SpanContext{}, errInvalidTraceparent
+	}
+
+	var sc SpanContext
+	var flags [1]byte
+	if !decodeHex(sc.TraceID[:], h[3:35]) || !decodeHex(sc.SpanID[:], h[36:52]) || !decodeHex(flags[:], h[53:55]) {
+		return SpanContext{}, errInvalidTraceparent
+	}
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, errInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// decodeHex accepts the lowercase hex only, as the specification requires
+func decodeHex(dst []byte, s string) bool {
+	if strings.ToLower(s) != s {
+		return false
+	}
+	n, err := hex.Decode(dst, []byte(s))
+	return err == nil && n == len(dst)
+}
+
+func newTraceID() (id TraceID) {
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() (id SpanID) {
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
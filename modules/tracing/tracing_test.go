@@ -0,0 +1,149 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	spans []SpanData
+}
+
+func (r *recorder) Export(span SpanData) error {
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+func record(t *testing.T) *recorder {
+	r := &recorder{}
+	SetExporter(r, nil)
+	t.Cleanup(func() { SetExporter(nil, nil) })
+	return r
+}
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.IsSampled())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	sc, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-later")
+	require.NoError(t, err, "a later version")
+	assert.False(t, sc.IsSampled())
+
+	for _, h := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-more",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bx-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, err := ParseTraceparent(h)
+		assert.Error(t, err, h)
+	}
+}
+
+func TestStart(t *testing.T) {
+	r := record(t)
+	remote, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+
+	server := StartServer("GET /payments/:id", remote)
+	ctx := context.WithValue(context.Background(), SpanKey, server)
+	assert.Same(t, server, SpanFromContext(ctx), "the server span of the request values")
+
+	ctx, span := Start(ctx, "PaymentService.ByID")
+	assert.Same(t, span, SpanFromContext(ctx))
+	span.SetAttribute("payment.id", 1)
+	span.SetError(errors.New("not found"))
+	span.End()
+	span.End()
+	server.End()
+
+	require.Len(t, r.spans, 2, "ended once")
+	child, parent := r.spans[0], r.spans[1]
+	assert.Equal(t, remote.TraceID, parent.SpanContext.TraceID)
+	assert.Equal(t, remote.SpanID, parent.Parent)
+	assert.Equal(t, KindServer, parent.Kind)
+	assert.Equal(t, remote.TraceID, child.SpanContext.TraceID)
+	assert.Equal(t, parent.SpanContext.SpanID, child.Parent)
+	assert.Equal(t, []Attribute{{"payment.id", 1}}, child.Attributes)
+	assert.Equal(t, "not found", child.Error)
+
+	_, root := Start(context.Background(), "ApprovalService.ExpireStale")
+	assert.True(t, root.SpanContext().IsValid())
+	assert.NotEqual(t, remote.TraceID, root.SpanContext().TraceID, "a new trace")
+	assert.True(t, root.SpanContext().IsSampled())
+}
+
+func TestStart_NotSampled(t *testing.T) {
+	r := record(t)
+	remote, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.NoError(t, err)
+
+	server := StartServer("GET /payments", remote)
+	_, span := Start(ContextWithSpan(context.Background(), server), "PaymentService.List")
+	span.End()
+	server.End()
+
+	assert.Empty(t, r.spans)
+}
+
+func TestQueryHook(t *testing.T) {
+	r := record(t)
+	hook := QueryHook(func(ctx context.Context) string { return "PAYMENT.List" })
+
+	hook(context.Background(), "SELECT 1")(nil)
+	assert.Empty(t, r.spans, "no span without a parent")
+
+	ctx, parent := Start(context.Background(), "PaymentService.List")
+	hook(ctx, "SELECT ID FROM PAYMENT")(errors.New("ORA-00942"))
+
+	require.Len(t, r.spans, 1)
+	assert.Equal(t, "PAYMENT.List", r.spans[0].Name)
+	assert.Equal(t, KindClient, r.spans[0].Kind)
+	assert.Equal(t, parent.SpanContext().SpanID, r.spans[0].Parent)
+	assert.Equal(t, []Attribute{{"db.statement", "SELECT ID FROM PAYMENT"}}, r.spans[0].Attributes)
+	assert.Equal(t, "ORA-00942", r.spans[0].Error)
+}
+
+func TestExporters(t *testing.T) {
+	var jsonLines, otlpLines bytes.Buffer
+	span := StartServer("GET /payments/:id", SpanContext{})
+	span.SetAttribute("http.status_code", 500)
+	span.SetError(errors.New("Internal Server Error"))
+	span.End()
+	data := span.data
+
+	require.NoError(t, NewJSONExporter(&jsonLines).Export(data))
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonLines.Bytes(), &line))
+	assert.Equal(t, "GET /payments/:id", line["name"])
+	assert.Equal(t, "server", line["kind"])
+	assert.Equal(t, data.SpanContext.TraceID.String(), line["trace_id"])
+	assert.NotContains(t, line, "parent_span_id")
+	assert.Equal(t, map[string]interface{}{"http.status_code": float64(500)}, line["attributes"])
+	assert.Equal(t, "Internal Server Error", line["error"])
+
+	require.NoError(t, NewOTLPExporter(&otlpLines, "al_hilal_core").Export(data))
+	var request otlpRequest
+	require.NoError(t, json.Unmarshal(otlpLines.Bytes(), &request))
+	require.Len(t, request.ResourceSpans, 1)
+	assert.Equal(t, "service.name", request.ResourceSpans[0].Resource.Attributes[0].Key)
+	s := request.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	assert.Equal(t, data.SpanContext.SpanID.String(), s.SpanID)
+	assert.Equal(t, 2, s.Kind, "SPAN_KIND_SERVER")
+	assert.Equal(t, []otlpAttribute{{Key: "http.status_code", Value: map[string]interface{}{"intValue": "500"}}}, s.Attributes)
+	assert.Equal(t, &otlpStatus{Code: otlpStatusError, Message: "Internal Server Error"}, s.Status)
+}
@@ -75,7 +75,9 @@ func SendExportedFile(c *fiber.Ctx, body io.Reader, filename, format string) err
 	}
 	c.Set(fiber.HeaderContentType, mimeType)
 
-	return c.Status(fiber.StatusOK).SendStream(body)
+	// fiber's SendStream without a size reads the whole body to count it, the chunks are sent as they come instead
+	c.Status(fiber.StatusOK).Context().SetBodyStream(body, -1)
+	return nil
 }
 
 // RouteMatch - check if the current route matches to the list of routes
@@ -105,6 +107,8 @@ func GenerateSubtitle(text string) string {
 // Pricify -
 // ex. Pricify(20.000, 2)
 // ex. Pricify(20.321, 2)
+//
+// Deprecated: float64 loses minor units, use money.Parse with a RoundingMode.
 func Pricify(v float64, decimals int) float64 {
 	var pow float64 = 1
 	for i := 0; i < decimals; i++ {
@@ -404,6 +408,9 @@ func IsInt(e interface{}, v int) bool {
 	return reflect.TypeOf(e).Kind() == reflect.Int && e.(int) == v
 }
 
+// Substract returns |a - b|.
+//
+// Deprecated: use money.Amount Sub and Abs for amounts.
 func Substract(a, b float64) float64 {
 	return map[bool]float64{true: b - a, false: a - b}[a < b]
 }
@@ -539,6 +546,8 @@ func CamelToSnake(s string) string {
 }
 
 // Used when in Java we have BigDecimal divide in HALF_UP mode
+//
+// Deprecated: use money.Amount Div or Mul with money.HalfUp for amounts.
 func RoundHalfUp(x float64, optionalPrec ...int) float64 {
 	var prec int
 	if len(optionalPrec) > 0 {
@@ -553,6 +562,7 @@ func RoundHalfUp(x float64, optionalPrec ...int) float64 {
 	return math.Round(xf100) / math.Pow10(prec)
 }
 
+// Deprecated: use money.Amount Decimal.
 func FormatMoney(f float64, prec int) string {
 	rate := RoundHalfUp(f, prec)
 	return strconv.FormatFloat(rate, 'f', prec, 64)
@@ -750,6 +760,7 @@ func MonthsBetweenTwoDates(first, second time.Time) int {
 	return months
 }
 
+// Deprecated: use money.Amount Div or Allocate for amounts.
 func DivideFloat64Safe(a, b float64) float64 {
 	defer func() {
 		_ = recover()
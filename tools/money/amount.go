@@ -0,0 +1,287 @@
+// Package money is the fixed-point amount of a currency. Amounts are int64 minor units (tiyn, cents),
+// every operation that may lose a minor unit takes a RoundingMode and no float64 is involved.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrOverflow         = errors.New("money: amount overflows int64 minor units")
+	ErrPrecision        = errors.New("money: more fraction digits than the currency scale")
+	ErrUnknownCurrency  = errors.New("money: unknown currency")
+	ErrInvalidRatios    = errors.New("money: ratios must be non negative with a positive sum")
+)
+
+// decimalPattern - plain decimal notation, no exponent nor fraction like 1/3 accepted by big.Rat
+var decimalPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+// Amount is an immutable amount of Currency in its minor units
+type Amount struct {
+	minor    int64
+	currency Currency
+}
+
+// New returns the amount of minor units, New(1050075, KZT) is 10500.75 KZT
+func New(minor int64, currency Currency) Amount {
+	return Amount{minor: minor, currency: currency}
+}
+
+// Zero returns the zero amount of the currency
+func Zero(currency Currency) Amount {
+	return Amount{currency: currency}
+}
+
+// FromMinor is New by the currency code
+func FromMinor(minor int64, code string) (Amount, error) {
+	currency, ok := CurrencyByCode(code)
+	if !ok {
+		return Amount{}, fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+	return New(minor, currency), nil
+}
+
+// Parse reads the decimal notation of major units, "10500.75" or "-0.5",
+// the digits beyond the currency scale are rounded with the mode
+func Parse(s string, currency Currency, mode RoundingMode) (Amount, error) {
+	r, err := parseDecimal(s)
+	if err != nil {
+		return Amount{}, err
+	}
+	return fromMajor(r, currency, mode)
+}
+
+// ParseExact is Parse returning ErrPrecision instead of rounding
+func ParseExact(s string, currency Currency) (Amount, error) {
+	r, err := parseDecimal(s)
+	if err != nil {
+		return Amount{}, err
+	}
+	return fromMajorExact(r, currency)
+}
+
+func parseDecimal(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return nil, fmt.Errorf("money: invalid amount %q", s)
+	}
+	r, _ := new(big.Rat).SetString(s)
+	return r, nil
+}
+
+func fromMajor(r *big.Rat, currency Currency, mode RoundingMode) (Amount, error) {
+	minor, err := round(new(big.Rat).Mul(r, new(big.Rat).SetInt64(currency.pow10())), mode)
+	if err != nil {
+		return Amount{}, err
+	}
+	return New(minor, currency), nil
+}
+
+func fromMajorExact(r *big.Rat, currency Currency) (Amount, error) {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt64(currency.pow10()))
+	if !scaled.IsInt() {
+		return Amount{}, ErrPrecision
+	}
+	return fromMajor(r, currency, HalfEven)
+}
+
+// Minor returns the amount in minor units
+func (a Amount) Minor() int64 {
+	return a.minor
+}
+
+func (a Amount) Currency() Currency {
+	return a.currency
+}
+
+func (a Amount) IsZero() bool {
+	return a.minor == 0
+}
+
+// Sign returns -1, 0 or +1
+func (a Amount) Sign() int {
+	switch {
+	case a.minor < 0:
+		return -1
+	case a.minor > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Neg returns -a, ErrOverflow for the smallest int64
+func (a Amount) Neg() (Amount, error) {
+	if a.minor == minInt64 {
+		return Amount{}, ErrOverflow
+	}
+	return New(-a.minor, a.currency), nil
+}
+
+// Abs returns |a|, ErrOverflow for the smallest int64
+func (a Amount) Abs() (Amount, error) {
+	if a.minor < 0 {
+		return a.Neg()
+	}
+	return a, nil
+}
+
+// SameCurrency reports whether both amounts are of one currency
+func (a Amount) SameCurrency(b Amount) bool {
+	return a.currency == b.currency
+}
+
+// Cmp compares the amounts of one currency: -1 when a < b, 0 when equal, +1 when a > b
+func (a Amount) Cmp(b Amount) (int, error) {
+	if !a.SameCurrency(b) {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case a.minor < b.minor:
+		return -1, nil
+	case a.minor > b.minor:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Equal reports whether the amounts have one currency and value
+func (a Amount) Equal(b Amount) bool {
+	return a == b
+}
+
+func (a Amount) Add(b Amount) (Amount, error) {
+	if !a.SameCurrency(b) {
+		return Amount{}, ErrCurrencyMismatch
+	}
+	sum := a.minor + b.minor
+	if a.minor > 0 && b.minor > 0 && sum < 0 || a.minor < 0 && b.minor < 0 && sum >= 0 {
+		return Amount{}, ErrOverflow
+	}
+	return New(sum, a.currency), nil
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if !a.SameCurrency(b) {
+		return Amount{}, ErrCurrencyMismatch
+	}
+	diff := a.minor - b.minor
+	if a.minor >= 0 && b.minor < 0 && diff < 0 || a.minor < 0 && b.minor > 0 && diff >= 0 {
+		return Amount{}, ErrOverflow
+	}
+	return New(diff, a.currency), nil
+}
+
+// MulInt multiplies by an integer, the quantity of a tariff for example
+func (a Amount) MulInt(n int64) (Amount, error) {
+	return a.mulRat(new(big.Rat).SetInt64(n), HalfEven)
+}
+
+// Mul multiplies by the decimal factor, "0.12" for a rate, rounding the result with the mode
+func (a Amount) Mul(factor string, mode RoundingMode) (Amount, error) {
+	r, err := parseDecimal(factor)
+	if err != nil {
+		return Amount{}, err
+	}
+	return a.mulRat(r, mode)
+}
+
+// Div divides by n rounding the result with the mode, use Split to share an amount without losing minor units
+func (a Amount) Div(n int64, mode RoundingMode) (Amount, error) {
+	if n == 0 {
+		return Amount{}, errors.New("money: division by zero")
+	}
+	return a.mulRat(big.NewRat(1, n), mode)
+}
+
+func (a Amount) mulRat(r *big.Rat, mode RoundingMode) (Amount, error) {
+	minor, err := round(new(big.Rat).Mul(new(big.Rat).SetInt64(a.minor), r), mode)
+	if err != nil {
+		return Amount{}, err
+	}
+	return New(minor, a.currency), nil
+}
+
+// Allocate shares the amount in proportion to the ratios so that the parts sum up to the amount exactly.
+// Every part gets its share rounded toward zero, the minor units left go one by one to the parts
+// with a positive ratio in order: Allocate(1, 1, 1) of 100.00 is 33.34, 33.33, 33.33.
+func (a Amount) Allocate(ratios ...int64) ([]Amount, error) {
+	total := new(big.Int)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, ErrInvalidRatios
+		}
+		total.Add(total, big.NewInt(r))
+	}
+	if total.Sign() == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	minor := big.NewInt(a.minor)
+	parts := make([]Amount, len(ratios))
+	left := a.minor
+	for i, r := range ratios {
+		share := new(big.Int).Mul(minor, big.NewInt(r))
+		share.Quo(share, total)
+		parts[i] = New(share.Int64(), a.currency)
+		left -= share.Int64()
+	}
+
+	unit := int64(1)
+	if left < 0 {
+		unit = -1
+	}
+	for i := 0; left != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].minor += unit
+		left -= unit
+	}
+
+	return parts, nil
+}
+
+// Split shares the amount in n parts differing by one minor unit at most, the larger parts go first
+func (a Amount) Split(n int) ([]Amount, error) {
+	if n <= 0 {
+		return nil, ErrInvalidRatios
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return a.Allocate(ratios...)
+}
+
+// Decimal returns the amount in major units with the currency scale, "10500.75"
+func (a Amount) Decimal() string {
+	scale := a.currency.Scale
+	if scale == 0 {
+		return fmt.Sprintf("%d", a.minor)
+	}
+
+	digits := new(big.Int).Abs(big.NewInt(a.minor)).String()
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	sign := ""
+	if a.minor < 0 {
+		sign = "-"
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// String returns the amount with the currency code, "10500.75 KZT"
+func (a Amount) String() string {
+	return a.Decimal() + " " + a.currency.Code
+}
+
+const minInt64 = -1 << 63
@@ -0,0 +1,237 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		mode     RoundingMode
+		expected int64
+	}{
+		{"10500.75", HalfEven, 1050075},
+		{"-0.5", HalfEven, -50},
+		{"7", HalfUp, 700},
+		{"0.125", HalfEven, 12},
+		{"0.135", HalfEven, 14},
+		{"0.125", HalfUp, 13},
+		{"-0.125", HalfUp, -13},
+		{"-0.125", HalfEven, -12},
+		{"0.1251", HalfEven, 13},
+		{"0.1249", HalfUp, 12},
+	} {
+		a, err := Parse(tc.in, KZT, tc.mode)
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.expected, a.Minor(), "%s %s", tc.in, tc.mode)
+	}
+
+	for _, in := range []string{"", "1e3", "1/3", "1.", ".5", "1,5", "NaN"} {
+		_, err := Parse(in, KZT, HalfEven)
+		assert.Error(t, err, in)
+	}
+
+	_, err := ParseExact("0.125", USD)
+	assert.ErrorIs(t, err, ErrPrecision)
+
+	_, err = Parse("92233720368547758.08", KZT, HalfEven)
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestAmount_Arithmetic(t *testing.T) {
+	a := New(1050075, KZT)
+
+	assert.Equal(t, "10500.75", a.Decimal())
+	assert.Equal(t, "10500.75 KZT", a.String())
+	assert.Equal(t, "-0.05", New(-5, USD).Decimal())
+
+	_, err := a.Add(New(1, USD))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(math.MaxInt64, RUB).Add(New(1, RUB))
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, RUB).Sub(New(1, RUB))
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	// 12% of 10500.75 is 1260.09
+	vat, err := a.Mul("0.12", HalfUp)
+	require.NoError(t, err)
+	assert.Equal(t, int64(126009), vat.Minor())
+
+	third, err := New(100, KZT).Div(3, HalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, int64(33), third.Minor())
+
+	parts, err := New(10000, KZT).Split(3)
+	require.NoError(t, err)
+	assert.Equal(t, []Amount{New(3334, KZT), New(3333, KZT), New(3333, KZT)}, parts)
+
+	parts, err = New(-5, USD).Allocate(0, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []Amount{New(0, USD), New(-3, USD), New(-2, USD)}, parts)
+
+	_, err = a.Allocate(0, 0)
+	assert.ErrorIs(t, err, ErrInvalidRatios)
+}
+
+func TestAmount_JSON(t *testing.T) {
+	data, err := json.Marshal(New(1050075, KZT))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"10500.75","currency":"KZT"}`, string(data))
+
+	var a Amount
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":0.1,"currency":"usd"}`), &a))
+	assert.Equal(t, New(10, USD), a)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":"1","currency":"XXX"}`), &a), ErrUnknownCurrency)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":"0.001","currency":"KZT"}`), &a), ErrPrecision)
+}
+
+func TestAmount_Scan(t *testing.T) {
+	a := Zero(KZT)
+	for _, tc := range []struct {
+		src      interface{}
+		expected int64
+	}{
+		{int64(12), 1200},
+		{1050.75, 105075},
+		{[]byte("-0.01"), -1},
+		{"92233720368547758", 9223372036854775800},
+	} {
+		require.NoError(t, a.Scan(tc.src), "%v", tc.src)
+		assert.Equal(t, tc.expected, a.Minor(), "%v", tc.src)
+	}
+
+	assert.ErrorIs(t, a.Scan(0.125), ErrPrecision)
+
+	var noCurrency Amount
+	assert.Error(t, noCurrency.Scan(int64(1)))
+
+	n := NullAmount{Amount: Zero(USD)}
+	require.NoError(t, n.Scan(nil))
+	assert.False(t, n.Valid)
+	value, err := n.Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestAmount_Properties(t *testing.T) {
+	currencies := []Currency{KZT, USD, RUB}
+	pick := func(c uint8) Currency { return currencies[int(c)%len(currencies)] }
+
+	t.Run("decimal round trip", func(t *testing.T) {
+		f := func(minor int64, c uint8) bool {
+			a := New(minor, pick(c))
+			parsed, err := ParseExact(a.Decimal(), a.Currency())
+			return err == nil && parsed == a
+		}
+		require.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("json and sql round trip", func(t *testing.T) {
+		f := func(minor int64, c uint8) bool {
+			a := New(minor, pick(c))
+
+			data, err := json.Marshal(a)
+			if err != nil {
+				return false
+			}
+			var fromJSON Amount
+			if json.Unmarshal(data, &fromJSON) != nil {
+				return false
+			}
+
+			value, err := a.Value()
+			if err != nil {
+				return false
+			}
+			fromSQL := Zero(a.Currency())
+			return fromSQL.Scan(value) == nil && fromJSON == a && fromSQL == a
+		}
+		require.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("add then sub", func(t *testing.T) {
+		f := func(x, y int32) bool {
+			a, b := New(int64(x), KZT), New(int64(y), KZT)
+			sum, err := a.Add(b)
+			if err != nil {
+				return false
+			}
+			back, err := sum.Sub(b)
+			return err == nil && back == a
+		}
+		require.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("allocation keeps every minor unit", func(t *testing.T) {
+		f := func(minor int64, ratios []uint16) bool {
+			r := make([]int64, len(ratios))
+			var total int64
+			for i, v := range ratios {
+				r[i] = int64(v)
+				total += int64(v)
+			}
+
+			parts, err := New(minor, USD).Allocate(r...)
+			if total == 0 {
+				return err == ErrInvalidRatios
+			}
+			if err != nil {
+				return false
+			}
+
+			var sum int64
+			for i, p := range parts {
+				if r[i] == 0 && !p.IsZero() {
+					return false
+				}
+				sum += p.Minor()
+			}
+			return sum == minor
+		}
+		require.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("split parts differ by one minor unit at most", func(t *testing.T) {
+		f := func(minor int64, n uint8) bool {
+			parts, err := New(minor, RUB).Split(int(n%50) + 1)
+			if err != nil {
+				return false
+			}
+			for _, p := range parts[1:] {
+				if d := parts[0].Minor() - p.Minor(); d != 0 && d != 1 && d != -1 {
+					return false
+				}
+			}
+			return true
+		}
+		require.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("rounding modes differ only on ties", func(t *testing.T) {
+		f := func(minor int64, extra uint8) bool {
+			digit := int64(extra % 10)
+			s := New(minor/10, KZT).Decimal() + string(rune('0'+digit))
+
+			even, errEven := Parse(s, KZT, HalfEven)
+			up, errUp := Parse(s, KZT, HalfUp)
+			if errEven != nil || errUp != nil {
+				return false
+			}
+			if digit != 5 {
+				return even == up
+			}
+			d := up.Minor() - even.Minor()
+			return (d == 0 || d == 1 || d == -1) && even.Minor()%2 == 0
+		}
+		require.NoError(t, quick.Check(f, nil))
+	})
+}
@@ -0,0 +1,47 @@
+package money
+
+import "strings"
+
+// Currency is an ISO 4217 currency, Scale is the number of its minor unit digits (2 for tiyn, kopeck and cent)
+type Currency struct {
+	Code  string
+	Scale int
+}
+
+var (
+	KZT = Currency{Code: "KZT", Scale: 2}
+	USD = Currency{Code: "USD", Scale: 2}
+	RUB = Currency{Code: "RUB", Scale: 2}
+	EUR = Currency{Code: "EUR", Scale: 2}
+	CNY = Currency{Code: "CNY", Scale: 2}
+	GBP = Currency{Code: "GBP", Scale: 2}
+)
+
+// currencies - the supported currencies by code
+var currencies = map[string]Currency{
+	KZT.Code: KZT,
+	USD.Code: USD,
+	RUB.Code: RUB,
+	EUR.Code: EUR,
+	CNY.Code: CNY,
+	GBP.Code: GBP,
+}
+
+// CurrencyByCode returns the supported currency, the code is case insensitive
+func CurrencyByCode(code string) (Currency, bool) {
+	c, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+func (c Currency) String() string {
+	return c.Code
+}
+
+// pow10 returns 10^Scale of the currency, the minor units in one major unit
+func (c Currency) pow10() int64 {
+	p := int64(1)
+	for i := 0; i < c.Scale; i++ {
+		p *= 10
+	}
+	return p
+}
@@ -0,0 +1,54 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// amountJSON - the amount is a string so the clients don't parse it into a float
+type amountJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON writes {"amount":"10500.75","currency":"KZT"}
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(amountJSON{Amount: a.Decimal(), Currency: a.currency.Code})
+}
+
+// UnmarshalJSON accepts the amount as a string or a number literal, the number is read as a decimal and
+// never as a float. The digits beyond the currency scale are ErrPrecision.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Amount   interface{} `json:"amount"`
+		Currency string      `json:"currency"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return err
+	}
+
+	currency, ok := CurrencyByCode(v.Currency)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownCurrency, v.Currency)
+	}
+
+	var s string
+	switch amount := v.Amount.(type) {
+	case string:
+		s = amount
+	case json.Number:
+		s = amount.String()
+	default:
+		return fmt.Errorf("money: invalid amount %v", v.Amount)
+	}
+
+	parsed, err := ParseExact(s, currency)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
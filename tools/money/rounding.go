@@ -0,0 +1,50 @@
+package money
+
+import "math/big"
+
+// RoundingMode decides where a value between two minor units goes
+type RoundingMode int
+
+const (
+	// HalfEven rounds ties to the even neighbour, the banker's rounding: 0.125 -> 0.12, 0.135 -> 0.14
+	HalfEven RoundingMode = iota
+
+	// HalfUp rounds ties away from zero, BigDecimal ROUND_HALF_UP: 0.125 -> 0.13, -0.125 -> -0.13
+	HalfUp
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case HalfEven:
+		return "HALF_EVEN"
+	case HalfUp:
+		return "HALF_UP"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// round rounds the rational to an integer, ErrOverflow is returned when it doesn't fit int64
+func round(r *big.Rat, mode RoundingMode) (int64, error) {
+	num, den := r.Num(), r.Denom()
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		twice := new(big.Int).Abs(rem)
+		twice.Lsh(twice, 1)
+
+		c := twice.Cmp(den)
+		if c > 0 || c == 0 && (mode == HalfUp || q.Bit(0) == 1) {
+			if num.Sign() < 0 {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return q.Int64(), nil
+}
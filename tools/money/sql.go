@@ -0,0 +1,71 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// Value writes the amount in major units as a decimal string, Oracle converts it into NUMBER without a float
+func (a Amount) Value() (driver.Value, error) {
+	return a.Decimal(), nil
+}
+
+// Scan reads a NUMBER column of major units. The column has no currency, so the receiver keeps its one
+// and has to be set before: a := money.Zero(money.KZT); row.Scan(&a).
+// A value with more fraction digits than the currency scale is ErrPrecision.
+func (a *Amount) Scan(src interface{}) error {
+	if a.currency.Code == "" {
+		return fmt.Errorf("money: scan into Amount without currency")
+	}
+
+	var s string
+	switch v := src.(type) {
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		// the shortest representation of the float, 1050.75 stays 1050.75
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case nil:
+		return fmt.Errorf("money: scan NULL into Amount, use NullAmount")
+	default:
+		return fmt.Errorf("money: unsupported scan type %T", src)
+	}
+
+	parsed, err := ParseExact(s, a.currency)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// NullAmount is an Amount of a nullable column, like sql.NullInt64
+type NullAmount struct {
+	Amount Amount
+	Valid  bool
+}
+
+func (n NullAmount) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Amount.Value()
+}
+
+// Scan keeps the currency of n.Amount, see Amount.Scan
+func (n *NullAmount) Scan(src interface{}) error {
+	if src == nil {
+		n.Valid = false
+		return nil
+	}
+	if err := n.Amount.Scan(src); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
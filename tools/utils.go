@@ -254,10 +254,74 @@ func MaskCardNumber(number string) (result string) {
 	return
 }
 
+// ValidateResidencyAndEconomicCode checks KBe/KOd: the residency sign (1 resident, 2 non-resident)
+// followed by the economic sector digit
+func ValidateResidencyAndEconomicCode(code string) bool {
+	return CheckWithRegExp(code, "^[12][0-9]$")
+}
+
 func ValidateTaxCode(taxCode string) bool {
 	return CheckWithRegExp(taxCode, "^[0-9]{12}$")
 }
 
+// ValidateTaxCodeChecksum checks the control (12th) digit of Kazakhstan IIN/BIN.
+// The weighted sum of the first 11 digits with weights 1..11 is taken mod 11,
+// if it equals 10 the weights 3..11,1,2 are used, a second 10 means the code is invalid.
+func ValidateTaxCodeChecksum(taxCode string) bool {
+	if !ValidateTaxCode(taxCode) {
+		return false
+	}
+
+	digits := make([]int, 12)
+	for i, ch := range taxCode {
+		digits[i] = int(ch - '0')
+	}
+
+	control := func(shift int) int {
+		sum := 0
+		for i := 0; i < 11; i++ {
+			sum += digits[i] * ((i+shift)%11 + 1)
+		}
+		return sum % 11
+	}
+
+	checksum := control(0)
+	if checksum == 10 {
+		checksum = control(2)
+	}
+
+	return checksum != 10 && checksum == digits[11]
+}
+
+// NormalizeIBAN removes the spaces of the printed form and upper cases the IBAN
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(RemoveSpaces(iban))
+}
+
+// ValidateIBAN checks the ISO 13616 check digits: the IBAN with the first four characters
+// moved to the end and the letters replaced by 10..35 must be 1 mod 97
+func ValidateIBAN(iban string) bool {
+	if !CheckWithRegExp(iban, "^[A-Z]{2}[0-9]{2}[0-9A-Z]{1,30}$") {
+		return false
+	}
+
+	remainder := 0
+	for _, ch := range iban[4:] + iban[:4] {
+		if ch >= 'A' && ch <= 'Z' {
+			remainder = (remainder*100 + int(ch-'A') + 10) % 97
+		} else {
+			remainder = (remainder*10 + int(ch-'0')) % 97
+		}
+	}
+
+	return remainder == 1
+}
+
+// ValidateKZIBAN checks the Kazakhstan IBAN: KZ, 2 check digits, 3 digit bank code and 13 characters of the account
+func ValidateKZIBAN(iban string) bool {
+	return CheckWithRegExp(iban, "^KZ[0-9]{2}[0-9]{3}[0-9A-Z]{13}$") && ValidateIBAN(iban)
+}
+
 func RemoveDuplicatesTime(slice []time.Time) []time.Time {
 	set := make(map[time.Time]struct{})
 	var result []time.Time
@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateKZIBAN(t *testing.T) {
+	for _, iban := range []string{"KZ86125KZT5004100100", "KZ244350000012344567", NormalizeIBAN("kz86 125K ZT50 0410 0100")} {
+		assert.True(t, ValidateKZIBAN(iban), iban)
+	}
+
+	for _, iban := range []string{
+		"",
+		"KZ87125KZT5004100100",  // check digits
+		"KZ86125KZT500410010",   // length
+		"KZ86125KZT50041001000", // length
+		"kz86125KZT5004100100",  // lower case
+		"KZ8612AKZT5004100100",  // bank code
+		"DE89370400440532013000",
+	} {
+		assert.False(t, ValidateKZIBAN(iban), iban)
+	}
+
+	assert.True(t, ValidateIBAN("DE89370400440532013000"))
+}